@@ -2,9 +2,14 @@ module github.com/go-interpreter
 
 go 1.25.0
 
+require (
+	github.com/stretchr/testify v1.11.0
+	golang.org/x/term v0.40.0
+)
+
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/stretchr/testify v1.11.0 // indirect
+	golang.org/x/sys v0.41.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,50 @@
+// Package analysis runs the passes that stand between the source of a
+// program and running it: scanning, parsing and resolving.
+package analysis
+
+import (
+	"github.com/go-interpreter/internal/ast"
+	"github.com/go-interpreter/internal/errors"
+	"github.com/go-interpreter/internal/interpreter"
+	"github.com/go-interpreter/internal/parser"
+	"github.com/go-interpreter/internal/resolver"
+	"github.com/go-interpreter/internal/scanner"
+)
+
+// Program is a source to analyse. File names it in diagnostics and spans.
+// A source that continues others, like an input of the REPL, starts on
+// Line, or on the first line when it is zero.
+type Program struct {
+	File   string
+	Source string
+	Line   int
+}
+
+// Parse scans and parses a program. The diagnostics of both are returned,
+// so that a single run shows every syntax error; the statements are only
+// meant to be used when there are none.
+func Parse(program Program) ([]ast.Stmt, []errors.ExecutionError) {
+	tokenScanner := scanner.NewTokenScanner(program.Source)
+	if program.Line > 0 {
+		tokenScanner.Line = program.Line
+	}
+	tokens, diagnostics := tokenScanner.ScanTokens()
+	p := parser.NewParser(tokens)
+	p.File = program.File
+	stmts, parseErrors := p.Parse()
+	return stmts, append(diagnostics, parseErrors...)
+}
+
+// Analyse parses a program and, when it has no syntax errors, resolves it
+// for inter to run.
+func Analyse(program Program, inter *interpreter.Interpreter) ([]ast.Stmt, []errors.ExecutionError) {
+	stmts, diagnostics := Parse(program)
+	if len(diagnostics) > 0 {
+		return nil, diagnostics
+	}
+	diagnostics = resolver.NewResolver(inter).Resolve(stmts)
+	if len(diagnostics) > 0 {
+		return nil, diagnostics
+	}
+	return stmts, nil
+}
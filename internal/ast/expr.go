@@ -12,13 +12,21 @@ type ExprVisitor interface {
 	VisitVariable(node Variable) (any, error)
 	VisitAssign(node Assign) (any, error)
 	VisitLogical(node Logical) (any, error)
+	VisitCall(node Call) (any, error)
+	VisitGet(node Get) (any, error)
+	VisitSet(node Set) (any, error)
+	VisitThis(node This) (any, error)
+	VisitIncrement(node Increment) (any, error)
+	VisitSuper(node Super) (any, error)
 }
 
 type Expr interface {
 	Accept(visitor ExprVisitor) (any, error)
+	Location() Span
 }
 
 type Logical struct {
+	Span
 	Left     Expr
 	Operator token.Token
 	Right    Expr
@@ -28,9 +36,22 @@ func (node Logical) Accept(visitor ExprVisitor) (any, error) {
 	return visitor.VisitLogical(node)
 }
 
+type Call struct {
+	Span
+	Callee    Expr
+	Paren     token.Token
+	Arguments []Expr
+}
+
+func (node Call) Accept(visitor ExprVisitor) (any, error) {
+	return visitor.VisitCall(node)
+}
+
 type Assign struct {
-	Name  token.Token
-	Value Expr
+	Span
+	Name    token.Token
+	Value   Expr
+	Binding *Binding
 }
 
 func (node Assign) Accept(visitor ExprVisitor) (any, error) {
@@ -38,6 +59,7 @@ func (node Assign) Accept(visitor ExprVisitor) (any, error) {
 }
 
 type Binary struct {
+	Span
 	Left     Expr
 	Operator token.Token
 	Right    Expr
@@ -48,6 +70,7 @@ func (node Binary) Accept(visitor ExprVisitor) (any, error) {
 }
 
 type Grouping struct {
+	Span
 	Expression Expr
 }
 
@@ -56,6 +79,7 @@ func (node Grouping) Accept(visitor ExprVisitor) (any, error) {
 }
 
 type Literal struct {
+	Span
 	Value any
 }
 
@@ -64,6 +88,7 @@ func (node Literal) Accept(visitor ExprVisitor) (any, error) {
 }
 
 type Unary struct {
+	Span
 	Operator token.Token
 	Right    Expr
 }
@@ -73,9 +98,83 @@ func (node Unary) Accept(visitor ExprVisitor) (any, error) {
 }
 
 type Variable struct {
-	Name token.Token
+	Span
+	Name    token.Token
+	Binding *Binding
 }
 
 func (node Variable) Accept(visitor ExprVisitor) (any, error) {
 	return visitor.VisitVariable(node)
 }
+
+type Get struct {
+	Span
+	Object Expr
+	Name   token.Token
+}
+
+func (node Get) Accept(visitor ExprVisitor) (any, error) {
+	return visitor.VisitGet(node)
+}
+
+type Set struct {
+	Span
+	Object Expr
+	Name   token.Token
+	Value  Expr
+}
+
+func (node Set) Accept(visitor ExprVisitor) (any, error) {
+	return visitor.VisitSet(node)
+}
+
+type This struct {
+	Span
+	Keyword token.Token
+	Binding *Binding
+}
+
+func (node This) Accept(visitor ExprVisitor) (any, error) {
+	return visitor.VisitThis(node)
+}
+
+// Increment adds one to a property, as in obj.count++. Unlike a Set of a
+// Binary reading the property, it evaluates the object only once.
+type Increment struct {
+	Span
+	Object   Expr
+	Name     token.Token
+	Operator token.Token
+}
+
+func (node Increment) Accept(visitor ExprVisitor) (any, error) {
+	return visitor.VisitIncrement(node)
+}
+
+type Super struct {
+	Span
+	Keyword token.Token
+	Method  token.Token
+	Binding *Binding
+}
+
+func (node Super) Accept(visitor ExprVisitor) (any, error) {
+	return visitor.VisitSuper(node)
+}
+
+// Binding is the identity of a reference to a variable. Nodes are values,
+// so the parser gives every Variable, Assign, This and Super a Binding of
+// its own, which all copies of the node share. The resolver records the
+// scope of a reference under its Binding, which keeps references apart
+// even when their names and positions are the same, as they can be in two
+// sources run one after the other.
+type Binding struct {
+	// Name is the name referred to. Bindings are told apart by their
+	// address, never by their name.
+	Name string
+}
+
+// NewBinding returns the identity of a new reference to name.
+func NewBinding(name token.Token) *Binding {
+	return &Binding{Name: name.Lexeme}
+}
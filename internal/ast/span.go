@@ -0,0 +1,57 @@
+package ast
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-interpreter/internal/token"
+)
+
+// Position is a place in the source: a 1-based line and column, and the
+// byte offset from the start of the source.
+type Position struct {
+	Line   int
+	Column int
+	Offset int
+}
+
+// Span is the part of the source a node was parsed from, from Start up to,
+// but not including, End. Every node embeds one; nodes the parser makes up
+// while desugaring get the span of the code they stand for.
+type Span struct {
+	File  string
+	Start Position
+	End   Position
+}
+
+// Location returns the span of a node.
+func (span Span) Location() Span {
+	return span
+}
+
+// TokenSpan returns the span from the start of the first token to the end
+// of the last one.
+func TokenSpan(file string, first token.Token, last token.Token) Span {
+	return Span{File: file, Start: StartOf(first), End: EndOf(last)}
+}
+
+// StartOf returns the position of the first character of a token.
+func StartOf(tok token.Token) Position {
+	return Position{Line: tok.Line, Column: tok.Column, Offset: tok.Char}
+}
+
+// EndOf returns the position right after the last character of a token.
+// Strings can run over several lines.
+func EndOf(tok token.Token) Position {
+	end := Position{
+		Line:   tok.Line,
+		Column: tok.Column + utf8.RuneCountInString(tok.Lexeme),
+		Offset: tok.Char + len(tok.Lexeme),
+	}
+	if newlines := strings.Count(tok.Lexeme, "\n"); newlines > 0 {
+		lastLine := tok.Lexeme[strings.LastIndex(tok.Lexeme, "\n")+1:]
+		end.Line += newlines
+		end.Column = utf8.RuneCountInString(lastLine) + 1
+	}
+	return end
+}
@@ -11,17 +11,23 @@ type StmtVisitor interface {
 	VisitWhileStmt(node WhileStmt) (any, error)
 	VisitBreakStmt() (any, error)
 	VisitContinueStmt() (any, error)
+	VisitFunctionStmt(node FunctionStmt) (any, error)
+	VisitReturnStmt(node ReturnStmt) (any, error)
+	VisitClassStmt(node ClassStmt) (any, error)
 }
 
 type Stmt interface {
 	Accept(visitor StmtVisitor) (any, error)
+	Location() Span
 }
 type WhileStmt struct {
+	Span
 	Condition Expr
 	Body      Stmt
 }
 
 type BreakStmt struct {
+	Span
 	Value string
 }
 
@@ -29,7 +35,9 @@ func (node BreakStmt) Accept(visitor StmtVisitor) (any, error) {
 	return visitor.VisitBreakStmt()
 }
 
-type ContinueStmt struct{}
+type ContinueStmt struct {
+	Span
+}
 
 func (node ContinueStmt) Accept(visitor StmtVisitor) (any, error) {
 	return visitor.VisitContinueStmt()
@@ -40,6 +48,7 @@ func (node WhileStmt) Accept(visitor StmtVisitor) (any, error) {
 }
 
 type IfStmt struct {
+	Span
 	Condition  Expr
 	ThenBranch Stmt
 	ElseBranch Stmt
@@ -50,6 +59,7 @@ func (node IfStmt) Accept(visitor StmtVisitor) (any, error) {
 }
 
 type Block struct {
+	Span
 	Statements []Stmt
 }
 
@@ -58,6 +68,7 @@ func (node Block) Accept(visitor StmtVisitor) (any, error) {
 }
 
 type ExpressionStmt struct {
+	Span
 	Expression Expr
 }
 
@@ -66,6 +77,7 @@ func (node ExpressionStmt) Accept(visitor StmtVisitor) (any, error) {
 }
 
 type PrintStmt struct {
+	Span
 	Expression Expr
 }
 
@@ -74,6 +86,7 @@ func (node PrintStmt) Accept(visitor StmtVisitor) (any, error) {
 }
 
 type VarStmt struct {
+	Span
 	Name        token.Token
 	Initializer Expr
 }
@@ -81,3 +94,44 @@ type VarStmt struct {
 func (node VarStmt) Accept(visitor StmtVisitor) (any, error) {
 	return visitor.VisitVarStmt(node)
 }
+
+type FunctionStmt struct {
+	Span
+	Name   token.Token
+	Params []token.Token
+	Body   []Stmt
+}
+
+func (node FunctionStmt) Accept(visitor StmtVisitor) (any, error) {
+	return visitor.VisitFunctionStmt(node)
+}
+
+// ParamNames returns the names of the parameters, in order.
+func (node FunctionStmt) ParamNames() []string {
+	names := make([]string, 0, len(node.Params))
+	for _, param := range node.Params {
+		names = append(names, param.Lexeme)
+	}
+	return names
+}
+
+type ReturnStmt struct {
+	Span
+	Keyword token.Token
+	Value   Expr
+}
+
+func (node ReturnStmt) Accept(visitor StmtVisitor) (any, error) {
+	return visitor.VisitReturnStmt(node)
+}
+
+type ClassStmt struct {
+	Span
+	Name       token.Token
+	Superclass *Variable
+	Methods    []FunctionStmt
+}
+
+func (node ClassStmt) Accept(visitor StmtVisitor) (any, error) {
+	return visitor.VisitClassStmt(node)
+}
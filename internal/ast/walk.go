@@ -0,0 +1,254 @@
+package ast
+
+import "fmt"
+
+// Node is a statement or an expression. The traversals below also accept a
+// []Stmt, such as a whole program, and go through its statements in order.
+type Node any
+
+// Children returns the statements and expressions directly below a node, in
+// the order they appear in the source. Absent optional children, like a
+// missing else branch, are left out.
+func Children(node Node) []Node {
+	var children []Node
+	add := func(nodes ...Node) {
+		for _, child := range nodes {
+			if child != nil {
+				children = append(children, child)
+			}
+		}
+	}
+	switch node := node.(type) {
+	case []Stmt:
+		for _, stmt := range node {
+			add(stmt)
+		}
+	case Binary:
+		add(node.Left, node.Right)
+	case Logical:
+		add(node.Left, node.Right)
+	case Grouping:
+		add(node.Expression)
+	case Unary:
+		add(node.Right)
+	case Assign:
+		add(node.Value)
+	case Call:
+		add(node.Callee)
+		for _, argument := range node.Arguments {
+			add(argument)
+		}
+	case Get:
+		add(node.Object)
+	case Set:
+		add(node.Object, node.Value)
+	case Increment:
+		add(node.Object)
+	case ExpressionStmt:
+		add(node.Expression)
+	case PrintStmt:
+		add(node.Expression)
+	case VarStmt:
+		add(node.Initializer)
+	case Block:
+		for _, stmt := range node.Statements {
+			add(stmt)
+		}
+	case IfStmt:
+		add(node.Condition, node.ThenBranch, node.ElseBranch)
+	case WhileStmt:
+		add(node.Condition, node.Body)
+	case FunctionStmt:
+		for _, stmt := range node.Body {
+			add(stmt)
+		}
+	case ReturnStmt:
+		add(node.Value)
+	case ClassStmt:
+		if node.Superclass != nil {
+			add(*node.Superclass)
+		}
+		for _, method := range node.Methods {
+			add(method)
+		}
+	}
+	return children
+}
+
+// Walk traverses a tree depth-first. pre is called on a node before its
+// children, which are skipped when it returns false, and post after them.
+// Either hook may be nil.
+func Walk(node Node, pre func(Node) bool, post func(Node)) {
+	if _, ok := node.([]Stmt); !ok {
+		if pre != nil && !pre(node) {
+			return
+		}
+	}
+	for _, child := range Children(node) {
+		Walk(child, pre, post)
+	}
+	if _, ok := node.([]Stmt); !ok && post != nil {
+		post(node)
+	}
+}
+
+// Inspect traverses a tree depth-first, calling f on every node before its
+// children. The children of a node are skipped when f returns false.
+func Inspect(node Node, f func(Node) bool) {
+	Walk(node, f, nil)
+}
+
+// Rewrite returns a copy of a tree in which every node has been replaced by
+// what f returns for it. The children of a node are rewritten before the
+// node itself, so f sees them already replaced. Returning the node leaves
+// it as it is.
+//
+// An expression must be replaced by an expression and a statement by a
+// statement; methods stay functions and a superclass stays a variable.
+// Returning nil removes a statement from the list it is in, and removes an
+// optional child: an initializer, a returned value or an else branch.
+// Rewrite panics on any other replacement, as the tree would be invalid.
+func Rewrite(node Node, f func(Node) Node) Node {
+	switch node := node.(type) {
+	case []Stmt:
+		return rewriteStmts(node, f)
+	case Expr:
+		return f(rewriteExpr(node, f))
+	case Stmt:
+		return f(rewriteStmt(node, f))
+	}
+	panic(fmt.Sprintf("ast: cannot rewrite %T", node))
+}
+
+// rewriteExpr rewrites the children of an expression.
+func rewriteExpr(node Expr, f func(Node) Node) Expr {
+	switch node := node.(type) {
+	case Binary:
+		node.Left = expr(node.Left, f)
+		node.Right = expr(node.Right, f)
+		return node
+	case Logical:
+		node.Left = expr(node.Left, f)
+		node.Right = expr(node.Right, f)
+		return node
+	case Grouping:
+		node.Expression = expr(node.Expression, f)
+		return node
+	case Unary:
+		node.Right = expr(node.Right, f)
+		return node
+	case Assign:
+		node.Value = expr(node.Value, f)
+		return node
+	case Call:
+		node.Callee = expr(node.Callee, f)
+		arguments := make([]Expr, 0, len(node.Arguments))
+		for _, argument := range node.Arguments {
+			arguments = append(arguments, expr(argument, f))
+		}
+		node.Arguments = arguments
+		return node
+	case Get:
+		node.Object = expr(node.Object, f)
+		return node
+	case Set:
+		node.Object = expr(node.Object, f)
+		node.Value = expr(node.Value, f)
+		return node
+	case Increment:
+		node.Object = expr(node.Object, f)
+		return node
+	}
+	return node
+}
+
+// rewriteStmt rewrites the children of a statement.
+func rewriteStmt(node Stmt, f func(Node) Node) Stmt {
+	switch node := node.(type) {
+	case ExpressionStmt:
+		node.Expression = expr(node.Expression, f)
+		return node
+	case PrintStmt:
+		node.Expression = expr(node.Expression, f)
+		return node
+	case VarStmt:
+		node.Initializer = optionalExpr(node.Initializer, f)
+		return node
+	case Block:
+		node.Statements = rewriteStmts(node.Statements, f)
+		return node
+	case IfStmt:
+		node.Condition = expr(node.Condition, f)
+		node.ThenBranch = stmt(node.ThenBranch, f)
+		if node.ElseBranch != nil {
+			if elseBranch := Rewrite(node.ElseBranch, f); elseBranch != nil {
+				node.ElseBranch = mustBe[Stmt](elseBranch, "a statement")
+			} else {
+				node.ElseBranch = nil
+			}
+		}
+		return node
+	case WhileStmt:
+		node.Condition = expr(node.Condition, f)
+		node.Body = stmt(node.Body, f)
+		return node
+	case FunctionStmt:
+		node.Body = rewriteStmts(node.Body, f)
+		return node
+	case ReturnStmt:
+		node.Value = optionalExpr(node.Value, f)
+		return node
+	case ClassStmt:
+		if node.Superclass != nil {
+			superclass := mustBe[Variable](Rewrite(*node.Superclass, f), "a superclass")
+			node.Superclass = &superclass
+		}
+		methods := make([]FunctionStmt, 0, len(node.Methods))
+		for _, method := range node.Methods {
+			if rewritten := Rewrite(method, f); rewritten != nil {
+				methods = append(methods, mustBe[FunctionStmt](rewritten, "a method"))
+			}
+		}
+		node.Methods = methods
+		return node
+	}
+	return node
+}
+
+// rewriteStmts rewrites a list of statements, leaving out those replaced by nil.
+func rewriteStmts(stmts []Stmt, f func(Node) Node) []Stmt {
+	rewritten := make([]Stmt, 0, len(stmts))
+	for _, node := range stmts {
+		if replacement := Rewrite(node, f); replacement != nil {
+			rewritten = append(rewritten, mustBe[Stmt](replacement, "a statement"))
+		}
+	}
+	return rewritten
+}
+
+func expr(node Expr, f func(Node) Node) Expr {
+	return mustBe[Expr](Rewrite(node, f), "an expression")
+}
+
+func optionalExpr(node Expr, f func(Node) Node) Expr {
+	if node == nil {
+		return nil
+	}
+	if replacement := Rewrite(node, f); replacement != nil {
+		return mustBe[Expr](replacement, "an expression")
+	}
+	return nil
+}
+
+func stmt(node Stmt, f func(Node) Node) Stmt {
+	return mustBe[Stmt](Rewrite(node, f), "a statement")
+}
+
+// mustBe checks the kind of a replacement.
+func mustBe[T any](node Node, what string) T {
+	value, ok := node.(T)
+	if !ok {
+		panic(fmt.Sprintf("ast: %s cannot be replaced by %T", what, node))
+	}
+	return value
+}
@@ -0,0 +1,184 @@
+// Package cli implements the command line of the interpreter: a set of
+// subcommands sharing the way programs are read and exit codes reported.
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// PROGRAM is the name the command line goes by in its help.
+const PROGRAM = "go-interpreter"
+
+// Exit codes, the same for every command. The program errors follow
+// sysexits(3): bad input and internal failure.
+const (
+	EXIT_OK            = 0
+	EXIT_FAILURE       = 1
+	EXIT_USAGE         = 2
+	EXIT_PROGRAM_ERROR = 65
+	EXIT_RUNTIME_ERROR = 70
+)
+
+// STDIN is the file argument that reads the program from standard input.
+const STDIN = "-"
+
+// command is a subcommand. usage lists its arguments and summary is the
+// one-line description shown by help. run gets the arguments that follow
+// the name of the command and returns the exit code.
+type command struct {
+	usage   string
+	summary string
+	run     func(cli *cli, args []string) int
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"run":     {"run [flags] <file>", "run a program", runCommand},
+		"repl":    {"repl", "start an interactive session", replCommand},
+		"tokens":  {"tokens [flags] <file>", "print the tokens the scanner produces for a program", tokensCommand},
+		"ast":     {"ast [flags] <file>", "print the parse tree of a program", astCommand},
+		"check":   {"check [flags] <file>", "report the errors of a program without running it", checkCommand},
+		"fmt":     {"fmt [flags] <file>", "print a program in canonical format", fmtCommand},
+		"dot":     {"dot [flags] <file>", "render the parse tree or control flow of a program as a Graphviz graph", dotCommand},
+		"explain": {"explain [code...]", "describe error codes, or list them all", explainCommand},
+		"help":    {"help [command]", "show help for the command line or a command", helpCommand},
+	}
+}
+
+// cli is a single invocation of the command line.
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// Run runs the command line with the given arguments, not including the
+// program name, and returns the exit code. Without a command the arguments
+// are those of 'run', and without any arguments an interactive session is
+// started, as before there were commands.
+func Run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	c := &cli{stdin: stdin, stdout: stdout, stderr: stderr}
+	if len(args) == 0 {
+		return replCommand(c, nil)
+	}
+	switch args[0] {
+	case "-h", "-help", "--help":
+		return helpCommand(c, args[1:])
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return runCommand(c, args)
+	}
+	return cmd.run(c, args[1:])
+}
+
+// flags creates the flag set of a command. Its usage is printed by parse.
+func (c *cli) flags(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(c.stderr)
+	flags.Usage = func() {}
+	return flags
+}
+
+// parse parses the arguments of a command. When it returns false the
+// command is over, with the returned exit code: help was asked for, or
+// the flags were wrong.
+func (c *cli) parse(flags *flag.FlagSet, args []string) (int, bool) {
+	err := flags.Parse(args)
+	if err == flag.ErrHelp {
+		c.usage(c.stdout, flags)
+		return EXIT_OK, false
+	}
+	if err != nil {
+		c.usage(c.stderr, flags)
+		return EXIT_USAGE, false
+	}
+	return EXIT_OK, true
+}
+
+// usage describes a command and its flags.
+func (c *cli) usage(out io.Writer, flags *flag.FlagSet) {
+	cmd := commands[flags.Name()]
+	fmt.Fprintf(out, "usage: %s %s\n\n%s.\n", PROGRAM, cmd.usage, capitalize(cmd.summary))
+	hasFlags := false
+	flags.VisitAll(func(*flag.Flag) { hasFlags = true })
+	if hasFlags {
+		fmt.Fprintln(out, "\nflags:")
+		flags.SetOutput(out)
+		flags.PrintDefaults()
+		flags.SetOutput(c.stderr)
+	}
+}
+
+// source reads the program named by the only argument left after the
+// flags, from standard input when it is STDIN. It returns the name the
+// diagnostics refer to the program by.
+func (c *cli) source(flags *flag.FlagSet) (string, string, int, bool) {
+	if flags.NArg() != 1 {
+		fmt.Fprintf(c.stderr, "%s: expected one file, got %d\n", flags.Name(), flags.NArg())
+		c.usage(c.stderr, flags)
+		return "", "", EXIT_USAGE, false
+	}
+	path := flags.Arg(0)
+	var source []byte
+	var err error
+	if path == STDIN {
+		path = "<stdin>"
+		source, err = io.ReadAll(c.stdin)
+	} else {
+		source, err = os.ReadFile(path)
+	}
+	if err != nil {
+		fmt.Fprintf(c.stderr, "error: %v\n", err)
+		return "", "", EXIT_FAILURE, false
+	}
+	return path, string(source), EXIT_OK, true
+}
+
+func helpCommand(c *cli, args []string) int {
+	if len(args) > 0 {
+		cmd, ok := commands[args[0]]
+		if !ok || args[0] == "help" {
+			fmt.Fprintf(c.stderr, "unknown command %q\n", args[0])
+			return EXIT_USAGE
+		}
+		return cmd.run(c, []string{"-help"})
+	}
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(c.stdout, "usage: %s <command> [flags] [arguments]\n\ncommands:\n", PROGRAM)
+	for _, name := range names {
+		fmt.Fprintf(c.stdout, "  %-8s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintf(c.stdout, `
+A <file> of %q reads the program from standard input. Without a command
+the arguments are those of 'run', and without any arguments an
+interactive session is started. Run '%s help <command>' for the
+flags of a command.
+
+exit status:
+  %-3d success
+  %-3d the program could not be read, or the session failed
+  %-3d wrong usage of the command line
+  %-3d the program has errors and did not run
+  %-3d the program failed while running
+`, STDIN, PROGRAM, EXIT_OK, EXIT_FAILURE, EXIT_USAGE, EXIT_PROGRAM_ERROR, EXIT_RUNTIME_ERROR)
+	return EXIT_OK
+}
+
+func capitalize(text string) string {
+	if text == "" {
+		return text
+	}
+	return strings.ToUpper(text[:1]) + text[1:]
+}
@@ -0,0 +1,292 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/go-interpreter/internal/analysis"
+	"github.com/go-interpreter/internal/dot"
+	"github.com/go-interpreter/internal/errors"
+	"github.com/go-interpreter/internal/formatter"
+	"github.com/go-interpreter/internal/printer"
+	"github.com/go-interpreter/internal/repl"
+	"github.com/go-interpreter/internal/scanner"
+	"github.com/go-interpreter/internal/serializer"
+)
+
+func backendFlag(flags *flag.FlagSet) *string {
+	return flags.String("backend", string(repl.TREE_WALKER),
+		fmt.Sprintf("execution backend: %q (tree-walking interpreter) or %q (bytecode virtual machine)",
+			repl.TREE_WALKER, repl.BYTECODE_VM))
+}
+
+func diagnosticsFlag(flags *flag.FlagSet) *string {
+	return flags.String("diagnostics", string(errors.TEXT_FORMAT),
+		fmt.Sprintf("how to write diagnostics to stderr: %q, %q (one object per line) or %q (a SARIF 2.1.0 log)",
+			errors.TEXT_FORMAT, errors.JSON_FORMAT, errors.SARIF_FORMAT))
+}
+
+// format checks the value of the diagnostics flag.
+func (c *cli) format(flags *flag.FlagSet, diagnostics string) (errors.Format, int, bool) {
+	switch format := errors.Format(diagnostics); format {
+	case errors.TEXT_FORMAT, errors.JSON_FORMAT, errors.SARIF_FORMAT:
+		return format, EXIT_OK, true
+	}
+	fmt.Fprintf(c.stderr, "unknown diagnostics format %q\n", diagnostics)
+	c.usage(c.stderr, flags)
+	return "", EXIT_USAGE, false
+}
+
+// reporter writes the diagnostics of a program to stderr.
+func (c *cli) reporter(format errors.Format, name string, source string) errors.Reporter {
+	return errors.NewReporter(format, c.stderr, name, source, repl.IsTerminal(c.stderr))
+}
+
+// report hands diagnostics to the reporter and returns the exit code they call for.
+func (c *cli) report(reporter errors.Reporter, diagnostics []errors.ExecutionError) int {
+	for _, diagnostic := range diagnostics {
+		reporter.Report(diagnostic)
+	}
+	if err := reporter.Flush(); err != nil {
+		fmt.Fprintf(c.stderr, "error: %v\n", err)
+		return EXIT_FAILURE
+	}
+	if len(diagnostics) > 0 {
+		return EXIT_PROGRAM_ERROR
+	}
+	return EXIT_OK
+}
+
+func runCommand(c *cli, args []string) int {
+	flags := c.flags("run")
+	backend := backendFlag(flags)
+	diagnostics := diagnosticsFlag(flags)
+	if code, ok := c.parse(flags, args); !ok {
+		return code
+	}
+	r := repl.NewRepl()
+	r.Errors = c.stderr
+	r.Output = c.stdout
+	r.Input = c.stdin
+	switch repl.Backend(*backend) {
+	case repl.TREE_WALKER, repl.BYTECODE_VM:
+		r.Backend = repl.Backend(*backend)
+	default:
+		fmt.Fprintf(c.stderr, "unknown backend %q\n", *backend)
+		c.usage(c.stderr, flags)
+		return EXIT_USAGE
+	}
+	format, code, ok := c.format(flags, *diagnostics)
+	if !ok {
+		return code
+	}
+	r.Format = format
+	name, source, code, ok := c.source(flags)
+	if !ok {
+		return code
+	}
+	r.Run(name, source)
+	if r.HadError {
+		return EXIT_PROGRAM_ERROR
+	}
+	if r.HadRuntimeError {
+		return EXIT_RUNTIME_ERROR
+	}
+	return EXIT_OK
+}
+
+func replCommand(c *cli, args []string) int {
+	flags := c.flags("repl")
+	if code, ok := c.parse(flags, args); !ok {
+		return code
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintf(c.stderr, "repl: unexpected arguments %q\n", flags.Args())
+		c.usage(c.stderr, flags)
+		return EXIT_USAGE
+	}
+	if err := repl.NewRepl().Start(c.stdin, c.stdout); err != nil {
+		fmt.Fprintf(c.stderr, "error: %v\n", err)
+		return EXIT_FAILURE
+	}
+	return EXIT_OK
+}
+
+func checkCommand(c *cli, args []string) int {
+	flags := c.flags("check")
+	diagnostics := diagnosticsFlag(flags)
+	if code, ok := c.parse(flags, args); !ok {
+		return code
+	}
+	format, code, ok := c.format(flags, *diagnostics)
+	if !ok {
+		return code
+	}
+	name, source, code, ok := c.source(flags)
+	if !ok {
+		return code
+	}
+	r := repl.NewRepl()
+	r.Errors = c.stderr
+	r.Format = format
+	r.Check(name, source)
+	if r.HadError {
+		return EXIT_PROGRAM_ERROR
+	}
+	return EXIT_OK
+}
+
+func tokensCommand(c *cli, args []string) int {
+	flags := c.flags("tokens")
+	diagnostics := diagnosticsFlag(flags)
+	if code, ok := c.parse(flags, args); !ok {
+		return code
+	}
+	format, code, ok := c.format(flags, *diagnostics)
+	if !ok {
+		return code
+	}
+	name, source, code, ok := c.source(flags)
+	if !ok {
+		return code
+	}
+	tokenScanner := scanner.NewTokenScanner(source)
+	tokens, scanErrors := tokenScanner.ScanTokens()
+	for _, tok := range tokens {
+		fmt.Fprintln(c.stdout, tok.Describe())
+	}
+	return c.report(c.reporter(format, name, source), scanErrors)
+}
+
+// JSON_TREE is the format of the ast command printing the tree as JSON,
+// as package serializer writes it.
+const JSON_TREE = "json"
+
+func astCommand(c *cli, args []string) int {
+	flags := c.flags("ast")
+	style := flags.String("format", string(printer.TREE_FORMAT),
+		fmt.Sprintf("how to print the tree: %q (a node per line), %q (an S-expression per statement) or %q",
+			printer.TREE_FORMAT, printer.SEXPR_FORMAT, JSON_TREE))
+	diagnostics := diagnosticsFlag(flags)
+	if code, ok := c.parse(flags, args); !ok {
+		return code
+	}
+	switch printer.Format(*style) {
+	case printer.TREE_FORMAT, printer.SEXPR_FORMAT, JSON_TREE:
+	default:
+		fmt.Fprintf(c.stderr, "unknown tree format %q\n", *style)
+		c.usage(c.stderr, flags)
+		return EXIT_USAGE
+	}
+	format, code, ok := c.format(flags, *diagnostics)
+	if !ok {
+		return code
+	}
+	name, source, code, ok := c.source(flags)
+	if !ok {
+		return code
+	}
+	stmts, syntaxErrors := analysis.Parse(analysis.Program{File: name, Source: source})
+	if len(syntaxErrors) > 0 {
+		return c.report(c.reporter(format, name, source), syntaxErrors)
+	}
+	if *style != JSON_TREE {
+		fmt.Fprint(c.stdout, printer.Print(printer.Format(*style), stmts))
+		return EXIT_OK
+	}
+	data, err := serializer.MarshalIndent(stmts)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "error: %v\n", err)
+		return EXIT_FAILURE
+	}
+	fmt.Fprintf(c.stdout, "%s\n", data)
+	return EXIT_OK
+}
+
+func fmtCommand(c *cli, args []string) int {
+	flags := c.flags("fmt")
+	write := flags.Bool("w", false, "write the result back to the file instead of printing it")
+	diagnostics := diagnosticsFlag(flags)
+	if code, ok := c.parse(flags, args); !ok {
+		return code
+	}
+	format, code, ok := c.format(flags, *diagnostics)
+	if !ok {
+		return code
+	}
+	name, source, code, ok := c.source(flags)
+	if !ok {
+		return code
+	}
+	formatted, syntaxErrors := formatter.Format(source)
+	if len(syntaxErrors) > 0 {
+		return c.report(c.reporter(format, name, source), syntaxErrors)
+	}
+	if !*write {
+		fmt.Fprint(c.stdout, formatted)
+		return EXIT_OK
+	}
+	if flags.Arg(0) == STDIN {
+		fmt.Fprintln(c.stderr, "fmt: cannot write the result back to standard input")
+		return EXIT_USAGE
+	}
+	if err := os.WriteFile(name, []byte(formatted), 0o644); err != nil {
+		fmt.Fprintf(c.stderr, "error: %v\n", err)
+		return EXIT_FAILURE
+	}
+	return EXIT_OK
+}
+
+func dotCommand(c *cli, args []string) int {
+	flags := c.flags("dot")
+	cfg := flags.Bool("cfg", false, "render the control-flow graph of every function instead of the parse tree")
+	diagnostics := diagnosticsFlag(flags)
+	if code, ok := c.parse(flags, args); !ok {
+		return code
+	}
+	format, code, ok := c.format(flags, *diagnostics)
+	if !ok {
+		return code
+	}
+	name, source, code, ok := c.source(flags)
+	if !ok {
+		return code
+	}
+	stmts, syntaxErrors := analysis.Parse(analysis.Program{File: name, Source: source})
+	if len(syntaxErrors) > 0 {
+		return c.report(c.reporter(format, name, source), syntaxErrors)
+	}
+	if *cfg {
+		fmt.Fprint(c.stdout, dot.CFG(stmts))
+	} else {
+		fmt.Fprint(c.stdout, dot.AST(stmts))
+	}
+	return EXIT_OK
+}
+
+func explainCommand(c *cli, args []string) int {
+	flags := c.flags("explain")
+	if code, ok := c.parse(flags, args); !ok {
+		return code
+	}
+	if flags.NArg() == 0 {
+		for _, code := range errors.Codes() {
+			explanation, _ := errors.Explain(string(code))
+			fmt.Fprintf(c.stdout, "%s  %s\n", code, explanation.Title)
+		}
+		return EXIT_OK
+	}
+	for index, code := range flags.Args() {
+		explanation, ok := errors.Explain(code)
+		if !ok {
+			fmt.Fprintf(c.stderr, "unknown error code %q (run '%s explain' to list them)\n", code, PROGRAM)
+			return EXIT_USAGE
+		}
+		if index > 0 {
+			fmt.Fprintln(c.stdout)
+		}
+		fmt.Fprint(c.stdout, explanation)
+	}
+	return EXIT_OK
+}
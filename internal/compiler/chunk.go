@@ -0,0 +1,107 @@
+package compiler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Chunk is a sequence of bytecode together with the constants it refers
+// to and a line table mapping every instruction back to the source.
+type Chunk struct {
+	Code      []byte
+	Constants []any
+	Lines     []LineStart
+}
+
+// LineStart marks the first instruction generated for a source position.
+// Consecutive instructions from the same position share a single entry,
+// which keeps the table much smaller than the code itself.
+type LineStart struct {
+	Offset int
+	Line   int
+	Column int
+	Length int
+	Char   int
+}
+
+// Write appends a byte to the chunk, extending the line table if the
+// source position differs from that of the previous byte.
+func (chunk *Chunk) Write(b byte, line int, column int, length int, char int) {
+	last := len(chunk.Lines) - 1
+	if last < 0 || chunk.Lines[last].Line != line || chunk.Lines[last].Char != char {
+		chunk.Lines = append(chunk.Lines, LineStart{Offset: len(chunk.Code), Line: line, Column: column, Length: length, Char: char})
+	}
+	chunk.Code = append(chunk.Code, b)
+}
+
+// AddConstant appends a value to the constant pool and returns its index.
+func (chunk *Chunk) AddConstant(value any) int {
+	chunk.Constants = append(chunk.Constants, value)
+	return len(chunk.Constants) - 1
+}
+
+// Position returns the line table entry for the instruction at the given
+// offset in the code, which holds its source position.
+func (chunk *Chunk) Position(offset int) LineStart {
+	index := sort.Search(len(chunk.Lines), func(i int) bool {
+		return chunk.Lines[i].Offset > offset
+	}) - 1
+	if index < 0 {
+		return LineStart{}
+	}
+	return chunk.Lines[index]
+}
+
+// Disassemble renders the chunk as a human readable listing, one
+// instruction per line. It is meant for debugging the compiler.
+func (chunk *Chunk) Disassemble(name string) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "== %s ==\n", name)
+	for offset := 0; offset < len(chunk.Code); {
+		offset = chunk.disassembleInstruction(&builder, offset)
+	}
+	return builder.String()
+}
+
+func (chunk *Chunk) disassembleInstruction(builder *strings.Builder, offset int) int {
+	op := OpCode(chunk.Code[offset])
+	fmt.Fprintf(builder, "%04d %4d %-16s", offset, chunk.Position(offset).Line, op)
+	switch op {
+	case OP_CONSTANT, OP_GET_GLOBAL, OP_DEFINE_GLOBAL, OP_SET_GLOBAL,
+		OP_GET_PROPERTY, OP_SET_PROPERTY, OP_GET_SUPER, OP_CLASS, OP_METHOD:
+		index := chunk.readShort(offset + 1)
+		fmt.Fprintf(builder, " %4d '%v'\n", index, chunk.Constants[index])
+		return offset + 3
+	case OP_GET_LOCAL, OP_SET_LOCAL, OP_GET_UPVALUE, OP_SET_UPVALUE, OP_CALL:
+		fmt.Fprintf(builder, " %4d\n", chunk.Code[offset+1])
+		return offset + 2
+	case OP_JUMP, OP_JUMP_IF_FALSE:
+		fmt.Fprintf(builder, " %4d -> %d\n", offset, offset+3+chunk.readShort(offset+1))
+		return offset + 3
+	case OP_LOOP:
+		fmt.Fprintf(builder, " %4d -> %d\n", offset, offset+3-chunk.readShort(offset+1))
+		return offset + 3
+	case OP_CLOSURE:
+		index := chunk.readShort(offset + 1)
+		function := chunk.Constants[index].(*Function)
+		fmt.Fprintf(builder, " %4d %v\n", index, function)
+		offset += 3
+		for range function.UpvalueCount {
+			kind := "upvalue"
+			if chunk.Code[offset] == 1 {
+				kind = "local"
+			}
+			fmt.Fprintf(builder, "%04d    |                     %s %d\n", offset, kind, chunk.Code[offset+1])
+			offset += 2
+		}
+		return offset
+	default:
+		builder.WriteString("\n")
+		return offset + 1
+	}
+}
+
+func (chunk *Chunk) readShort(offset int) int {
+	return int(chunk.Code[offset])<<8 | int(chunk.Code[offset+1])
+}
@@ -0,0 +1,809 @@
+package compiler
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/go-interpreter/internal/ast"
+	"github.com/go-interpreter/internal/errors"
+	"github.com/go-interpreter/internal/token"
+)
+
+/*
+	The compiler lowers the statements produced by the parser into bytecode
+	for the virtual machine in internal/vm. It is a single pass over the
+	tree; every function body, including the top level script, gets its own
+	Function with its own Chunk.
+
+	Locals live on the VM stack and are addressed by slot. Variables of an
+	enclosing function that are captured by a closure are reached through
+	upvalues, exactly like in clox. Everything else is a global, looked up
+	by name at runtime.
+*/
+
+// FunctionType tells the compiler what kind of body it is compiling.
+type FunctionType int
+
+const (
+	TYPE_SCRIPT FunctionType = iota
+	TYPE_FUNCTION
+	TYPE_METHOD
+	TYPE_INITIALIZER
+)
+
+const (
+	maxLocals   = math.MaxUint8 + 1
+	maxUpvalues = math.MaxUint8 + 1
+	maxShort    = math.MaxUint16
+)
+
+type local struct {
+	name       string
+	depth      int
+	isCaptured bool
+}
+
+type upvalue struct {
+	index   byte
+	isLocal bool
+}
+
+// loop records what 'break' and 'continue' need to know about the
+// innermost enclosing loop.
+type loop struct {
+	start      int
+	scopeDepth int
+	breaks     []int
+}
+
+// functionState holds the bookkeeping for the function being compiled.
+// Nested function declarations push a new state linked to the enclosing one.
+type functionState struct {
+	enclosing    *functionState
+	function     *Function
+	functionType FunctionType
+	locals       []local
+	upvalues     []upvalue
+	scopeDepth   int
+	loops        []loop
+	constants    map[any]int
+}
+
+type classState struct {
+	enclosing     *classState
+	hasSuperclass bool
+}
+
+// Compiler turns a parsed program into a Function for the virtual machine.
+// Line, Column, Length and Char hold the position of the last node with a
+// token, which is recorded in the line table for every byte emitted.
+type Compiler struct {
+	current      *functionState
+	currentClass *classState
+	line         int
+	column       int
+	length       int
+	char         int
+}
+
+// NewCompiler creates a compiler ready to compile a top level script.
+func NewCompiler() *Compiler {
+	return &Compiler{}
+}
+
+// Compile compiles the statements of a program into the script Function.
+// The statements are expected to have passed the resolver already.
+func (c *Compiler) Compile(stmts []ast.Stmt) (*Function, error) {
+	c.beginFunction(TYPE_SCRIPT, "")
+	for _, stmt := range stmts {
+		if stmt == nil {
+			return nil, c.error(errors.INTERNAL_ERROR, "Cannot compile a program with syntax errors.")
+		}
+		if _, err := stmt.Accept(c); err != nil {
+			return nil, err
+		}
+	}
+	function, _ := c.endFunction()
+	return function, nil
+}
+
+func (c *Compiler) VisitExpressionStmt(stmt ast.ExpressionStmt) (any, error) {
+	if err := c.expression(stmt.Expression); err != nil {
+		return nil, err
+	}
+	c.emitOp(OP_POP)
+	return nil, nil
+}
+
+func (c *Compiler) VisitPrintStmt(stmt ast.PrintStmt) (any, error) {
+	if err := c.expression(stmt.Expression); err != nil {
+		return nil, err
+	}
+	c.emitOp(OP_PRINT)
+	return nil, nil
+}
+
+// VisitVarStmt leaves the initial value on the stack. Inside a scope that
+// stack slot simply becomes the local; at the top level it is moved into
+// the globals table.
+func (c *Compiler) VisitVarStmt(stmt ast.VarStmt) (any, error) {
+	c.at(stmt.Name)
+	if stmt.Initializer != nil {
+		if err := c.expression(stmt.Initializer); err != nil {
+			return nil, err
+		}
+	} else {
+		c.emitOp(OP_NIL)
+	}
+	return nil, c.defineVariable(stmt.Name)
+}
+
+func (c *Compiler) VisitBlockStmt(stmt ast.Block) (any, error) {
+	c.beginScope()
+	if err := c.statements(stmt.Statements); err != nil {
+		return nil, err
+	}
+	c.endScope()
+	return nil, nil
+}
+
+func (c *Compiler) VisitIfStmt(stmt ast.IfStmt) (any, error) {
+	if err := c.expression(stmt.Condition); err != nil {
+		return nil, err
+	}
+	thenJump := c.emitJump(OP_JUMP_IF_FALSE)
+	c.emitOp(OP_POP)
+	if err := c.statement(stmt.ThenBranch); err != nil {
+		return nil, err
+	}
+	elseJump := c.emitJump(OP_JUMP)
+	if err := c.patchJump(thenJump); err != nil {
+		return nil, err
+	}
+	c.emitOp(OP_POP)
+	if stmt.ElseBranch != nil {
+		if err := c.statement(stmt.ElseBranch); err != nil {
+			return nil, err
+		}
+	}
+	return nil, c.patchJump(elseJump)
+}
+
+// VisitWhileStmt compiles the condition at the top of the loop. 'break'
+// jumps land after the condition has been popped, so they are patched last.
+func (c *Compiler) VisitWhileStmt(stmt ast.WhileStmt) (any, error) {
+	loopStart := len(c.chunk().Code)
+	if err := c.expression(stmt.Condition); err != nil {
+		return nil, err
+	}
+	exitJump := c.emitJump(OP_JUMP_IF_FALSE)
+	c.emitOp(OP_POP)
+
+	c.current.loops = append(c.current.loops, loop{start: loopStart, scopeDepth: c.current.scopeDepth})
+	if err := c.statement(stmt.Body); err != nil {
+		return nil, err
+	}
+	if err := c.emitLoop(loopStart); err != nil {
+		return nil, err
+	}
+	innermost := c.current.loops[len(c.current.loops)-1]
+	c.current.loops = c.current.loops[:len(c.current.loops)-1]
+
+	if err := c.patchJump(exitJump); err != nil {
+		return nil, err
+	}
+	c.emitOp(OP_POP)
+	for _, breakJump := range innermost.breaks {
+		if err := c.patchJump(breakJump); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func (c *Compiler) VisitBreakStmt() (any, error) {
+	innermost := &c.current.loops[len(c.current.loops)-1]
+	c.discardLocals(innermost.scopeDepth)
+	innermost.breaks = append(innermost.breaks, c.emitJump(OP_JUMP))
+	return nil, nil
+}
+
+func (c *Compiler) VisitContinueStmt() (any, error) {
+	innermost := c.current.loops[len(c.current.loops)-1]
+	c.discardLocals(innermost.scopeDepth)
+	return nil, c.emitLoop(innermost.start)
+}
+
+// VisitFunctionStmt marks a local function as initialised before its body
+// is compiled so that it can call itself recursively.
+func (c *Compiler) VisitFunctionStmt(stmt ast.FunctionStmt) (any, error) {
+	c.at(stmt.Name)
+	if c.current.scopeDepth > 0 {
+		if err := c.addLocal(stmt.Name.Lexeme); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.function(stmt, TYPE_FUNCTION); err != nil {
+		return nil, err
+	}
+	if c.current.scopeDepth > 0 {
+		return nil, nil
+	}
+	return nil, c.emitNamed(OP_DEFINE_GLOBAL, stmt.Name.Lexeme)
+}
+
+func (c *Compiler) VisitReturnStmt(stmt ast.ReturnStmt) (any, error) {
+	c.at(stmt.Keyword)
+	if stmt.Value == nil {
+		c.emitReturn()
+		return nil, nil
+	}
+	if err := c.expression(stmt.Value); err != nil {
+		return nil, err
+	}
+	c.emitOp(OP_RETURN)
+	return nil, nil
+}
+
+// VisitClassStmt creates the class, then loads it back on the stack to
+// attach the methods one by one. With a superclass, an extra scope holds
+// it in a local called 'super' for the methods to capture.
+func (c *Compiler) VisitClassStmt(stmt ast.ClassStmt) (any, error) {
+	c.at(stmt.Name)
+	if c.current.scopeDepth > 0 {
+		if err := c.addLocal(stmt.Name.Lexeme); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.emitNamed(OP_CLASS, stmt.Name.Lexeme); err != nil {
+		return nil, err
+	}
+	if c.current.scopeDepth == 0 {
+		if err := c.emitNamed(OP_DEFINE_GLOBAL, stmt.Name.Lexeme); err != nil {
+			return nil, err
+		}
+	}
+
+	c.currentClass = &classState{enclosing: c.currentClass}
+	defer func() { c.currentClass = c.currentClass.enclosing }()
+
+	if stmt.Superclass != nil {
+		if _, err := c.VisitVariable(*stmt.Superclass); err != nil {
+			return nil, err
+		}
+		c.beginScope()
+		if err := c.addLocal("super"); err != nil {
+			return nil, err
+		}
+		if err := c.namedVariable(stmt.Name, false); err != nil {
+			return nil, err
+		}
+		c.at(stmt.Superclass.Name)
+		c.emitOp(OP_INHERIT)
+		c.currentClass.hasSuperclass = true
+	}
+
+	if err := c.namedVariable(stmt.Name, false); err != nil {
+		return nil, err
+	}
+	for _, method := range stmt.Methods {
+		functionType := TYPE_METHOD
+		if method.Name.Lexeme == "init" {
+			functionType = TYPE_INITIALIZER
+		}
+		if err := c.function(method, functionType); err != nil {
+			return nil, err
+		}
+		if err := c.emitNamed(OP_METHOD, method.Name.Lexeme); err != nil {
+			return nil, err
+		}
+	}
+	c.emitOp(OP_POP)
+
+	if c.currentClass.hasSuperclass {
+		c.endScope()
+	}
+	return nil, nil
+}
+
+func (c *Compiler) VisitLiteral(expr ast.Literal) (any, error) {
+	switch value := expr.Value.(type) {
+	case nil:
+		c.emitOp(OP_NIL)
+	case bool:
+		if value {
+			c.emitOp(OP_TRUE)
+		} else {
+			c.emitOp(OP_FALSE)
+		}
+	default:
+		return nil, c.emitConstant(value)
+	}
+	return nil, nil
+}
+
+func (c *Compiler) VisitGrouping(expr ast.Grouping) (any, error) {
+	return nil, c.expression(expr.Expression)
+}
+
+func (c *Compiler) VisitUnary(expr ast.Unary) (any, error) {
+	if err := c.expression(expr.Right); err != nil {
+		return nil, err
+	}
+	c.at(expr.Operator)
+	switch expr.Operator.Type {
+	case token.MINUS:
+		c.emitOp(OP_NEGATE)
+	case token.BANG:
+		c.emitOp(OP_NOT)
+	default:
+		return nil, c.error(errors.INTERNAL_ERROR, fmt.Sprintf("%s is not a valid operator", expr.Operator.Lexeme))
+	}
+	return nil, nil
+}
+
+// VisitBinary evaluates both operands left to right. Operators without an
+// instruction of their own are built from the negation of their opposite.
+func (c *Compiler) VisitBinary(expr ast.Binary) (any, error) {
+	if err := c.expression(expr.Left); err != nil {
+		return nil, err
+	}
+	if err := c.expression(expr.Right); err != nil {
+		return nil, err
+	}
+	c.at(expr.Operator)
+	switch expr.Operator.Type {
+	case token.PLUS, token.INC:
+		c.emitOp(OP_ADD)
+	case token.MINUS, token.DEC:
+		c.emitOp(OP_SUBTRACT)
+	case token.STAR:
+		c.emitOp(OP_MULTIPLY)
+	case token.SLASH:
+		c.emitOp(OP_DIVIDE)
+	case token.GREATER:
+		c.emitOp(OP_GREATER)
+	case token.GREATER_EQUAL:
+		c.emitOps(OP_LESS, OP_NOT)
+	case token.LESS:
+		c.emitOp(OP_LESS)
+	case token.LESS_EQUAL:
+		c.emitOps(OP_GREATER, OP_NOT)
+	case token.EQUAL_EQUAL:
+		c.emitOp(OP_EQUAL)
+	case token.BANG_EQUAL:
+		c.emitOps(OP_EQUAL, OP_NOT)
+	default:
+		return nil, c.error(errors.INTERNAL_ERROR, fmt.Sprintf("%s is not a valid operator", expr.Operator.Lexeme))
+	}
+	return nil, nil
+}
+
+// VisitLogical short-circuits with jumps, leaving whichever operand
+// decided the result on the stack.
+func (c *Compiler) VisitLogical(expr ast.Logical) (any, error) {
+	if err := c.expression(expr.Left); err != nil {
+		return nil, err
+	}
+	c.at(expr.Operator)
+	if expr.Operator.Type == token.OR {
+		elseJump := c.emitJump(OP_JUMP_IF_FALSE)
+		endJump := c.emitJump(OP_JUMP)
+		if err := c.patchJump(elseJump); err != nil {
+			return nil, err
+		}
+		c.emitOp(OP_POP)
+		if err := c.expression(expr.Right); err != nil {
+			return nil, err
+		}
+		return nil, c.patchJump(endJump)
+	}
+	endJump := c.emitJump(OP_JUMP_IF_FALSE)
+	c.emitOp(OP_POP)
+	if err := c.expression(expr.Right); err != nil {
+		return nil, err
+	}
+	return nil, c.patchJump(endJump)
+}
+
+func (c *Compiler) VisitVariable(expr ast.Variable) (any, error) {
+	return nil, c.namedVariable(expr.Name, false)
+}
+
+func (c *Compiler) VisitAssign(expr ast.Assign) (any, error) {
+	if err := c.expression(expr.Value); err != nil {
+		return nil, err
+	}
+	return nil, c.namedVariable(expr.Name, true)
+}
+
+func (c *Compiler) VisitCall(expr ast.Call) (any, error) {
+	if err := c.expression(expr.Callee); err != nil {
+		return nil, err
+	}
+	for _, argument := range expr.Arguments {
+		if err := c.expression(argument); err != nil {
+			return nil, err
+		}
+	}
+	c.at(expr.Paren)
+	c.emitBytes(byte(OP_CALL), byte(len(expr.Arguments)))
+	return nil, nil
+}
+
+func (c *Compiler) VisitGet(expr ast.Get) (any, error) {
+	if err := c.expression(expr.Object); err != nil {
+		return nil, err
+	}
+	c.at(expr.Name)
+	return nil, c.emitNamed(OP_GET_PROPERTY, expr.Name.Lexeme)
+}
+
+func (c *Compiler) VisitSet(expr ast.Set) (any, error) {
+	if err := c.expression(expr.Object); err != nil {
+		return nil, err
+	}
+	if err := c.expression(expr.Value); err != nil {
+		return nil, err
+	}
+	c.at(expr.Name)
+	return nil, c.emitNamed(OP_SET_PROPERTY, expr.Name.Lexeme)
+}
+
+// VisitIncrement keeps a copy of the object on the stack, so that it is
+// evaluated only once for reading the property and for writing it back.
+func (c *Compiler) VisitIncrement(expr ast.Increment) (any, error) {
+	if err := c.expression(expr.Object); err != nil {
+		return nil, err
+	}
+	c.at(expr.Name)
+	c.emitOp(OP_DUP)
+	if err := c.emitNamed(OP_GET_PROPERTY, expr.Name.Lexeme); err != nil {
+		return nil, err
+	}
+	c.at(expr.Operator)
+	if err := c.emitConstant(float64(1)); err != nil {
+		return nil, err
+	}
+	c.emitOp(OP_ADD)
+	c.at(expr.Name)
+	return nil, c.emitNamed(OP_SET_PROPERTY, expr.Name.Lexeme)
+}
+
+func (c *Compiler) VisitThis(expr ast.This) (any, error) {
+	return nil, c.namedVariable(expr.Keyword, false)
+}
+
+// VisitSuper loads the receiver and the superclass, and lets the VM bind
+// the superclass method to the receiver.
+func (c *Compiler) VisitSuper(expr ast.Super) (any, error) {
+	this := token.Token{Type: token.THIS, Lexeme: "this", Line: expr.Keyword.Line, Column: expr.Keyword.Column, Char: expr.Keyword.Char}
+	if err := c.namedVariable(this, false); err != nil {
+		return nil, err
+	}
+	if err := c.namedVariable(expr.Keyword, false); err != nil {
+		return nil, err
+	}
+	c.at(expr.Method)
+	return nil, c.emitNamed(OP_GET_SUPER, expr.Method.Lexeme)
+}
+
+func (c *Compiler) statements(stmts []ast.Stmt) error {
+	for _, stmt := range stmts {
+		if err := c.statement(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Compiler) statement(stmt ast.Stmt) error {
+	_, err := stmt.Accept(c)
+	return err
+}
+
+func (c *Compiler) expression(expr ast.Expr) error {
+	_, err := expr.Accept(c)
+	return err
+}
+
+// function compiles a function body into a Function of its own and emits
+// the instruction that wraps it into a closure at runtime.
+func (c *Compiler) function(declaration ast.FunctionStmt, functionType FunctionType) error {
+	c.beginFunction(functionType, declaration.Name.Lexeme)
+	c.current.function.Arity = len(declaration.Params)
+	c.beginScope()
+	for _, param := range declaration.Params {
+		if err := c.addLocal(param.Lexeme); err != nil {
+			return err
+		}
+	}
+	if err := c.statements(declaration.Body); err != nil {
+		return err
+	}
+	function, upvalues := c.endFunction()
+
+	index, err := c.makeConstant(function)
+	if err != nil {
+		return err
+	}
+	c.emitOp(OP_CLOSURE)
+	c.emitShort(index)
+	for _, captured := range upvalues {
+		isLocal := byte(0)
+		if captured.isLocal {
+			isLocal = 1
+		}
+		c.emitBytes(isLocal, captured.index)
+	}
+	return nil
+}
+
+// beginFunction pushes a fresh function state. Stack slot zero belongs to
+// the callee itself; in methods it holds the receiver and is named 'this'.
+func (c *Compiler) beginFunction(functionType FunctionType, name string) {
+	state := &functionState{
+		enclosing:    c.current,
+		function:     &Function{Name: name},
+		functionType: functionType,
+		constants:    make(map[any]int),
+	}
+	slotZero := ""
+	if functionType == TYPE_METHOD || functionType == TYPE_INITIALIZER {
+		slotZero = "this"
+	}
+	state.locals = append(state.locals, local{name: slotZero, depth: 0})
+	c.current = state
+}
+
+// endFunction finishes the current function with an implicit return and
+// pops its state, handing back the upvalues the closure has to capture.
+func (c *Compiler) endFunction() (*Function, []upvalue) {
+	c.emitReturn()
+	state := c.current
+	state.function.UpvalueCount = len(state.upvalues)
+	c.current = state.enclosing
+	return state.function, state.upvalues
+}
+
+func (c *Compiler) beginScope() {
+	c.current.scopeDepth++
+}
+
+// endScope pops the locals of the scope, closing the ones captured by
+// a closure so that they outlive the stack slot.
+func (c *Compiler) endScope() {
+	c.current.scopeDepth--
+	c.discardLocals(c.current.scopeDepth)
+	for len(c.current.locals) > 0 && c.current.locals[len(c.current.locals)-1].depth > c.current.scopeDepth {
+		c.current.locals = c.current.locals[:len(c.current.locals)-1]
+	}
+}
+
+// discardLocals emits the instructions that drop every local deeper than
+// the given depth, without forgetting them at compile time. Jumping out
+// of a loop needs the former; leaving a scope needs both.
+func (c *Compiler) discardLocals(depth int) {
+	for index := len(c.current.locals) - 1; index >= 0 && c.current.locals[index].depth > depth; index-- {
+		if c.current.locals[index].isCaptured {
+			c.emitOp(OP_CLOSE_UPVALUE)
+		} else {
+			c.emitOp(OP_POP)
+		}
+	}
+}
+
+// defineVariable binds the value on top of the stack to the name, either
+// as a new local in the current scope or as a global.
+func (c *Compiler) defineVariable(name token.Token) error {
+	if c.current.scopeDepth > 0 {
+		return c.addLocal(name.Lexeme)
+	}
+	return c.emitNamed(OP_DEFINE_GLOBAL, name.Lexeme)
+}
+
+func (c *Compiler) addLocal(name string) error {
+	if len(c.current.locals) >= maxLocals {
+		return c.error(errors.TOO_MANY_LOCALS, "Too many local variables in function.")
+	}
+	c.current.locals = append(c.current.locals, local{name: name, depth: c.current.scopeDepth})
+	return nil
+}
+
+// namedVariable emits a get or set for a name, looking for it among the
+// locals first, then the enclosing functions, and finally the globals.
+func (c *Compiler) namedVariable(name token.Token, assign bool) error {
+	c.at(name)
+	if slot := resolveLocal(c.current, name.Lexeme); slot >= 0 {
+		op := OP_GET_LOCAL
+		if assign {
+			op = OP_SET_LOCAL
+		}
+		c.emitBytes(byte(op), byte(slot))
+		return nil
+	}
+	index, err := c.resolveUpvalue(c.current, name.Lexeme)
+	if err != nil {
+		return err
+	}
+	if index >= 0 {
+		op := OP_GET_UPVALUE
+		if assign {
+			op = OP_SET_UPVALUE
+		}
+		c.emitBytes(byte(op), byte(index))
+		return nil
+	}
+	op := OP_GET_GLOBAL
+	if assign {
+		op = OP_SET_GLOBAL
+	}
+	return c.emitNamed(op, name.Lexeme)
+}
+
+func resolveLocal(state *functionState, name string) int {
+	for index := len(state.locals) - 1; index >= 0; index-- {
+		if state.locals[index].name == name {
+			return index
+		}
+	}
+	return -1
+}
+
+// resolveUpvalue finds a variable in one of the enclosing functions and
+// threads an upvalue through every function in between.
+func (c *Compiler) resolveUpvalue(state *functionState, name string) (int, error) {
+	if state.enclosing == nil {
+		return -1, nil
+	}
+	if slot := resolveLocal(state.enclosing, name); slot >= 0 {
+		state.enclosing.locals[slot].isCaptured = true
+		return c.addUpvalue(state, byte(slot), true)
+	}
+	index, err := c.resolveUpvalue(state.enclosing, name)
+	if err != nil || index < 0 {
+		return index, err
+	}
+	return c.addUpvalue(state, byte(index), false)
+}
+
+func (c *Compiler) addUpvalue(state *functionState, index byte, isLocal bool) (int, error) {
+	for existing, captured := range state.upvalues {
+		if captured.index == index && captured.isLocal == isLocal {
+			return existing, nil
+		}
+	}
+	if len(state.upvalues) >= maxUpvalues {
+		return -1, c.error(errors.TOO_MANY_UPVALUES, "Too many closure variables in function.")
+	}
+	state.upvalues = append(state.upvalues, upvalue{index: index, isLocal: isLocal})
+	return len(state.upvalues) - 1, nil
+}
+
+func (c *Compiler) chunk() *Chunk {
+	return &c.current.function.Chunk
+}
+
+// at moves the position recorded in the line table to the given token.
+func (c *Compiler) at(name token.Token) {
+	c.line = name.Line
+	c.column = name.Column
+	c.length = len(name.Lexeme)
+	c.char = name.Char
+}
+
+func (c *Compiler) emitByte(b byte) {
+	c.chunk().Write(b, c.line, c.column, c.length, c.char)
+}
+
+func (c *Compiler) emitBytes(bytes ...byte) {
+	for _, b := range bytes {
+		c.emitByte(b)
+	}
+}
+
+func (c *Compiler) emitOp(op OpCode) {
+	c.emitByte(byte(op))
+}
+
+func (c *Compiler) emitOps(ops ...OpCode) {
+	for _, op := range ops {
+		c.emitOp(op)
+	}
+}
+
+func (c *Compiler) emitShort(value int) {
+	c.emitBytes(byte(value>>8), byte(value))
+}
+
+// emitReturn emits the implicit return at the end of a body. Initializers
+// always return the receiver sitting in slot zero.
+func (c *Compiler) emitReturn() {
+	if c.current.functionType == TYPE_INITIALIZER {
+		c.emitBytes(byte(OP_GET_LOCAL), 0)
+	} else {
+		c.emitOp(OP_NIL)
+	}
+	c.emitOp(OP_RETURN)
+}
+
+func (c *Compiler) emitConstant(value any) error {
+	index, err := c.makeConstant(value)
+	if err != nil {
+		return err
+	}
+	c.emitOp(OP_CONSTANT)
+	c.emitShort(index)
+	return nil
+}
+
+// emitNamed emits an instruction whose operand is a name in the constant pool.
+func (c *Compiler) emitNamed(op OpCode, name string) error {
+	index, err := c.makeConstant(name)
+	if err != nil {
+		return err
+	}
+	c.emitOp(op)
+	c.emitShort(index)
+	return nil
+}
+
+// makeConstant adds a value to the constant pool of the current function.
+// Numbers and strings are interned so that repeated names share a slot.
+func (c *Compiler) makeConstant(value any) (int, error) {
+	switch value.(type) {
+	case float64, string:
+		if index, ok := c.current.constants[value]; ok {
+			return index, nil
+		}
+	}
+	if len(c.chunk().Constants) > maxShort {
+		return 0, c.error(errors.TOO_MANY_CONSTANTS, "Too many constants in one chunk.")
+	}
+	index := c.chunk().AddConstant(value)
+	switch value.(type) {
+	case float64, string:
+		c.current.constants[value] = index
+	}
+	return index, nil
+}
+
+// emitJump emits a forward jump with a placeholder offset and returns the
+// position of the offset so it can be patched once the target is known.
+func (c *Compiler) emitJump(op OpCode) int {
+	c.emitOp(op)
+	c.emitShort(maxShort)
+	return len(c.chunk().Code) - 2
+}
+
+func (c *Compiler) patchJump(offset int) error {
+	jump := len(c.chunk().Code) - offset - 2
+	if jump > maxShort {
+		return c.error(errors.JUMP_TOO_LONG, "Too much code to jump over.")
+	}
+	c.chunk().Code[offset] = byte(jump >> 8)
+	c.chunk().Code[offset+1] = byte(jump)
+	return nil
+}
+
+func (c *Compiler) emitLoop(loopStart int) error {
+	c.emitOp(OP_LOOP)
+	offset := len(c.chunk().Code) - loopStart + 2
+	if offset > maxShort {
+		return c.error(errors.LOOP_TOO_LONG, "Loop body too large.")
+	}
+	c.emitShort(offset)
+	return nil
+}
+
+func (c *Compiler) error(code errors.Code, message string) error {
+	return errors.ExecutionError{
+		Type:    errors.COMPILER_ERROR,
+		Code:    code,
+		Line:    c.line,
+		Column:  c.column,
+		Length:  c.length,
+		Where:   c.char,
+		Message: message,
+	}
+}
@@ -0,0 +1,20 @@
+package compiler
+
+import "fmt"
+
+// Function is a compiled function body. The top level script is compiled
+// into a Function as well, with an empty name and no parameters.
+type Function struct {
+	Name         string
+	Arity        int
+	UpvalueCount int
+	Chunk        Chunk
+}
+
+// String is used when a function value gets printed.
+func (function *Function) String() string {
+	if function.Name == "" {
+		return "<script>"
+	}
+	return fmt.Sprintf("<fn %s>", function.Name)
+}
@@ -0,0 +1,91 @@
+package compiler
+
+// OpCode is a single bytecode instruction. Some instructions are followed
+// by operands in the byte stream; their layout is noted next to each one.
+type OpCode byte
+
+const (
+	OP_CONSTANT      OpCode = iota // u16 constant index
+	OP_NIL                         //
+	OP_TRUE                        //
+	OP_FALSE                       //
+	OP_POP                         //
+	OP_DUP                         //
+	OP_GET_LOCAL                   // u8 stack slot
+	OP_SET_LOCAL                   // u8 stack slot
+	OP_GET_GLOBAL                  // u16 name constant
+	OP_DEFINE_GLOBAL               // u16 name constant
+	OP_SET_GLOBAL                  // u16 name constant
+	OP_GET_UPVALUE                 // u8 upvalue index
+	OP_SET_UPVALUE                 // u8 upvalue index
+	OP_GET_PROPERTY                // u16 name constant
+	OP_SET_PROPERTY                // u16 name constant
+	OP_GET_SUPER                   // u16 name constant
+	OP_EQUAL                       //
+	OP_GREATER                     //
+	OP_LESS                        //
+	OP_ADD                         //
+	OP_SUBTRACT                    //
+	OP_MULTIPLY                    //
+	OP_DIVIDE                      //
+	OP_NOT                         //
+	OP_NEGATE                      //
+	OP_PRINT                       //
+	OP_JUMP                        // u16 forward offset
+	OP_JUMP_IF_FALSE               // u16 forward offset
+	OP_LOOP                        // u16 backward offset
+	OP_CALL                        // u8 argument count
+	OP_CLOSURE                     // u16 function constant, then (u8 isLocal, u8 index) per upvalue
+	OP_CLOSE_UPVALUE               //
+	OP_RETURN                      //
+	OP_CLASS                       // u16 name constant
+	OP_INHERIT                     //
+	OP_METHOD                      // u16 name constant
+)
+
+var opNames = [...]string{
+	OP_CONSTANT:      "OP_CONSTANT",
+	OP_NIL:           "OP_NIL",
+	OP_TRUE:          "OP_TRUE",
+	OP_FALSE:         "OP_FALSE",
+	OP_POP:           "OP_POP",
+	OP_DUP:           "OP_DUP",
+	OP_GET_LOCAL:     "OP_GET_LOCAL",
+	OP_SET_LOCAL:     "OP_SET_LOCAL",
+	OP_GET_GLOBAL:    "OP_GET_GLOBAL",
+	OP_DEFINE_GLOBAL: "OP_DEFINE_GLOBAL",
+	OP_SET_GLOBAL:    "OP_SET_GLOBAL",
+	OP_GET_UPVALUE:   "OP_GET_UPVALUE",
+	OP_SET_UPVALUE:   "OP_SET_UPVALUE",
+	OP_GET_PROPERTY:  "OP_GET_PROPERTY",
+	OP_SET_PROPERTY:  "OP_SET_PROPERTY",
+	OP_GET_SUPER:     "OP_GET_SUPER",
+	OP_EQUAL:         "OP_EQUAL",
+	OP_GREATER:       "OP_GREATER",
+	OP_LESS:          "OP_LESS",
+	OP_ADD:           "OP_ADD",
+	OP_SUBTRACT:      "OP_SUBTRACT",
+	OP_MULTIPLY:      "OP_MULTIPLY",
+	OP_DIVIDE:        "OP_DIVIDE",
+	OP_NOT:           "OP_NOT",
+	OP_NEGATE:        "OP_NEGATE",
+	OP_PRINT:         "OP_PRINT",
+	OP_JUMP:          "OP_JUMP",
+	OP_JUMP_IF_FALSE: "OP_JUMP_IF_FALSE",
+	OP_LOOP:          "OP_LOOP",
+	OP_CALL:          "OP_CALL",
+	OP_CLOSURE:       "OP_CLOSURE",
+	OP_CLOSE_UPVALUE: "OP_CLOSE_UPVALUE",
+	OP_RETURN:        "OP_RETURN",
+	OP_CLASS:         "OP_CLASS",
+	OP_INHERIT:       "OP_INHERIT",
+	OP_METHOD:        "OP_METHOD",
+}
+
+// String returns the mnemonic of the instruction.
+func (op OpCode) String() string {
+	if int(op) < len(opNames) {
+		return opNames[op]
+	}
+	return "OP_UNKNOWN"
+}
@@ -0,0 +1,214 @@
+package dot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-interpreter/internal/ast"
+	"github.com/go-interpreter/internal/printer"
+)
+
+// AST renders the syntax tree of statements, a box for every statement and
+// an ellipse for every expression. Edges to children whose role is not
+// obvious from their order, like the branches of an if, are labelled.
+// For loops show up as the while loops the parser turns them into.
+func AST(stmts []ast.Stmt) string {
+	tree := &treeGraph{}
+	tree.open("ast")
+	tree.line("ordering=out;")
+	tree.node("program", "Program", "shape=box", "style=rounded")
+	for _, stmt := range stmts {
+		tree.edge("program", tree.stmt(stmt), "")
+	}
+	return tree.close()
+}
+
+// treeGraph is a visitor implementation writing a node per visited node,
+// and the edges to its children. Visiting a node returns its id.
+type treeGraph struct {
+	graph
+	nodes int
+}
+
+// add writes a new node and returns its id.
+func (tree *treeGraph) add(label string, attributes ...string) string {
+	tree.nodes++
+	id := fmt.Sprintf("n%d", tree.nodes)
+	tree.node(id, label, attributes...)
+	return id
+}
+
+func (tree *treeGraph) statement(label string) string {
+	return tree.add(label, "shape=box")
+}
+
+func (tree *treeGraph) expression(label string) string {
+	return tree.add(label, "shape=ellipse")
+}
+
+func (tree *treeGraph) stmt(stmt ast.Stmt) string {
+	id, _ := stmt.Accept(tree)
+	return id.(string)
+}
+
+func (tree *treeGraph) expr(expression ast.Expr) string {
+	id, _ := expression.Accept(tree)
+	return id.(string)
+}
+
+func (tree *treeGraph) VisitBinary(node ast.Binary) (any, error) {
+	id := tree.expression("Binary " + node.Operator.Lexeme)
+	tree.edge(id, tree.expr(node.Left), "")
+	tree.edge(id, tree.expr(node.Right), "")
+	return id, nil
+}
+
+func (tree *treeGraph) VisitLogical(node ast.Logical) (any, error) {
+	id := tree.expression("Logical " + node.Operator.Lexeme)
+	tree.edge(id, tree.expr(node.Left), "")
+	tree.edge(id, tree.expr(node.Right), "")
+	return id, nil
+}
+
+func (tree *treeGraph) VisitGrouping(node ast.Grouping) (any, error) {
+	id := tree.expression("Grouping")
+	tree.edge(id, tree.expr(node.Expression), "")
+	return id, nil
+}
+
+func (tree *treeGraph) VisitLiteral(node ast.Literal) (any, error) {
+	return tree.expression("Literal " + (&printer.PrintSExpr{}).Print(node)), nil
+}
+
+func (tree *treeGraph) VisitUnary(node ast.Unary) (any, error) {
+	id := tree.expression("Unary " + node.Operator.Lexeme)
+	tree.edge(id, tree.expr(node.Right), "")
+	return id, nil
+}
+
+func (tree *treeGraph) VisitVariable(node ast.Variable) (any, error) {
+	return tree.expression("Variable " + node.Name.Lexeme), nil
+}
+
+func (tree *treeGraph) VisitAssign(node ast.Assign) (any, error) {
+	id := tree.expression("Assign " + node.Name.Lexeme)
+	tree.edge(id, tree.expr(node.Value), "")
+	return id, nil
+}
+
+func (tree *treeGraph) VisitCall(node ast.Call) (any, error) {
+	id := tree.expression("Call")
+	tree.edge(id, tree.expr(node.Callee), "callee")
+	for _, argument := range node.Arguments {
+		tree.edge(id, tree.expr(argument), "argument")
+	}
+	return id, nil
+}
+
+func (tree *treeGraph) VisitGet(node ast.Get) (any, error) {
+	id := tree.expression("Get " + node.Name.Lexeme)
+	tree.edge(id, tree.expr(node.Object), "")
+	return id, nil
+}
+
+func (tree *treeGraph) VisitSet(node ast.Set) (any, error) {
+	id := tree.expression("Set " + node.Name.Lexeme)
+	tree.edge(id, tree.expr(node.Object), "object")
+	tree.edge(id, tree.expr(node.Value), "value")
+	return id, nil
+}
+
+func (tree *treeGraph) VisitIncrement(node ast.Increment) (any, error) {
+	id := tree.expression("Increment " + node.Name.Lexeme)
+	tree.edge(id, tree.expr(node.Object), "")
+	return id, nil
+}
+
+func (tree *treeGraph) VisitThis(node ast.This) (any, error) {
+	return tree.expression("This"), nil
+}
+
+func (tree *treeGraph) VisitSuper(node ast.Super) (any, error) {
+	return tree.expression("Super " + node.Method.Lexeme), nil
+}
+
+func (tree *treeGraph) VisitExpressionStmt(node ast.ExpressionStmt) (any, error) {
+	id := tree.statement("Expression")
+	tree.edge(id, tree.expr(node.Expression), "")
+	return id, nil
+}
+
+func (tree *treeGraph) VisitPrintStmt(node ast.PrintStmt) (any, error) {
+	id := tree.statement("Print")
+	tree.edge(id, tree.expr(node.Expression), "")
+	return id, nil
+}
+
+func (tree *treeGraph) VisitVarStmt(node ast.VarStmt) (any, error) {
+	id := tree.statement("Var " + node.Name.Lexeme)
+	if node.Initializer != nil {
+		tree.edge(id, tree.expr(node.Initializer), "")
+	}
+	return id, nil
+}
+
+func (tree *treeGraph) VisitBlockStmt(node ast.Block) (any, error) {
+	id := tree.statement("Block")
+	for _, stmt := range node.Statements {
+		tree.edge(id, tree.stmt(stmt), "")
+	}
+	return id, nil
+}
+
+func (tree *treeGraph) VisitIfStmt(node ast.IfStmt) (any, error) {
+	id := tree.statement("If")
+	tree.edge(id, tree.expr(node.Condition), "condition")
+	tree.edge(id, tree.stmt(node.ThenBranch), "then")
+	if node.ElseBranch != nil {
+		tree.edge(id, tree.stmt(node.ElseBranch), "else")
+	}
+	return id, nil
+}
+
+func (tree *treeGraph) VisitWhileStmt(node ast.WhileStmt) (any, error) {
+	id := tree.statement("While")
+	tree.edge(id, tree.expr(node.Condition), "condition")
+	tree.edge(id, tree.stmt(node.Body), "body")
+	return id, nil
+}
+
+func (tree *treeGraph) VisitBreakStmt() (any, error) {
+	return tree.statement("Break"), nil
+}
+
+func (tree *treeGraph) VisitContinueStmt() (any, error) {
+	return tree.statement("Continue"), nil
+}
+
+func (tree *treeGraph) VisitFunctionStmt(node ast.FunctionStmt) (any, error) {
+	id := tree.statement(fmt.Sprintf("Function %s(%s)", node.Name.Lexeme, strings.Join(node.ParamNames(), ", ")))
+	for _, stmt := range node.Body {
+		tree.edge(id, tree.stmt(stmt), "")
+	}
+	return id, nil
+}
+
+func (tree *treeGraph) VisitReturnStmt(node ast.ReturnStmt) (any, error) {
+	id := tree.statement("Return")
+	if node.Value != nil {
+		tree.edge(id, tree.expr(node.Value), "")
+	}
+	return id, nil
+}
+
+func (tree *treeGraph) VisitClassStmt(node ast.ClassStmt) (any, error) {
+	label := "Class " + node.Name.Lexeme
+	if node.Superclass != nil {
+		label += " < " + node.Superclass.Name.Lexeme
+	}
+	id := tree.statement(label)
+	for _, method := range node.Methods {
+		tree.edge(id, tree.stmt(method), "")
+	}
+	return id, nil
+}
@@ -0,0 +1,292 @@
+package dot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-interpreter/internal/ast"
+	"github.com/go-interpreter/internal/printer"
+)
+
+// CFG renders the control-flow graph of the top level of a program and of
+// every function and method in it, each in a cluster of its own. The nodes
+// are basic blocks: statements that run one after the other, written as
+// S-expressions. A block ending in a condition has a "true" and a "false"
+// edge, but for 'while (true)', which has no "false" edge. Code that cannot
+// be reached is drawn dashed.
+func CFG(stmts []ast.Stmt) string {
+	flows := &flowGraph{}
+	flows.add("<script>", stmts)
+	for index := 0; index < len(flows.functions); index++ {
+		flows.functions[index].build()
+	}
+	flows.open("cfg")
+	for _, function := range flows.functions {
+		function.write(&flows.graph)
+	}
+	return flows.close()
+}
+
+// flowGraph holds the functions of a program, in the order they are found.
+type flowGraph struct {
+	graph
+	functions []*function
+}
+
+func (flows *flowGraph) add(name string, body []ast.Stmt) {
+	flows.functions = append(flows.functions, &function{
+		flows: flows,
+		index: len(flows.functions),
+		name:  name,
+		body:  body,
+	})
+}
+
+// block is a basic block and the edges leaving it.
+type block struct {
+	id    int
+	lines []string
+	edges []flowEdge
+}
+
+type flowEdge struct {
+	to    *block
+	label string
+}
+
+// loop is where break and continue jump to in a loop.
+type loop struct {
+	condition *block
+	exit      *block
+}
+
+// function is a visitor implementation splitting the body of a function
+// into basic blocks. Nested functions are added to the flow graph, to be
+// built after it.
+type function struct {
+	flows   *flowGraph
+	index   int
+	name    string
+	body    []ast.Stmt
+	blocks  []*block
+	entry   *block
+	exit    *block
+	current *block
+	loops   []loop
+}
+
+func (f *function) build() {
+	f.entry = f.newBlock()
+	f.exit = f.newBlock()
+	f.current = f.newBlock()
+	f.jump(f.entry, f.current, "")
+	f.stmts(f.body)
+	f.jump(f.current, f.exit, "")
+}
+
+func (f *function) newBlock() *block {
+	b := &block{id: len(f.blocks)}
+	f.blocks = append(f.blocks, b)
+	return b
+}
+
+func (f *function) jump(from *block, to *block, label string) {
+	from.edges = append(from.edges, flowEdge{to, label})
+}
+
+// startBlock ends the current block, unless it is still empty, so the next
+// statement is the first of a block that can be jumped to.
+func (f *function) startBlock() *block {
+	if len(f.current.lines) == 0 {
+		return f.current
+	}
+	next := f.newBlock()
+	f.jump(f.current, next, "")
+	f.current = next
+	return next
+}
+
+// unreachable starts a block for the code after a jump.
+func (f *function) unreachable() {
+	f.current = f.newBlock()
+}
+
+func (f *function) add(stmt ast.Stmt) {
+	f.current.lines = append(f.current.lines, (&printer.PrintSExpr{}).PrintStmt(stmt))
+}
+
+func (f *function) condition(keyword string, condition ast.Expr) {
+	f.current.lines = append(f.current.lines, keyword+" "+(&printer.PrintSExpr{}).Print(condition))
+}
+
+func (f *function) stmts(stmts []ast.Stmt) {
+	for _, stmt := range stmts {
+		stmt.Accept(f)
+	}
+}
+
+func (f *function) VisitExpressionStmt(node ast.ExpressionStmt) (any, error) {
+	f.add(node)
+	return nil, nil
+}
+
+func (f *function) VisitPrintStmt(node ast.PrintStmt) (any, error) {
+	f.add(node)
+	return nil, nil
+}
+
+func (f *function) VisitVarStmt(node ast.VarStmt) (any, error) {
+	f.add(node)
+	return nil, nil
+}
+
+func (f *function) VisitBlockStmt(node ast.Block) (any, error) {
+	f.stmts(node.Statements)
+	return nil, nil
+}
+
+func (f *function) VisitIfStmt(node ast.IfStmt) (any, error) {
+	f.condition("if", node.Condition)
+	branch := f.current
+	join := f.newBlock()
+	f.current = f.newBlock()
+	f.jump(branch, f.current, "true")
+	node.ThenBranch.Accept(f)
+	f.jump(f.current, join, "")
+	if node.ElseBranch != nil {
+		f.current = f.newBlock()
+		f.jump(branch, f.current, "false")
+		node.ElseBranch.Accept(f)
+		f.jump(f.current, join, "")
+	} else {
+		f.jump(branch, join, "false")
+	}
+	f.current = join
+	return nil, nil
+}
+
+func (f *function) VisitWhileStmt(node ast.WhileStmt) (any, error) {
+	condition := f.startBlock()
+	f.condition("while", node.Condition)
+	exit := f.newBlock()
+	f.current = f.newBlock()
+	f.jump(condition, f.current, "true")
+	// A loop on the literal true only ends with a break
+	if literal, ok := node.Condition.(ast.Literal); !ok || literal.Value != true {
+		f.jump(condition, exit, "false")
+	}
+	f.loops = append(f.loops, loop{condition, exit})
+	node.Body.Accept(f)
+	f.loops = f.loops[:len(f.loops)-1]
+	f.jump(f.current, condition, "")
+	f.current = exit
+	return nil, nil
+}
+
+func (f *function) VisitBreakStmt() (any, error) {
+	if len(f.loops) > 0 {
+		f.jump(f.current, f.loops[len(f.loops)-1].exit, "break")
+		f.unreachable()
+	}
+	return nil, nil
+}
+
+func (f *function) VisitContinueStmt() (any, error) {
+	if len(f.loops) > 0 {
+		f.jump(f.current, f.loops[len(f.loops)-1].condition, "continue")
+		f.unreachable()
+	}
+	return nil, nil
+}
+
+func (f *function) VisitFunctionStmt(node ast.FunctionStmt) (any, error) {
+	f.current.lines = append(f.current.lines, fmt.Sprintf("fun %s(%s)", node.Name.Lexeme, strings.Join(node.ParamNames(), ", ")))
+	f.flows.add(node.Name.Lexeme, node.Body)
+	return nil, nil
+}
+
+func (f *function) VisitReturnStmt(node ast.ReturnStmt) (any, error) {
+	f.add(node)
+	f.jump(f.current, f.exit, "")
+	f.unreachable()
+	return nil, nil
+}
+
+func (f *function) VisitClassStmt(node ast.ClassStmt) (any, error) {
+	f.current.lines = append(f.current.lines, "class "+node.Name.Lexeme)
+	for _, method := range node.Methods {
+		f.flows.add(node.Name.Lexeme+"."+method.Name.Lexeme, method.Body)
+	}
+	return nil, nil
+}
+
+// reachable finds the blocks that can be reached from the entry.
+func (f *function) reachable() map[*block]bool {
+	reached := map[*block]bool{f.entry: true}
+	pending := []*block{f.entry}
+	for len(pending) > 0 {
+		b := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		for _, edge := range b.edges {
+			if !reached[edge.to] {
+				reached[edge.to] = true
+				pending = append(pending, edge.to)
+			}
+		}
+	}
+	return reached
+}
+
+// target skips the empty blocks that only lead on to another one, like
+// the block after a loop at the end of a function.
+func (f *function) target(b *block) *block {
+	for b != f.entry && len(b.lines) == 0 && len(b.edges) == 1 && b.edges[0].label == "" {
+		b = b.edges[0].to
+	}
+	return b
+}
+
+// write writes the function as a cluster. Empty blocks that cannot be
+// reached, like those started after a return, are left out.
+func (f *function) write(g *graph) {
+	reached := f.reachable()
+	shown := func(b *block) bool {
+		return (reached[b] || len(b.lines) > 0) && f.target(b) == b
+	}
+	g.line("subgraph cluster_%d {", f.index)
+	g.indent++
+	g.line("label=%s;", quote(f.name))
+	for _, b := range f.blocks {
+		if !shown(b) {
+			continue
+		}
+		switch {
+		case b == f.entry:
+			g.node(f.id(b), "entry", "shape=oval")
+		case b == f.exit:
+			g.node(f.id(b), "exit", "shape=oval")
+		case len(b.lines) == 0:
+			g.node(f.id(b), "", "shape=point")
+		case !reached[b]:
+			g.node(f.id(b), strings.Join(b.lines, "\n")+"\n", "shape=box", "style=dashed")
+		default:
+			g.node(f.id(b), strings.Join(b.lines, "\n")+"\n", "shape=box")
+		}
+	}
+	for _, b := range f.blocks {
+		if !shown(b) {
+			continue
+		}
+		for _, edge := range b.edges {
+			if to := f.target(edge.to); shown(to) {
+				g.edge(f.id(b), f.id(to), edge.label)
+			}
+		}
+	}
+	g.indent--
+	g.line("}")
+}
+
+func (f *function) id(b *block) string {
+	return fmt.Sprintf("f%d_b%d", f.index, b.id)
+}
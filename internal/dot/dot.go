@@ -0,0 +1,59 @@
+// Package dot renders programs as Graphviz DOT graphs: the syntax tree the
+// parser builds, and the control-flow graph of every function. The output
+// is turned into an image with the dot tool, e.g. 'dot -Tsvg'.
+package dot
+
+import (
+	"fmt"
+	"strings"
+)
+
+// graph writes the statements of a DOT graph.
+type graph struct {
+	out    strings.Builder
+	indent int
+}
+
+func (g *graph) open(name string) {
+	fmt.Fprintf(&g.out, "digraph %s {\n", name)
+	g.line(`node [fontname="monospace"];`)
+	g.line(`edge [fontname="monospace"];`)
+}
+
+func (g *graph) close() string {
+	g.out.WriteString("}\n")
+	return g.out.String()
+}
+
+func (g *graph) line(format string, args ...any) {
+	g.out.WriteString(strings.Repeat("  ", g.indent+1))
+	fmt.Fprintf(&g.out, format, args...)
+	g.out.WriteByte('\n')
+}
+
+// node writes a node with a label and extra attributes, like "shape=box".
+func (g *graph) node(id string, label string, attributes ...string) {
+	g.line("%s [%s];", id, strings.Join(append([]string{"label=" + quote(label)}, attributes...), ", "))
+}
+
+// edge writes an edge, with a label unless it is empty.
+func (g *graph) edge(from string, to string, label string) {
+	if label == "" {
+		g.line("%s -> %s;", from, to)
+		return
+	}
+	g.line("%s -> %s [label=%s];", from, to, quote(label))
+}
+
+// quote makes text a DOT string. Lines are left-aligned, which is what
+// '\l' at their end does.
+func quote(text string) string {
+	text = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text)
+	if strings.Contains(text, "\n") {
+		text = strings.ReplaceAll(text, "\n", `\l`)
+		if !strings.HasSuffix(text, `\l`) {
+			text += `\l`
+		}
+	}
+	return `"` + text + `"`
+}
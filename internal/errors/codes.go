@@ -0,0 +1,415 @@
+package errors
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Code identifies a kind of error independently of the wording of its
+// message, so that it can be searched for and documented. Codes never
+// change meaning once released. They are grouped by the stage that
+// reports them: E00xx scanner, E01xx parser, E02xx resolver, E03xx
+// runtime, E04xx compiler and E09xx internal errors.
+type Code string
+
+// Scanner errors
+const (
+	UNEXPECTED_CHARACTER Code = "E0001"
+	UNTERMINATED_STRING  Code = "E0002"
+	INVALID_ESCAPE       Code = "E0003"
+)
+
+// Parser errors
+const (
+	EXPECTED_EXPRESSION       Code = "E0101"
+	MISSING_SEMICOLON         Code = "E0102"
+	MISSING_PARENTHESIS       Code = "E0103"
+	MISSING_BRACE             Code = "E0104"
+	EXPECTED_NAME             Code = "E0105"
+	MISSING_DOT               Code = "E0106"
+	INVALID_ASSIGNMENT_TARGET Code = "E0107"
+	TOO_MANY_PARAMETERS       Code = "E0108"
+	TOO_MANY_ARGUMENTS        Code = "E0109"
+	BREAK_OUTSIDE_LOOP        Code = "E0110"
+	CONTINUE_OUTSIDE_LOOP     Code = "E0111"
+)
+
+// Resolver errors
+const (
+	INHERIT_FROM_SELF        Code = "E0201"
+	TOP_LEVEL_RETURN         Code = "E0202"
+	RETURN_FROM_INITIALIZER  Code = "E0203"
+	SUPER_OUTSIDE_CLASS      Code = "E0204"
+	SUPER_WITHOUT_SUPERCLASS Code = "E0205"
+	THIS_OUTSIDE_CLASS       Code = "E0206"
+	READ_IN_OWN_INITIALIZER  Code = "E0207"
+	ALREADY_DECLARED         Code = "E0208"
+)
+
+// Runtime errors, shared by both backends
+const (
+	UNDEFINED_VARIABLE   Code = "E0301"
+	UNDEFINED_PROPERTY   Code = "E0302"
+	NOT_AN_INSTANCE      Code = "E0303"
+	INVALID_OPERAND      Code = "E0304"
+	NOT_CALLABLE         Code = "E0305"
+	WRONG_ARGUMENT_COUNT Code = "E0306"
+	SUPERCLASS_NOT_CLASS Code = "E0307"
+	STACK_OVERFLOW       Code = "E0308"
+	INTERRUPTED          Code = "E0309"
+	NATIVE_ERROR         Code = "E0310"
+)
+
+// Compiler errors, only reported by the bytecode backend
+const (
+	TOO_MANY_LOCALS    Code = "E0401"
+	TOO_MANY_UPVALUES  Code = "E0402"
+	TOO_MANY_CONSTANTS Code = "E0403"
+	JUMP_TOO_LONG      Code = "E0404"
+	LOOP_TOO_LONG      Code = "E0405"
+)
+
+// INTERNAL_ERROR is a bug in the interpreter rather than in the program.
+const INTERNAL_ERROR Code = "E0901"
+
+// Explanation documents an error code: what it means, a program that
+// causes it and the same program fixed.
+type Explanation struct {
+	Code        Code
+	Title       string
+	Description string
+	Wrong       string
+	Correct     string
+}
+
+// String formats the explanation for the terminal.
+func (explanation Explanation) String() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%s: %s\n\n%s\n", explanation.Code, explanation.Title, explanation.Description)
+	if explanation.Wrong != "" {
+		fmt.Fprintf(&builder, "\nWrong:\n\n%s", quote(explanation.Wrong))
+	}
+	if explanation.Correct != "" {
+		fmt.Fprintf(&builder, "\nCorrect:\n\n%s", quote(explanation.Correct))
+	}
+	return builder.String()
+}
+
+// quote indents a program so that it stands out from the prose around it.
+func quote(program string) string {
+	var builder strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(program, "\n"), "\n") {
+		builder.WriteString("    " + line + "\n")
+	}
+	return builder.String()
+}
+
+// Explain looks up the explanation of a code. Codes are matched case
+// insensitively, so "e0102" finds E0102.
+func Explain(code string) (Explanation, bool) {
+	explanation, ok := explanations[Code(strings.ToUpper(strings.TrimSpace(code)))]
+	return explanation, ok
+}
+
+// Codes returns every documented code, in order.
+func Codes() []Code {
+	codes := make([]Code, 0, len(explanations))
+	for code := range explanations {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+	return codes
+}
+
+var explanations = map[Code]Explanation{}
+
+func init() {
+	for _, explanation := range []Explanation{
+		{
+			Code:  UNEXPECTED_CHARACTER,
+			Title: "unexpected character",
+			Description: "The scanner found a character that is not part of the language, outside\n" +
+				"of a string or a comment.",
+			Wrong:   "var price = 10$;",
+			Correct: "var price = 10;",
+		},
+		{
+			Code:        UNTERMINATED_STRING,
+			Title:       "unterminated string",
+			Description: "A string was opened with '\"' but the file ended before it was closed.",
+			Wrong:       "print \"hello;",
+			Correct:     "print \"hello\";",
+		},
+		{
+			Code:  INVALID_ESCAPE,
+			Title: "invalid escape sequence",
+			Description: "A backslash in a string starts an escape sequence such as \\n, \\t, \\\" or\n" +
+				"\\\\. Any other character after the backslash is an error; write \\\\ for a\n" +
+				"backslash of its own.",
+			Wrong:   "print \"C:\\path\";",
+			Correct: "print \"C:\\\\path\";",
+		},
+		{
+			Code:  EXPECTED_EXPRESSION,
+			Title: "expected an expression",
+			Description: "The parser needed a value, such as a number, a string, a variable or a\n" +
+				"parenthesised expression, but found something else or the end of the input.",
+			Wrong:   "var total = 1 + ;",
+			Correct: "var total = 1 + 2;",
+		},
+		{
+			Code:  MISSING_SEMICOLON,
+			Title: "missing semicolon",
+			Description: "Every statement that is not a block ends with ';'. The error points just\n" +
+				"past the token the semicolon should follow.",
+			Wrong:   "var a = 1\nprint a;",
+			Correct: "var a = 1;\nprint a;",
+		},
+		{
+			Code:  MISSING_PARENTHESIS,
+			Title: "missing parenthesis",
+			Description: "A '(' or ')' is required here: around the condition of 'if' and 'while',\n" +
+				"around the clauses of 'for', around parameters and arguments, and to\n" +
+				"close a parenthesised expression.",
+			Wrong:   "if a > 1 {\n  print a;\n}",
+			Correct: "if (a > 1) {\n  print a;\n}",
+		},
+		{
+			Code:        MISSING_BRACE,
+			Title:       "missing brace",
+			Description: "Blocks, function bodies and class bodies are enclosed in '{' and '}'.",
+			Wrong:       "fun greet() {\n  print \"hi\";\n",
+			Correct:     "fun greet() {\n  print \"hi\";\n}",
+		},
+		{
+			Code:  EXPECTED_NAME,
+			Title: "expected a name",
+			Description: "Declarations of variables, functions, classes and parameters, and\n" +
+				"property accesses, need an identifier. Identifiers start with a letter\n" +
+				"or '_' and cannot be keywords.",
+			Wrong:   "var class = 1;",
+			Correct: "var kind = 1;",
+		},
+		{
+			Code:        MISSING_DOT,
+			Title:       "'super' without a method",
+			Description: "'super' can only be used to access a method of the superclass, as in\n'super.method'.",
+			Wrong:       "class B < A {\n  init() {\n    super();\n  }\n}",
+			Correct:     "class B < A {\n  init() {\n    super.init();\n  }\n}",
+		},
+		{
+			Code:        INVALID_ASSIGNMENT_TARGET,
+			Title:       "invalid assignment target",
+			Description: "Only variables and properties can be assigned to or incremented with\n'++'.",
+			Wrong:       "var a = 1;\na + 1 = 3;",
+			Correct:     "var a = 1;\na = 3 - 1;",
+		},
+		{
+			Code:        TOO_MANY_PARAMETERS,
+			Title:       "too many parameters",
+			Description: "A function can have at most 255 parameters. Pass a list or an instance\ninstead.",
+			Wrong:       "fun f(p0, p1, p2, ..., p255) {}",
+			Correct:     "class Options {}\nfun f(options) {}",
+		},
+		{
+			Code:        TOO_MANY_ARGUMENTS,
+			Title:       "too many arguments",
+			Description: "A call can pass at most 255 arguments.",
+			Wrong:       "f(a0, a1, a2, ..., a255);",
+			Correct:     "f(options);",
+		},
+		{
+			Code:        BREAK_OUTSIDE_LOOP,
+			Title:       "'break' outside of a loop",
+			Description: "'break' leaves the innermost loop, so it can only appear inside one.",
+			Wrong:       "if (done) break;",
+			Correct:     "while (true) {\n  if (done) break;\n}",
+		},
+		{
+			Code:        CONTINUE_OUTSIDE_LOOP,
+			Title:       "'continue' outside of a loop",
+			Description: "'continue' skips to the next iteration of the innermost loop, so it can\nonly appear inside one.",
+			Wrong:       "if (skip) continue;",
+			Correct:     "while (true) {\n  if (skip) continue;\n}",
+		},
+		{
+			Code:        INHERIT_FROM_SELF,
+			Title:       "class inherits from itself",
+			Description: "A class cannot name itself as its superclass.",
+			Wrong:       "class A < A {}",
+			Correct:     "class Base {}\nclass A < Base {}",
+		},
+		{
+			Code:        TOP_LEVEL_RETURN,
+			Title:       "'return' outside of a function",
+			Description: "'return' ends the function it is in, so top-level code cannot use it.",
+			Wrong:       "return 1;",
+			Correct:     "fun one() {\n  return 1;\n}",
+		},
+		{
+			Code:  RETURN_FROM_INITIALIZER,
+			Title: "value returned from an initializer",
+			Description: "'init' always returns the new instance. It may use a bare 'return' to\n" +
+				"stop early, but cannot return a value of its own.",
+			Wrong:   "class A {\n  init() {\n    return 1;\n  }\n}",
+			Correct: "class A {\n  init() {\n    return;\n  }\n}",
+		},
+		{
+			Code:        SUPER_OUTSIDE_CLASS,
+			Title:       "'super' outside of a class",
+			Description: "'super' refers to the superclass of the class a method belongs to, so it\ncan only be used in methods.",
+			Wrong:       "super.greet();",
+			Correct:     "class B < A {\n  greet() {\n    super.greet();\n  }\n}",
+		},
+		{
+			Code:        SUPER_WITHOUT_SUPERCLASS,
+			Title:       "'super' in a class without a superclass",
+			Description: "'super' can only be used in a class that inherits from another one.",
+			Wrong:       "class B {\n  greet() {\n    super.greet();\n  }\n}",
+			Correct:     "class B < A {\n  greet() {\n    super.greet();\n  }\n}",
+		},
+		{
+			Code:        THIS_OUTSIDE_CLASS,
+			Title:       "'this' outside of a class",
+			Description: "'this' refers to the instance a method was called on, so it can only be\nused in methods.",
+			Wrong:       "fun name() {\n  return this.name;\n}",
+			Correct:     "class Person {\n  name() {\n    return this.name;\n  }\n}",
+		},
+		{
+			Code:  READ_IN_OWN_INITIALIZER,
+			Title: "variable read in its own initializer",
+			Description: "A local variable does not exist until its initializer has run, so the\n" +
+				"initializer cannot use it.",
+			Wrong:   "{\n  var a = a + 1;\n}",
+			Correct: "{\n  var a = 1;\n  a = a + 1;\n}",
+		},
+		{
+			Code:  ALREADY_DECLARED,
+			Title: "variable declared twice in the same scope",
+			Description: "A local scope can only declare a name once. Assign to the variable\n" +
+				"instead, or declare it in a nested block.",
+			Wrong:   "{\n  var a = 1;\n  var a = 2;\n}",
+			Correct: "{\n  var a = 1;\n  a = 2;\n}",
+		},
+		{
+			Code:        UNDEFINED_VARIABLE,
+			Title:       "undefined variable",
+			Description: "A global variable was read or assigned before it was declared with 'var'.",
+			Wrong:       "count = 1;",
+			Correct:     "var count = 1;",
+		},
+		{
+			Code:  UNDEFINED_PROPERTY,
+			Title: "undefined property",
+			Description: "The instance has no field and its class no method with this name. Fields\n" +
+				"exist once they have been assigned.",
+			Wrong:   "class Point {}\nprint Point().x;",
+			Correct: "class Point {}\nvar p = Point();\np.x = 1;\nprint p.x;",
+		},
+		{
+			Code:        NOT_AN_INSTANCE,
+			Title:       "property of a value that is not an instance",
+			Description: "Only instances of classes have fields and methods.",
+			Wrong:       "var n = 1;\nprint n.size;",
+			Correct:     "class Box {}\nvar n = Box();\nn.size = 1;\nprint n.size;",
+		},
+		{
+			Code:  INVALID_OPERAND,
+			Title: "operand of the wrong type",
+			Description: "Arithmetic and comparison operators need numbers. '+' also joins two\n" +
+				"strings, but does not mix strings and numbers.",
+			Wrong:   "print \"total: \" - 1;",
+			Correct: "print \"total: \" + \"1\";",
+		},
+		{
+			Code:        NOT_CALLABLE,
+			Title:       "call of a value that is not callable",
+			Description: "Only functions, methods and classes can be called.",
+			Wrong:       "var greeting = \"hi\";\ngreeting();",
+			Correct:     "fun greeting() {\n  print \"hi\";\n}\ngreeting();",
+		},
+		{
+			Code:  WRONG_ARGUMENT_COUNT,
+			Title: "wrong number of arguments",
+			Description: "A function must be called with as many arguments as it has parameters,\n" +
+				"and a class with as many as its 'init' method takes.",
+			Wrong:   "fun add(a, b) {\n  return a + b;\n}\nadd(1);",
+			Correct: "fun add(a, b) {\n  return a + b;\n}\nadd(1, 2);",
+		},
+		{
+			Code:        SUPERCLASS_NOT_CLASS,
+			Title:       "superclass is not a class",
+			Description: "The name after '<' in a class declaration must refer to a class.",
+			Wrong:       "var Base = 1;\nclass A < Base {}",
+			Correct:     "class Base {}\nclass A < Base {}",
+		},
+		{
+			Code:  STACK_OVERFLOW,
+			Title: "stack overflow",
+			Description: "Too many calls were nested, usually because of a recursive function that\n" +
+				"never reaches its base case.",
+			Wrong:   "fun count(n) {\n  return count(n + 1);\n}\ncount(0);",
+			Correct: "fun count(n) {\n  if (n >= 10) return n;\n  return count(n + 1);\n}\ncount(0);",
+		},
+		{
+			Code:  INTERRUPTED,
+			Title: "interrupted",
+			Description: "The program was stopped before it finished, because the Go application\n" +
+				"running it cancelled it or its deadline passed. Loops and calls check for\n" +
+				"that, so even a program that never ends can be stopped.",
+		},
+		{
+			Code:  NATIVE_ERROR,
+			Title: "native code failed",
+			Description: "A function defined in Go by the application running the program, an\n" +
+				"object it handed to the program, or the output it gave the program failed.\n" +
+				"The message is that of the Go error, and the error points at the call, the\n" +
+				"property or the print statement.",
+		},
+		{
+			Code:        TOO_MANY_LOCALS,
+			Title:       "too many local variables",
+			Description: "The bytecode compiler supports at most 256 local variables in scope in a\nsingle function.",
+			Wrong:       "fun f() {\n  var v0; var v1; ... var v256;\n}",
+			Correct:     "fun f() {\n  var values = Values();\n}",
+		},
+		{
+			Code:        TOO_MANY_UPVALUES,
+			Title:       "too many closure variables",
+			Description: "A function compiled to bytecode can capture at most 256 variables of the\nfunctions around it.",
+			Wrong:       "fun outer() {\n  var v0; ... var v256;\n  fun inner() { print v0 + ... + v256; }\n}",
+			Correct:     "fun outer() {\n  var values = Values();\n  fun inner() { print values.sum(); }\n}",
+		},
+		{
+			Code:        TOO_MANY_CONSTANTS,
+			Title:       "too many constants",
+			Description: "A function compiled to bytecode can hold at most 256 distinct constants.\nSplit it into smaller functions.",
+			Wrong:       "fun f() {\n  print 0; print 1; ... print 256;\n}",
+			Correct:     "fun low() {\n  print 0; ... print 127;\n}\nfun high() {\n  print 128; ... print 256;\n}",
+		},
+		{
+			Code:        JUMP_TOO_LONG,
+			Title:       "too much code to jump over",
+			Description: "The branch of an 'if', or the operand of 'and' and 'or', compiled to more\nbytecode than a jump can cross. Move it into a function.",
+			Wrong:       "if (ready) {\n  // thousands of statements\n}",
+			Correct:     "fun start() {\n  // thousands of statements\n}\nif (ready) start();",
+		},
+		{
+			Code:        LOOP_TOO_LONG,
+			Title:       "loop body too large",
+			Description: "The body of a loop compiled to more bytecode than a jump can cross. Move\nit into a function.",
+			Wrong:       "while (running) {\n  // thousands of statements\n}",
+			Correct:     "fun step() {\n  // thousands of statements\n}\nwhile (running) step();",
+		},
+		{
+			Code:  INTERNAL_ERROR,
+			Title: "internal error",
+			Description: "The interpreter reached a state it should never be in. This is a bug in\n" +
+				"the interpreter rather than in the program; please report it together\n" +
+				"with the program that triggered it.",
+		},
+	} {
+		explanations[explanation.Code] = explanation
+	}
+}
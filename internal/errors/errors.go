@@ -8,24 +8,66 @@ import (
 type ExecutionErrorType string
 
 const (
-	RUNTIME_ERROR ExecutionErrorType = "Runtime Error"
-	PROGRAM_ERROR ExecutionErrorType = "Program Error"
-	PARSER_ERROR  ExecutionErrorType = "Syntax Error"
-	SCANNER_ERROR ExecutionErrorType = "Scanner Error"
+	RUNTIME_ERROR  ExecutionErrorType = "Runtime Error"
+	PROGRAM_ERROR  ExecutionErrorType = "Program Error"
+	PARSER_ERROR   ExecutionErrorType = "Syntax Error"
+	SCANNER_ERROR  ExecutionErrorType = "Scanner Error"
+	RESOLVER_ERROR ExecutionErrorType = "Resolution Error"
+	COMPILER_ERROR ExecutionErrorType = "Compile Error"
 )
 
 func (s ExecutionErrorType) String() string {
 	return string(s)
 }
 
+// ExecutionError is a diagnostic about the program. Code identifies the
+// kind of error, see Explain. Line and Column are
+// 1-based and point at the start of the offending token, Length is how
+// many characters of it to underline, and Where is its byte offset in
+// the source. Hint optionally tells the user how to fix the problem.
+// Runtime errors also carry the Trace of the calls that led to them,
+// innermost first.
 type ExecutionError struct {
 	Type    ExecutionErrorType
+	Code    Code
 	Line    int
+	Column  int
+	Length  int
 	Where   int
 	Message string
+	Hint    string
+	Trace   []Frame
+}
+
+// SCRIPT_FRAME names the outermost frame of a stack trace, the top-level code.
+const SCRIPT_FRAME = "<script>"
+
+// Frame is one entry of a stack trace: a function that was running when
+// the error happened, and the position it had reached. For every frame
+// but the innermost that is the call to the next function.
+type Frame struct {
+	Function string
+	File     string
+	Line     int
+	Column   int
+}
+
+func (frame Frame) String() string {
+	if frame.File == "" {
+		return fmt.Sprintf("at %s (%d:%d)", frame.Function, frame.Line, frame.Column)
+	}
+	return fmt.Sprintf("at %s (%s:%d:%d)", frame.Function, frame.File, frame.Line, frame.Column)
 }
 
 // Report to user where and why that thing went wrong
 func (err ExecutionError) Error() string {
-	return fmt.Sprintf("%s [line %d] at %v: %s", err.Type, err.Line, err.Where, err.Message)
+	return fmt.Sprintf("%s [line %d, column %d]: %s", err.title(), err.Line, err.Column, err.Message)
+}
+
+// title is the type of the error followed by its code, if it has one.
+func (err ExecutionError) title() string {
+	if err.Code == "" {
+		return err.Type.String()
+	}
+	return fmt.Sprintf("%s[%s]", err.Type, err.Code)
 }
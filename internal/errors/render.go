@@ -0,0 +1,131 @@
+package errors
+
+import (
+	goerrors "errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ANSI escape codes used when the output is a terminal.
+const (
+	RED   = "\033[1;31m"
+	BLUE  = "\033[1;34m"
+	BOLD  = "\033[1m"
+	RESET = "\033[0m"
+)
+
+// MAX_TRACE_FRAMES is how many frames are shown at either end of a long stack trace.
+const MAX_TRACE_FRAMES = 10
+
+// Renderer formats diagnostics the way modern compilers do:
+//
+//	Syntax Error[E0102]: Expect ';' after value.
+//	 --> main.lox:1:8
+//	  |
+//	1 | print 1
+//	  |        ^
+//	  = hint: ...
+//
+// Source is the text the line numbers of the diagnostics refer to, and File
+// is the name it is shown under.
+type Renderer struct {
+	File   string
+	Source string
+	Color  bool
+}
+
+// Render formats a single diagnostic, ending with a newline.
+func (renderer Renderer) Render(err ExecutionError) string {
+	var builder strings.Builder
+	gutter := strings.Repeat(" ", len(strconv.Itoa(err.Line)))
+	bar := renderer.paint(BLUE, "|")
+
+	fmt.Fprintf(&builder, "%s%s\n", renderer.paint(RED, err.title()), renderer.paint(BOLD, ": "+err.Message))
+	if err.Line > 0 {
+		fmt.Fprintf(&builder, "%s%s %s:%d:%d\n", gutter, renderer.paint(BLUE, "-->"), renderer.File, err.Line, err.Column)
+	} else {
+		// Errors that are not about a particular place in the program
+		fmt.Fprintf(&builder, "%s%s %s\n", gutter, renderer.paint(BLUE, "-->"), renderer.File)
+	}
+	if line, ok := renderer.line(err.Line); ok {
+		fmt.Fprintf(&builder, "%s %s\n", gutter, bar)
+		fmt.Fprintf(&builder, "%s %s %s\n", renderer.paint(BLUE, strconv.Itoa(err.Line)), bar, line)
+		if err.Column > 0 {
+			fmt.Fprintf(&builder, "%s %s %s%s\n", gutter, bar, indent(line, err.Column),
+				renderer.paint(RED, strings.Repeat("^", underline(line, err.Column, err.Length))))
+		}
+	}
+	if err.Hint != "" {
+		fmt.Fprintf(&builder, "%s %s hint: %s\n", gutter, renderer.paint(BLUE, "="), err.Hint)
+	}
+	// A trace of the top-level script alone would only repeat the position
+	if len(err.Trace) > 1 {
+		builder.WriteString("stack trace:\n")
+		frames, skipped := err.Trace, 0
+		// Runaway recursion would bury the interesting frames at either end
+		if len(frames) > 2*MAX_TRACE_FRAMES {
+			skipped = len(frames) - 2*MAX_TRACE_FRAMES
+			frames = append(frames[:MAX_TRACE_FRAMES:MAX_TRACE_FRAMES], frames[len(frames)-MAX_TRACE_FRAMES:]...)
+		}
+		for index, frame := range frames {
+			if skipped > 0 && index == MAX_TRACE_FRAMES {
+				fmt.Fprintf(&builder, "  ... %d more frames ...\n", skipped)
+			}
+			fmt.Fprintf(&builder, "  %s\n", frame)
+		}
+	}
+	return builder.String()
+}
+
+// line returns the 1-based line number of the source.
+func (renderer Renderer) line(number int) (string, bool) {
+	lines := strings.Split(renderer.Source, "\n")
+	if number < 1 || number > len(lines) {
+		return "", false
+	}
+	return strings.TrimRight(lines[number-1], "\r"), true
+}
+
+func (renderer Renderer) paint(color string, text string) string {
+	if !renderer.Color {
+		return text
+	}
+	return color + text + RESET
+}
+
+// indent lines the caret up with the given 1-based column. Tabs are kept
+// so that the caret ends up in the same place however wide they are shown.
+func indent(line string, column int) string {
+	var builder strings.Builder
+	for i, char := range []rune(line) {
+		if i >= column-1 {
+			break
+		}
+		if char == '\t' {
+			builder.WriteRune('\t')
+		} else {
+			builder.WriteRune(' ')
+		}
+	}
+	return builder.String()
+}
+
+// underline returns how many carets to draw: the length of the token,
+// but at least one and never past the end of the line.
+func underline(line string, column int, length int) int {
+	rest := utf8.RuneCountInString(line) - (column - 1)
+	if length > rest {
+		length = rest
+	}
+	return max(length, 1)
+}
+
+// AsExecutionError finds the ExecutionError in err, which may have been
+// wrapped on its way up.
+func AsExecutionError(err error) (ExecutionError, bool) {
+	var executionError ExecutionError
+	ok := goerrors.As(err, &executionError)
+	return executionError, ok
+}
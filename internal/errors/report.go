@@ -0,0 +1,281 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format selects how diagnostics are written out.
+type Format string
+
+const (
+	TEXT_FORMAT  Format = "text"
+	JSON_FORMAT  Format = "json"
+	SARIF_FORMAT Format = "sarif"
+)
+
+// SEVERITY is the severity of every diagnostic; there are no warnings yet.
+const SEVERITY = "error"
+
+// Reporter receives the diagnostics of a run one at a time. Flush must be
+// called once the run is over, since some formats can only be written out
+// as a whole.
+type Reporter interface {
+	Report(err ExecutionError)
+	Flush() error
+}
+
+// NewReporter creates a reporter writing diagnostics about file in the
+// given format to out. source is only needed for the text format, which
+// quotes it.
+func NewReporter(format Format, out io.Writer, file string, source string, color bool) Reporter {
+	switch format {
+	case JSON_FORMAT:
+		return &jsonReporter{out: out, file: file}
+	case SARIF_FORMAT:
+		return &sarifReporter{out: out, file: file}
+	default:
+		return &textReporter{out: out, renderer: Renderer{File: file, Source: source, Color: color}}
+	}
+}
+
+// ruleIDs names the rule broken by errors that have no Code, for tools
+// that group diagnostics by rule.
+var ruleIDs = map[ExecutionErrorType]string{
+	RUNTIME_ERROR:  "runtime-error",
+	PROGRAM_ERROR:  "program-error",
+	PARSER_ERROR:   "syntax-error",
+	SCANNER_ERROR:  "scanner-error",
+	RESOLVER_ERROR: "resolution-error",
+	COMPILER_ERROR: "compile-error",
+}
+
+// Rule returns the ID of the rule the error breaks: its code, or the
+// kind of error when it has none.
+func (err ExecutionError) Rule() string {
+	if err.Code != "" {
+		return string(err.Code)
+	}
+	return ruleIDs[err.Type]
+}
+
+// end returns the 1-based line and column just past the underlined part
+// of the error.
+func (err ExecutionError) end() (int, int) {
+	return err.Line, err.Column + max(err.Length, 1)
+}
+
+// textReporter writes diagnostics for people, see Renderer.
+type textReporter struct {
+	out      io.Writer
+	renderer Renderer
+}
+
+func (reporter *textReporter) Report(err ExecutionError) {
+	fmt.Fprint(reporter.out, reporter.renderer.Render(err))
+}
+
+func (reporter *textReporter) Flush() error {
+	return nil
+}
+
+type jsonPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type jsonRange struct {
+	Start jsonPosition `json:"start"`
+	End   jsonPosition `json:"end"`
+}
+
+type jsonFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+}
+
+type jsonDiagnostic struct {
+	Rule     string      `json:"rule"`
+	Severity string      `json:"severity"`
+	File     string      `json:"file"`
+	Range    jsonRange   `json:"range"`
+	Message  string      `json:"message"`
+	Hint     string      `json:"hint,omitempty"`
+	Trace    []jsonFrame `json:"trace,omitempty"`
+}
+
+// jsonReporter writes every diagnostic as soon as it comes, as one JSON
+// object per line.
+type jsonReporter struct {
+	out  io.Writer
+	file string
+	err  error
+}
+
+func (reporter *jsonReporter) Report(err ExecutionError) {
+	endLine, endColumn := err.end()
+	diagnostic := jsonDiagnostic{
+		Rule:     err.Rule(),
+		Severity: SEVERITY,
+		File:     reporter.file,
+		Range: jsonRange{
+			Start: jsonPosition{Line: err.Line, Column: err.Column},
+			End:   jsonPosition{Line: endLine, Column: endColumn},
+		},
+		Message: err.Message,
+		Hint:    err.Hint,
+	}
+	for _, frame := range err.Trace {
+		diagnostic.Trace = append(diagnostic.Trace, jsonFrame(frame))
+	}
+	encoder := json.NewEncoder(reporter.out)
+	encoder.SetEscapeHTML(false)
+	if encodeErr := encoder.Encode(diagnostic); encodeErr != nil && reporter.err == nil {
+		reporter.err = encodeErr
+	}
+}
+
+func (reporter *jsonReporter) Flush() error {
+	return reporter.err
+}
+
+// SARIF_SCHEMA and SARIF_VERSION identify the version of SARIF written by
+// the sarif format.
+const (
+	SARIF_SCHEMA  = "https://json.schemastore.org/sarif-2.1.0.json"
+	SARIF_VERSION = "2.1.0"
+	TOOL_NAME     = "go-interpreter"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+// sarifReporter collects the diagnostics and writes them as a single
+// SARIF log when flushed.
+type sarifReporter struct {
+	out     io.Writer
+	file    string
+	rules   []sarifRule
+	results []sarifResult
+}
+
+func (reporter *sarifReporter) Report(err ExecutionError) {
+	reporter.addRule(err)
+	message := err.Message
+	if err.Hint != "" {
+		message = fmt.Sprintf("%s (hint: %s)", message, err.Hint)
+	}
+	location := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: reporter.file}}
+	// Errors that are not about a particular place only point at the file
+	if err.Line > 0 {
+		endLine, endColumn := err.end()
+		location.Region = &sarifRegion{
+			StartLine:   err.Line,
+			StartColumn: max(err.Column, 1),
+			EndLine:     endLine,
+			EndColumn:   max(endColumn, 2),
+		}
+	}
+	reporter.results = append(reporter.results, sarifResult{
+		RuleID:    err.Rule(),
+		Level:     SEVERITY,
+		Message:   sarifMessage{Text: message},
+		Locations: []sarifLocation{{PhysicalLocation: location}},
+	})
+}
+
+// addRule describes the rule of the error in the log, once per rule.
+func (reporter *sarifReporter) addRule(err ExecutionError) {
+	for _, rule := range reporter.rules {
+		if rule.ID == err.Rule() {
+			return
+		}
+	}
+	description := strings.ToLower(err.Type.String())
+	if explanation, ok := Explain(string(err.Code)); ok {
+		description = explanation.Title
+	}
+	reporter.rules = append(reporter.rules, sarifRule{
+		ID:               err.Rule(),
+		ShortDescription: sarifMessage{Text: description},
+	})
+}
+
+func (reporter *sarifReporter) Flush() error {
+	log := sarifLog{
+		Schema:  SARIF_SCHEMA,
+		Version: SARIF_VERSION,
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: sarifDriver{Name: TOOL_NAME, Rules: reporter.rules}},
+			Results: reporter.results,
+		}},
+	}
+	// An empty run still needs its lists, so that readers do not choke on null
+	if log.Runs[0].Results == nil {
+		log.Runs[0].Results = []sarifResult{}
+	}
+	if log.Runs[0].Tool.Driver.Rules == nil {
+		log.Runs[0].Tool.Driver.Rules = []sarifRule{}
+	}
+	encoder := json.NewEncoder(reporter.out)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
@@ -0,0 +1,548 @@
+// Package formatter prints programs back as source in a canonical layout:
+// one statement per line, tab indentation, braces on the line of the
+// statement they belong to and single spaces around binary operators.
+package formatter
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/go-interpreter/internal/ast"
+	"github.com/go-interpreter/internal/errors"
+	"github.com/go-interpreter/internal/scanner"
+	"github.com/go-interpreter/internal/token"
+
+	parser "github.com/go-interpreter/internal/parser"
+)
+
+// INDENT is one level of indentation.
+const INDENT = "\t"
+
+// Format parses source and prints it back in canonical layout, comments
+// included. Programs with syntax errors are not formatted; their
+// diagnostics are returned instead.
+func Format(source string) (string, []errors.ExecutionError) {
+	tokenScanner := scanner.NewTokenScanner(source)
+	tokens, scanErrors := tokenScanner.ScanTokens()
+	p := parser.NewParser(tokens)
+	stmts, parseErrors := p.Parse()
+	if diagnostics := append(scanErrors, parseErrors...); len(diagnostics) > 0 {
+		return "", diagnostics
+	}
+	formatter := NewFormatter(tokens, tokenScanner.Comments)
+	return formatter.Format(stmts), nil
+}
+
+// Formatter prints statements as source. It walks the tokens the
+// statements were parsed from alongside the tree, which is how it knows
+// where the comments go, how literals were spelled and which loops were
+// written with 'for' before the parser desugared them into 'while'.
+// current is the next of those tokens to be printed, and comment the next
+// comment. line is the current output line, not yet indented, and
+// lastLine the source line of whatever was printed last.
+type Formatter struct {
+	tokens   []token.Token
+	comments []token.Token
+	current  int
+	comment  int
+	out      strings.Builder
+	line     strings.Builder
+	indent   int
+	lastLine int
+	// blockStart is set at the start of the file and of every block,
+	// where blank lines of the source are not kept.
+	blockStart bool
+}
+
+// NewFormatter creates a formatter for statements parsed from tokens,
+// with the comments the scanner found in between.
+func NewFormatter(tokens []token.Token, comments []token.Token) *Formatter {
+	return &Formatter{tokens: tokens, comments: comments}
+}
+
+// Format prints the statements, followed by any comments left at the end
+// of the source.
+func (f *Formatter) Format(stmts []ast.Stmt) string {
+	f.blockStart = true
+	for _, stmt := range stmts {
+		f.statement(stmt)
+	}
+	f.leadingComments(len(f.tokens))
+	return f.out.String()
+}
+
+// statement prints a statement on lines of its own, after the comments
+// that come before it.
+func (f *Formatter) statement(stmt ast.Stmt) {
+	f.leadingComments(f.current)
+	f.separate(f.peek().Line)
+	_, _ = stmt.Accept(f)
+}
+
+// body prints the body of a function, loop or conditional. A block stays
+// on the line of the statement it belongs to and is left open after its
+// closing brace, which body reports; anything else goes indented on the
+// next line.
+func (f *Formatter) body(stmt ast.Stmt) bool {
+	if block, ok := stmt.(ast.Block); ok && f.peekType() == token.LEFT_BRACE {
+		f.write(" ")
+		f.block(block.Statements)
+		return true
+	}
+	f.newline()
+	f.indent++
+	f.statement(stmt)
+	f.indent--
+	return false
+}
+
+// block prints braces around statements, leaving the line open after the
+// closing one. Empty blocks stay on one line.
+func (f *Formatter) block(stmts []ast.Stmt) {
+	f.expect(token.LEFT_BRACE)
+	if len(stmts) == 0 && !f.commentsBefore(f.current) {
+		f.expect(token.RIGHT_BRACE)
+		f.write("{}")
+		return
+	}
+	f.write("{")
+	f.newline()
+	f.indent++
+	f.blockStart = true
+	for _, stmt := range stmts {
+		f.statement(stmt)
+	}
+	f.leadingComments(f.current)
+	f.indent--
+	f.expect(token.RIGHT_BRACE)
+	f.write("}")
+}
+
+func (f *Formatter) VisitExpressionStmt(node ast.ExpressionStmt) (any, error) {
+	f.write(f.expression(node.Expression))
+	f.expect(token.SEMICOLON)
+	f.write(";")
+	f.newline()
+	return nil, nil
+}
+
+func (f *Formatter) VisitPrintStmt(node ast.PrintStmt) (any, error) {
+	f.expect(token.PRINT)
+	f.write("print " + f.expression(node.Expression))
+	f.expect(token.SEMICOLON)
+	f.write(";")
+	f.newline()
+	return nil, nil
+}
+
+func (f *Formatter) VisitVarStmt(node ast.VarStmt) (any, error) {
+	f.write(f.declaration(node))
+	f.newline()
+	return nil, nil
+}
+
+// declaration prints a variable declaration without ending the line, for
+// it may be the initializer of a for loop.
+func (f *Formatter) declaration(node ast.VarStmt) string {
+	f.expect(token.VAR)
+	f.expect(token.IDENTIFIER)
+	text := "var " + node.Name.Lexeme
+	if node.Initializer != nil {
+		f.expect(token.EQUAL)
+		text += " = " + f.expression(node.Initializer)
+	}
+	f.expect(token.SEMICOLON)
+	return text + ";"
+}
+
+func (f *Formatter) VisitBlockStmt(node ast.Block) (any, error) {
+	if f.peekType() == token.FOR {
+		return nil, f.forLoop(node)
+	}
+	f.block(node.Statements)
+	f.newline()
+	return nil, nil
+}
+
+func (f *Formatter) VisitIfStmt(node ast.IfStmt) (any, error) {
+	f.expect(token.IF)
+	f.expect(token.LEFT_PAREN)
+	f.write("if (" + f.expression(node.Condition))
+	f.expect(token.RIGHT_PAREN)
+	f.write(")")
+	open := f.body(node.ThenBranch)
+	if node.ElseBranch == nil {
+		if open {
+			f.newline()
+		}
+		return nil, nil
+	}
+	if open {
+		f.write(" ")
+	}
+	f.expect(token.ELSE)
+	f.write("else")
+	// 'else if' chains stay flat
+	if _, ok := node.ElseBranch.(ast.IfStmt); ok && f.peekType() == token.IF {
+		f.write(" ")
+		return node.ElseBranch.Accept(f)
+	}
+	if f.body(node.ElseBranch) {
+		f.newline()
+	}
+	return nil, nil
+}
+
+func (f *Formatter) VisitWhileStmt(node ast.WhileStmt) (any, error) {
+	if f.peekType() == token.FOR {
+		return nil, f.forLoop(node)
+	}
+	f.expect(token.WHILE)
+	f.expect(token.LEFT_PAREN)
+	f.write("while (" + f.expression(node.Condition))
+	f.expect(token.RIGHT_PAREN)
+	f.write(")")
+	if f.body(node.Body) {
+		f.newline()
+	}
+	return nil, nil
+}
+
+// forLoop prints a loop the parser desugared from 'for' back as one:
+//
+//	{ initializer; while (condition) { body; increment; } }
+//
+// The block around it is only there with an initializer, the block in it
+// only with an increment, and a missing condition became 'true'. Which of
+// the clauses were written is read off the tokens.
+func (f *Formatter) forLoop(stmt ast.Stmt) error {
+	f.expect(token.FOR)
+	f.expect(token.LEFT_PAREN)
+	header := "for ("
+	loop := stmt
+	if f.peekType() == token.SEMICOLON {
+		f.expect(token.SEMICOLON)
+		header += ";"
+	} else {
+		block := stmt.(ast.Block)
+		loop = block.Statements[1]
+		switch initializer := block.Statements[0].(type) {
+		case ast.VarStmt:
+			header += f.declaration(initializer)
+		case ast.ExpressionStmt:
+			header += f.expression(initializer.Expression) + ";"
+			f.expect(token.SEMICOLON)
+		}
+	}
+	while := loop.(ast.WhileStmt)
+	if f.peekType() != token.SEMICOLON {
+		header += " " + f.expression(while.Condition)
+	}
+	f.expect(token.SEMICOLON)
+	header += ";"
+	body := while.Body
+	if f.peekType() != token.RIGHT_PAREN {
+		block := body.(ast.Block)
+		body = block.Statements[0]
+		header += " " + f.expression(block.Statements[1].(ast.ExpressionStmt).Expression)
+		// Older scripts terminate the increment with a ';' as well
+		if f.peekType() == token.SEMICOLON {
+			f.expect(token.SEMICOLON)
+		}
+	}
+	f.expect(token.RIGHT_PAREN)
+	f.write(header + ")")
+	if f.body(body) {
+		f.newline()
+	}
+	return nil
+}
+
+func (f *Formatter) VisitBreakStmt() (any, error) {
+	f.expect(token.BREAK)
+	f.expect(token.SEMICOLON)
+	f.write("break;")
+	f.newline()
+	return nil, nil
+}
+
+func (f *Formatter) VisitContinueStmt() (any, error) {
+	f.expect(token.CONTINUE)
+	f.expect(token.SEMICOLON)
+	f.write("continue;")
+	f.newline()
+	return nil, nil
+}
+
+func (f *Formatter) VisitFunctionStmt(node ast.FunctionStmt) (any, error) {
+	f.expect(token.FUN)
+	f.write("fun ")
+	f.function(node)
+	return nil, nil
+}
+
+// function prints the name, parameters and body of a function or method.
+func (f *Formatter) function(node ast.FunctionStmt) {
+	f.expect(token.IDENTIFIER)
+	f.expect(token.LEFT_PAREN)
+	params := make([]string, 0, len(node.Params))
+	for index, param := range node.Params {
+		if index > 0 {
+			f.expect(token.COMMA)
+		}
+		f.expect(token.IDENTIFIER)
+		params = append(params, param.Lexeme)
+	}
+	f.expect(token.RIGHT_PAREN)
+	f.write(node.Name.Lexeme + "(" + strings.Join(params, ", ") + ") ")
+	f.block(node.Body)
+	f.newline()
+}
+
+func (f *Formatter) VisitReturnStmt(node ast.ReturnStmt) (any, error) {
+	f.expect(token.RETURN)
+	f.write("return")
+	if node.Value != nil {
+		f.write(" " + f.expression(node.Value))
+	}
+	f.expect(token.SEMICOLON)
+	f.write(";")
+	f.newline()
+	return nil, nil
+}
+
+func (f *Formatter) VisitClassStmt(node ast.ClassStmt) (any, error) {
+	f.expect(token.CLASS)
+	f.expect(token.IDENTIFIER)
+	f.write("class " + node.Name.Lexeme)
+	if node.Superclass != nil {
+		f.expect(token.LESS)
+		f.expect(token.IDENTIFIER)
+		f.write(" < " + node.Superclass.Name.Lexeme)
+	}
+	f.expect(token.LEFT_BRACE)
+	if len(node.Methods) == 0 && !f.commentsBefore(f.current) {
+		f.expect(token.RIGHT_BRACE)
+		f.write(" {}")
+		f.newline()
+		return nil, nil
+	}
+	f.write(" {")
+	f.newline()
+	f.indent++
+	f.blockStart = true
+	for _, method := range node.Methods {
+		f.leadingComments(f.current)
+		f.separate(f.peek().Line)
+		f.function(method)
+	}
+	f.leadingComments(f.current)
+	f.indent--
+	f.expect(token.RIGHT_BRACE)
+	f.write("}")
+	f.newline()
+	return nil, nil
+}
+
+// expression prints an expression on a single line.
+func (f *Formatter) expression(expr ast.Expr) string {
+	text, _ := expr.Accept(f)
+	return text.(string)
+}
+
+func (f *Formatter) VisitBinary(node ast.Binary) (any, error) {
+	left := f.expression(node.Left)
+	f.expect(node.Operator.Type)
+	right := f.expression(node.Right)
+	return left + " " + node.Operator.Lexeme + " " + right, nil
+}
+
+func (f *Formatter) VisitGrouping(node ast.Grouping) (any, error) {
+	f.expect(token.LEFT_PAREN)
+	expr := f.expression(node.Expression)
+	f.expect(token.RIGHT_PAREN)
+	return "(" + expr + ")", nil
+}
+
+// VisitLiteral prints a literal the way it was written, so that numbers
+// and escape sequences keep their spelling.
+func (f *Formatter) VisitLiteral(node ast.Literal) (any, error) {
+	var tokenType token.TokenType
+	var text string
+	switch value := node.Value.(type) {
+	case nil:
+		tokenType, text = token.NIL, "nil"
+	case bool:
+		tokenType, text = token.FALSE, "false"
+		if value {
+			tokenType, text = token.TRUE, "true"
+		}
+	case float64:
+		tokenType, text = token.NUMBER, strconv.FormatFloat(value, 'f', -1, 64)
+	case string:
+		tokenType, text = token.STRING, strconv.Quote(value)
+	}
+	if tok, ok := f.expect(tokenType); ok {
+		return tok.Lexeme, nil
+	}
+	return text, nil
+}
+
+func (f *Formatter) VisitUnary(node ast.Unary) (any, error) {
+	f.expect(node.Operator.Type)
+	right := f.expression(node.Right)
+	// '- -a' must not turn into '--a'
+	if node.Operator.Type == token.MINUS && strings.HasPrefix(right, "-") {
+		return node.Operator.Lexeme + " " + right, nil
+	}
+	return node.Operator.Lexeme + right, nil
+}
+
+func (f *Formatter) VisitVariable(node ast.Variable) (any, error) {
+	f.expect(token.IDENTIFIER)
+	return node.Name.Lexeme, nil
+}
+
+func (f *Formatter) VisitAssign(node ast.Assign) (any, error) {
+	f.expect(token.IDENTIFIER)
+	if isIncrement(node.Value) {
+		f.expect(token.INC)
+		return node.Name.Lexeme + "++", nil
+	}
+	f.expect(token.EQUAL)
+	return node.Name.Lexeme + " = " + f.expression(node.Value), nil
+}
+
+func (f *Formatter) VisitLogical(node ast.Logical) (any, error) {
+	return f.VisitBinary(ast.Binary(node))
+}
+
+func (f *Formatter) VisitCall(node ast.Call) (any, error) {
+	callee := f.expression(node.Callee)
+	f.expect(token.LEFT_PAREN)
+	arguments := make([]string, 0, len(node.Arguments))
+	for index, argument := range node.Arguments {
+		if index > 0 {
+			f.expect(token.COMMA)
+		}
+		arguments = append(arguments, f.expression(argument))
+	}
+	f.expect(token.RIGHT_PAREN)
+	return callee + "(" + strings.Join(arguments, ", ") + ")", nil
+}
+
+func (f *Formatter) VisitGet(node ast.Get) (any, error) {
+	object := f.expression(node.Object)
+	f.expect(token.DOT)
+	f.expect(token.IDENTIFIER)
+	return object + "." + node.Name.Lexeme, nil
+}
+
+func (f *Formatter) VisitSet(node ast.Set) (any, error) {
+	object := f.expression(node.Object)
+	f.expect(token.DOT)
+	f.expect(token.IDENTIFIER)
+	f.expect(token.EQUAL)
+	return object + "." + node.Name.Lexeme + " = " + f.expression(node.Value), nil
+}
+
+func (f *Formatter) VisitIncrement(node ast.Increment) (any, error) {
+	object := f.expression(node.Object)
+	f.expect(token.DOT)
+	f.expect(token.IDENTIFIER)
+	f.expect(token.INC)
+	return object + "." + node.Name.Lexeme + "++", nil
+}
+
+func (f *Formatter) VisitThis(node ast.This) (any, error) {
+	f.expect(token.THIS)
+	return "this", nil
+}
+
+func (f *Formatter) VisitSuper(node ast.Super) (any, error) {
+	f.expect(token.SUPER)
+	f.expect(token.DOT)
+	f.expect(token.IDENTIFIER)
+	return "super." + node.Method.Lexeme, nil
+}
+
+// isIncrement reports whether the value assigned is what the parser
+// desugars 'a++' into, 'a + 1' with the '++' token as the operator.
+func isIncrement(value ast.Expr) bool {
+	binary, ok := value.(ast.Binary)
+	return ok && binary.Operator.Type == token.INC
+}
+
+// peek returns the next token to be printed.
+func (f *Formatter) peek() token.Token {
+	if f.current >= len(f.tokens) {
+		return token.Token{Type: token.EOF}
+	}
+	return f.tokens[f.current]
+}
+
+func (f *Formatter) peekType() token.TokenType {
+	return f.peek().Type
+}
+
+// expect moves past the next token when it is of the given type, which it
+// is whenever the tree was parsed from these tokens.
+func (f *Formatter) expect(tokenType token.TokenType) (token.Token, bool) {
+	tok := f.peek()
+	if tok.Type != tokenType || tok.Type == token.EOF {
+		return token.Token{}, false
+	}
+	f.current++
+	// Strings may span lines; what matters is where they end
+	f.lastLine = tok.Line + strings.Count(tok.Lexeme, "\n")
+	return tok, true
+}
+
+// commentsBefore reports whether there are comments left before the token
+// at the given index.
+func (f *Formatter) commentsBefore(index int) bool {
+	return f.comment < len(f.comments) && (index >= len(f.tokens) || f.comments[f.comment].Char < f.tokens[index].Char)
+}
+
+// leadingComments prints, each on a line of its own, the comments before
+// the token at the given index.
+func (f *Formatter) leadingComments(index int) {
+	for f.commentsBefore(index) {
+		comment := f.comments[f.comment]
+		f.comment++
+		f.separate(comment.Line)
+		f.write(comment.Lexeme)
+		f.lastLine = comment.Line
+		f.newline()
+	}
+}
+
+// separate keeps a blank line of the source in front of whatever starts
+// on the given line, unless it opens a block. Several blank lines in a
+// row are kept as one.
+func (f *Formatter) separate(line int) {
+	if !f.blockStart && f.lastLine > 0 && line > f.lastLine+1 {
+		f.out.WriteString("\n")
+	}
+	f.blockStart = false
+}
+
+// write adds text to the current line.
+func (f *Formatter) write(text string) {
+	f.line.WriteString(text)
+}
+
+// newline ends the current line, with the comment that followed the last
+// token printed on the same source line, if there was one.
+func (f *Formatter) newline() {
+	if f.comment < len(f.comments) && f.comments[f.comment].Line == f.lastLine && f.commentsBefore(f.current) {
+		f.write(" " + f.comments[f.comment].Lexeme)
+		f.comment++
+	}
+	if f.line.Len() > 0 {
+		f.out.WriteString(strings.Repeat(INDENT, f.indent))
+		f.out.WriteString(f.line.String())
+	}
+	f.out.WriteString("\n")
+	f.line.Reset()
+}
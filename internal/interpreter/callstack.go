@@ -0,0 +1,134 @@
+package interpreter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-interpreter/internal/errors"
+	"github.com/go-interpreter/internal/token"
+)
+
+// maxCallDepth bounds the nesting of calls, so that runaway recursion is
+// reported as a stack overflow instead of exhausting the Go stack.
+const maxCallDepth = 10000
+
+// callFrame is a call in progress: the name of the function being run and
+// the closing parenthesis of the call that started it.
+type callFrame struct {
+	function string
+	call     token.Token
+}
+
+// SetFile sets the file name that stack traces refer to.
+func (i *Interpreter) SetFile(file string) {
+	i.file = file
+}
+
+// SetContext sets the context that stops the program once it is done.
+// Loops check it before every iteration and calls before they start.
+func (i *Interpreter) SetContext(ctx context.Context) {
+	i.context = ctx
+}
+
+// interrupted returns an error pointing at the given position when the
+// context is done.
+func (i *Interpreter) interrupted(line int, column int, where int, length int) error {
+	if i.context == nil || i.context.Err() == nil {
+		return nil
+	}
+	return errors.ExecutionError{
+		Type:    errors.RUNTIME_ERROR,
+		Code:    errors.INTERRUPTED,
+		Line:    line,
+		Column:  column,
+		Length:  length,
+		Where:   where,
+		Message: fmt.Sprintf("Interrupted: %v.", context.Cause(i.context)),
+	}
+}
+
+// call runs a callable with a frame for it on the call stack, and attaches
+// the stack trace to any error coming out of it.
+func (i *Interpreter) call(callable Callable, paren token.Token, arguments []Value) (Value, error) {
+	if len(i.frames) >= maxCallDepth {
+		return Nil, i.trace(errors.ExecutionError{
+			Type:    errors.RUNTIME_ERROR,
+			Code:    errors.STACK_OVERFLOW,
+			Line:    paren.Line,
+			Column:  paren.Column,
+			Length:  len(paren.Lexeme),
+			Where:   paren.Char,
+			Message: "Stack overflow.",
+		})
+	}
+	if err := i.interrupted(paren.Line, paren.Column, paren.Char, len(paren.Lexeme)); err != nil {
+		return Nil, i.trace(err)
+	}
+	i.frames = append(i.frames, callFrame{function: callableName(callable), call: paren})
+	defer func() { i.frames = i.frames[:len(i.frames)-1] }()
+	value, err := callable.Call(i, arguments)
+	if err != nil {
+		if _, ok := err.(errors.ExecutionError); !ok {
+			// The error comes from Go code, which has no place in the program:
+			// it is reported at the call, and the trace starts there
+			return Nil, i.traceFrames(nativeError(err, paren), i.frames[:len(i.frames)-1])
+		}
+		return Nil, i.trace(err)
+	}
+	return value, nil
+}
+
+// nativeError turns an error returned by Go code into a runtime error at
+// the token of the program that ran it: the call of a native function, or
+// the property of a host object.
+func nativeError(err error, at token.Token) errors.ExecutionError {
+	return errors.ExecutionError{
+		Type:    errors.RUNTIME_ERROR,
+		Code:    errors.NATIVE_ERROR,
+		Line:    at.Line,
+		Column:  at.Column,
+		Length:  len(at.Lexeme),
+		Where:   at.Char,
+		Message: err.Error(),
+	}
+}
+
+// trace attaches the current stack trace to a runtime error, unless an
+// inner call already did. The innermost frame is where the error happened,
+// every other frame is where the call to the next one was made.
+func (i *Interpreter) trace(err error) error {
+	return i.traceFrames(err, i.frames)
+}
+
+// traceFrames attaches the stack trace made of the given frames.
+func (i *Interpreter) traceFrames(err error, frames []callFrame) error {
+	executionError, ok := err.(errors.ExecutionError)
+	if !ok || executionError.Trace != nil {
+		return err
+	}
+	trace := make([]errors.Frame, 0, len(frames)+1)
+	line, column := executionError.Line, executionError.Column
+	for index := len(frames) - 1; index >= 0; index-- {
+		frame := frames[index]
+		trace = append(trace, errors.Frame{Function: frame.function, File: i.file, Line: line, Column: column})
+		line, column = frame.call.Line, frame.call.Column
+	}
+	trace = append(trace, errors.Frame{Function: errors.SCRIPT_FRAME, File: i.file, Line: line, Column: column})
+	executionError.Trace = trace
+	return executionError
+}
+
+// callableName is the name a callable goes by in a stack trace.
+func callableName(callable Callable) string {
+	switch c := callable.(type) {
+	case *Function:
+		return c.Declaration.Name.Lexeme
+	case *Class:
+		return c.Name
+	case *NativeFunction:
+		if c.Name != "" {
+			return c.Name
+		}
+	}
+	return fmt.Sprint(callable)
+}
@@ -0,0 +1,102 @@
+package interpreter
+
+import (
+	"fmt"
+
+	"github.com/go-interpreter/internal/errors"
+	"github.com/go-interpreter/internal/token"
+)
+
+// Class is the runtime representation of a 'class' declaration.
+// Calling a class creates a new Instance of it. Superclass is nil
+// unless the class was declared with a '< Superclass' clause.
+type Class struct {
+	Name       string
+	Superclass *Class
+	Methods    map[string]*Function
+}
+
+// NewClass creates a class value with the given superclass and methods.
+func NewClass(name string, superclass *Class, methods map[string]*Function) *Class {
+	return &Class{Name: name, Superclass: superclass, Methods: methods}
+}
+
+// FindMethod looks up a method by name, walking up the superclass
+// chain when the class itself does not declare it.
+func (class *Class) FindMethod(name string) (*Function, bool) {
+	if method, ok := class.Methods[name]; ok {
+		return method, true
+	}
+	if class.Superclass != nil {
+		return class.Superclass.FindMethod(name)
+	}
+	return nil, false
+}
+
+// Arity of a class is the arity of its 'init' method, or zero when
+// the class does not declare an initializer.
+func (class *Class) Arity() int {
+	if initializer, ok := class.FindMethod("init"); ok {
+		return initializer.Arity()
+	}
+	return 0
+}
+
+// Call creates a new instance and runs the initializer on it, if any.
+func (class *Class) Call(interpreter *Interpreter, arguments []Value) (Value, error) {
+	instance := NewInstance(class)
+	if initializer, ok := class.FindMethod("init"); ok {
+		_, err := initializer.Bind(instance).Call(interpreter, arguments)
+		if err != nil {
+			return Nil, err
+		}
+	}
+	return InstanceValue(instance), nil
+}
+
+// String is used by stringify when a class value gets printed.
+func (class *Class) String() string {
+	return class.Name
+}
+
+// Instance is an object created by calling a Class. It holds its own
+// fields and falls back to the methods of its class on property access.
+type Instance struct {
+	Class  *Class
+	Fields map[string]Value
+}
+
+// NewInstance creates an instance of the class without any fields.
+func NewInstance(class *Class) *Instance {
+	return &Instance{Class: class, Fields: make(map[string]Value)}
+}
+
+// Get returns the value of a property. Fields shadow methods; methods
+// are bound to the instance so that 'this' refers to it inside the body.
+func (instance *Instance) Get(name token.Token) (Value, error) {
+	if value, ok := instance.Fields[name.Lexeme]; ok {
+		return value, nil
+	}
+	if method, ok := instance.Class.FindMethod(name.Lexeme); ok {
+		return CallableValue(method.Bind(instance)), nil
+	}
+	return Nil, errors.ExecutionError{
+		Type:    errors.RUNTIME_ERROR,
+		Code:    errors.UNDEFINED_PROPERTY,
+		Line:    name.Line,
+		Column:  name.Column,
+		Length:  len(name.Lexeme),
+		Where:   name.Char,
+		Message: fmt.Sprintf("Undefined property '%s'.", name.Lexeme),
+	}
+}
+
+// Set creates or overwrites a field on the instance.
+func (instance *Instance) Set(name token.Token, value Value) {
+	instance.Fields[name.Lexeme] = value
+}
+
+// String is used by stringify when an instance gets printed.
+func (instance *Instance) String() string {
+	return fmt.Sprintf("%s instance", instance.Class.Name)
+}
@@ -6,3 +6,9 @@ const (
 	BREAK ControlSig = iota
 	CONTINUE
 )
+
+// ReturnSig carries the value of a 'return' statement up through the
+// enclosing blocks and loops until it reaches the function call.
+type ReturnSig struct {
+	Value Value
+}
@@ -11,7 +11,7 @@ import (
 // Used to store bindings
 type Environment struct {
 	Enclosing *Environment
-	Values    map[string]any
+	Values    map[string]Value
 }
 
 // NewEnvironment Initiates a new Environment
@@ -19,7 +19,7 @@ func NewEnvironment(enclosing *Environment) *Environment {
 	// Global scope
 	environment := &Environment{
 		Enclosing: nil,
-		Values:    make(map[string]any),
+		Values:    make(map[string]Value),
 	}
 	// Inner scope
 	if enclosing != nil {
@@ -29,14 +29,14 @@ func NewEnvironment(enclosing *Environment) *Environment {
 }
 
 // Define Defines a variable in the Environment
-// It will set as a mapping bound to a value of any type
-func (env *Environment) Define(varName string, value any) {
+// It will set as a mapping bound to a runtime Value
+func (env *Environment) Define(varName string, value Value) {
 	env.Values[varName] = value
 }
 
 // Get Gets the value of a bound variable in an Environment
 // If it doesn't find i, it raises an execution Error
-func (env *Environment) Get(token token.Token) (any, error) {
+func (env *Environment) Get(token token.Token) (Value, error) {
 	_, exists := env.Values[token.Lexeme]
 	if exists {
 		return env.Values[token.Lexeme], nil
@@ -45,24 +45,24 @@ func (env *Environment) Get(token token.Token) (any, error) {
 		// Recursively lookup the variable until we reach
 		// the global scope. That is, walk the entire chain
 		// of enclosing scopes.
-		value, err := env.Enclosing.Get(token)
-		if err != nil {
-			return nil, nil
-		}
-		return value, nil
+		return env.Enclosing.Get(token)
 	}
-	return nil, errors.ExecutionError{
+	return Nil, errors.ExecutionError{
 		Type:    errors.RUNTIME_ERROR,
+		Code:    errors.UNDEFINED_VARIABLE,
 		Line:    token.Line,
+		Column:  token.Column,
+		Length:  len(token.Lexeme),
 		Where:   token.Char,
 		Message: fmt.Sprintf("Undefined variable %s.", token.Lexeme),
+		Hint:    fmt.Sprintf("declare it with 'var %s' before it is used", token.Lexeme),
 	}
 }
 
 // Assign updates the value of an existing variable in the Environment.
 // If the variable with the given name exists, it sets its value to the provided one and returns nil.
 // If the variable does not exist, it returns an ExecutionError indicating the variable is undefined.
-func (env *Environment) Assign(name token.Token, value any) error {
+func (env *Environment) Assign(name token.Token, value Value) error {
 	_, containsKey := env.Values[name.Lexeme]
 	if containsKey {
 		env.Values[name.Lexeme] = value
@@ -79,8 +79,34 @@ func (env *Environment) Assign(name token.Token, value any) error {
 	}
 	return errors.ExecutionError{
 		Type:    errors.RUNTIME_ERROR,
+		Code:    errors.UNDEFINED_VARIABLE,
 		Line:    name.Line,
+		Column:  name.Column,
+		Length:  len(name.Lexeme),
 		Where:   name.Char,
 		Message: fmt.Sprintf("Undefined variable %s.", name.Lexeme),
+		Hint:    fmt.Sprintf("declare it with 'var %s' before assigning to it", name.Lexeme),
+	}
+}
+
+// Ancestor walks up the chain of enclosing environments a fixed
+// number of hops. The resolver guarantees the chain is deep enough.
+func (env *Environment) Ancestor(distance int) *Environment {
+	environment := env
+	for range distance {
+		environment = environment.Enclosing
 	}
+	return environment
+}
+
+// GetAt gets the value of a variable from the Environment exactly
+// distance hops up the chain, as computed by the resolver.
+func (env *Environment) GetAt(distance int, name string) Value {
+	return env.Ancestor(distance).Values[name]
+}
+
+// AssignAt updates the value of a variable in the Environment exactly
+// distance hops up the chain, as computed by the resolver.
+func (env *Environment) AssignAt(distance int, name token.Token, value Value) {
+	env.Ancestor(distance).Values[name.Lexeme] = value
 }
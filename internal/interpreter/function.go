@@ -0,0 +1,71 @@
+package interpreter
+
+import (
+	"fmt"
+
+	"github.com/go-interpreter/internal/ast"
+)
+
+// Callable is implemented by every runtime value that can be invoked
+// with the call syntax, such as user defined functions.
+type Callable interface {
+	Arity() int
+	Call(interpreter *Interpreter, arguments []Value) (Value, error)
+}
+
+// Function is the runtime representation of a 'fun' declaration or a
+// class method. Closure is the Environment that was active when the
+// function was declared, which keeps the enclosing scope alive for as
+// long as the function value itself is reachable.
+type Function struct {
+	Declaration   ast.FunctionStmt
+	Closure       *Environment
+	IsInitializer bool
+}
+
+// NewFunction wraps a function declaration and the Environment it
+// was declared in into a callable value. Class 'init' methods are
+// marked as initializers so that they always return the instance.
+func NewFunction(declaration ast.FunctionStmt, closure *Environment, isInitializer bool) *Function {
+	return &Function{Declaration: declaration, Closure: closure, IsInitializer: isInitializer}
+}
+
+// Bind returns a copy of the method whose closure defines 'this' as
+// the given instance.
+func (function *Function) Bind(instance *Instance) *Function {
+	environment := NewEnvironment(function.Closure)
+	environment.Define("this", InstanceValue(instance))
+	return NewFunction(function.Declaration, environment, function.IsInitializer)
+}
+
+// Arity returns the number of parameters the function declares.
+func (function *Function) Arity() int {
+	return len(function.Declaration.Params)
+}
+
+// Call binds the arguments to the parameters in a fresh Environment
+// enclosed by the closure and executes the body in it. A ReturnSig coming out of the body
+// provides the result; falling off the end of the body returns nil.
+func (function *Function) Call(interpreter *Interpreter, arguments []Value) (Value, error) {
+	environment := NewEnvironment(function.Closure)
+	for index, param := range function.Declaration.Params {
+		environment.Define(param.Lexeme, arguments[index])
+	}
+	signal, err := interpreter.execBlock(function.Declaration.Body, environment)
+	if err != nil {
+		return Nil, err
+	}
+	// Initializers hand back the instance even on a bare 'return;'
+	if function.IsInitializer {
+		return function.Closure.GetAt(0, "this"), nil
+	}
+	if ret, ok := signal.(ReturnSig); ok {
+		return ret.Value, nil
+	}
+	return Nil, nil
+}
+
+// String is used by stringify when a function value gets printed.
+func (function *Function) String() string {
+	return fmt.Sprintf("<fn %s>", function.Declaration.Name.Lexeme)
+}
@@ -0,0 +1,28 @@
+package interpreter
+
+// HostObject is an object of the Go program running the interpreter, which
+// scripts use like an instance: they read and write its properties with
+// '.' and call its methods. Get and Set report false for a property the
+// object does not have. Objects cannot grow new properties, unlike
+// instances. Other errors they return are reported as runtime errors at
+// the property.
+type HostObject interface {
+	Get(name string) (Value, bool, error)
+	Set(name string, value Value) (bool, error)
+}
+
+// HostValue wraps a host object. Host values are compared by identity,
+// so the object should be a pointer.
+func HostValue(object HostObject) Value {
+	return Value{kind: HOST_VALUE, object: object}
+}
+
+func (v Value) IsHost() bool {
+	return v.kind == HOST_VALUE
+}
+
+// AsHost returns the host object, or nil when the value is not one.
+func (v Value) AsHost() HostObject {
+	object, _ := v.object.(HostObject)
+	return object
+}
@@ -0,0 +1,46 @@
+package interpreter
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// Input is what programs read with the builtin readLine. Interpreters are
+// copied by value, so they share their Input through a pointer.
+type Input struct {
+	reader *bufio.Reader
+}
+
+// NewInput returns an Input reading from in.
+func NewInput(in io.Reader) *Input {
+	return &Input{reader: bufio.NewReader(in)}
+}
+
+// ReadLine returns the next line without its line ending, and false once
+// the input has run out.
+func (input *Input) ReadLine() (string, bool, error) {
+	line, err := input.reader.ReadString('\n')
+	if err == io.EOF && line == "" {
+		return "", false, nil
+	}
+	if err != nil && err != io.EOF {
+		return "", false, err
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"), true, nil
+}
+
+// builtins returns the environment around the globals, with the functions
+// every program can call. Globals of the same name hide them.
+func builtins(input *Input) *Environment {
+	environment := NewEnvironment(nil)
+	readLine := func(arguments []Value) (Value, error) {
+		line, ok, err := input.ReadLine()
+		if !ok {
+			return Nil, err
+		}
+		return StringValue(line), nil
+	}
+	environment.Define("readLine", CallableValue(NewNativeFunction("readLine", 0, readLine)))
+	return environment
+}
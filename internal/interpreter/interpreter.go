@@ -1,9 +1,11 @@
 package interpreter
 
 import (
+	"context"
 	_ "errors"
 	"fmt"
-	"strconv"
+	"io"
+	"os"
 
 	"github.com/go-interpreter/internal/ast"
 	"github.com/go-interpreter/internal/errors"
@@ -13,19 +15,71 @@ import (
 // Interpreter represents the core structure for the interpreter.
 // It is responsible for executing and evaluating code based on the
 // implemented logic and rules of the interpreter.
+// The locals table is filled in by the resolver and maps the Binding of
+// every local variable reference to the number of scopes between the
+// reference and its declaration. Globals are not in the table.
+// frames is the stack of calls in progress, and file the name of the
+// program, both for stack traces. Once context is done, loops and calls
+// stop the program. 'print' writes to out, and readLine reads from input.
 type Interpreter struct {
+	globals     *Environment
 	environment *Environment
+	locals      map[*ast.Binding]int
+	frames      []callFrame
+	file        string
+	context     context.Context
+	out         io.Writer
+	input       *Input
 }
 
+// NewInterpreter creates an interpreter with empty globals, printing to
+// standard output and reading from standard input.
 func NewInterpreter() Interpreter {
+	input := NewInput(os.Stdin)
+	globals := NewEnvironment(builtins(input))
 	return Interpreter{
-		environment: NewEnvironment(nil),
+		globals:     globals,
+		environment: globals,
+		locals:      make(map[*ast.Binding]int),
+		out:         os.Stdout,
+		input:       input,
 	}
 }
 
+// SetOutput sets where 'print' writes to.
+func (i *Interpreter) SetOutput(out io.Writer) {
+	i.out = out
+}
+
+// SetInput sets where readLine reads from.
+func (i *Interpreter) SetInput(in io.Reader) {
+	*i.input = *NewInput(in)
+}
+
+// Globals returns the outermost Environment, where top-level definitions live.
+func (i *Interpreter) Globals() *Environment {
+	return i.globals
+}
+
+// Resolve records the scope depth of a local variable reference. It is
+// called by the resolver before the program is interpreted.
+func (i *Interpreter) Resolve(binding *ast.Binding, depth int) {
+	i.locals[binding] = depth
+}
+
+// lookUpVariable reads a variable from the Environment the resolver bound it
+// to, or from the globals when the resolver left it unresolved.
+func (i *Interpreter) lookUpVariable(name token.Token, binding *ast.Binding) (Value, error) {
+	if distance, ok := i.locals[binding]; ok {
+		return i.environment.GetAt(distance, name.Lexeme), nil
+	}
+	return i.globals.Get(name)
+}
+
 // Interpret executes a series of statements provided as input.
 // It iterates over each statement, executing them one by one using the exec method.
-// If an error occurs during the execution of a statement, it logs the error to the console.
+// Execution stops at the first error, which is returned. Nothing but what
+// the program prints is written out.
 func (i *Interpreter) Interpret(stmts []ast.Stmt) error {
 	if len(stmts) == 0 {
 		return nil
@@ -39,20 +93,38 @@ func (i *Interpreter) Interpret(stmts []ast.Stmt) error {
 		}
 		_, err := i.exec(statement) // WE DO NOT EVAL STATEMENTS, WE EXECUTE THEM
 		if err != nil {
-			return fmt.Errorf("error: %v", err)
+			return fmt.Errorf("error: %w", i.trace(err))
 		}
 	}
-	fmt.Println("") // To get rid of that annoying "%" in the terminal
 	return nil
 }
 
+// Execute runs a single statement in the current Environment.
+func (i *Interpreter) Execute(stmt ast.Stmt) error {
+	_, err := i.exec(stmt)
+	if err != nil {
+		return i.trace(err)
+	}
+	return nil
+}
+
+// Evaluate evaluates a single expression in the current Environment and
+// returns its value.
+func (i *Interpreter) Evaluate(expr ast.Expr) (Value, error) {
+	value, err := i.eval(expr)
+	if err != nil {
+		return Nil, i.trace(err)
+	}
+	return value, nil
+}
+
 // VisitVarStmt handles the execution of a variable declaration statement.
 // It evaluates the initializer expression if present, defines the variable
 // in the current Environment with its name and value, and returns any error
 // encountered during evaluation. If no initializer is provided, the variable
 // is defined with a nil value.
 func (i *Interpreter) VisitVarStmt(stmt ast.VarStmt) (any, error) {
-	var value any = nil
+	value := Nil
 	if stmt.Initializer != nil {
 		var err error = nil
 		value, err = i.eval(stmt.Initializer)
@@ -64,15 +136,11 @@ func (i *Interpreter) VisitVarStmt(stmt ast.VarStmt) (any, error) {
 	return nil, nil
 }
 
-// VisitVariable VisitVarExpr evaluates a variable expression by retrieving its value from the current Environment.
-// It takes an ast.Variable as input, attempts to get the value associated with the variable's name,
-// and returns the value along with any error encountered during the lookup.
+// VisitVariable VisitVarExpr evaluates a variable expression by retrieving its value from the Environment
+// the resolver bound it to. It takes an ast.Variable as input, attempts to get the value associated with the
+// variable's name, and returns the value along with any error encountered during the lookup.
 func (i *Interpreter) VisitVariable(expr ast.Variable) (any, error) {
-	value, err := i.environment.Get(expr.Name)
-	if err != nil {
-		return nil, err
-	}
-	return value, nil
+	return i.lookUpVariable(expr.Name, expr.Binding)
 }
 
 // VisitIfStmt executes an if statement in the AST.
@@ -85,7 +153,7 @@ func (i *Interpreter) VisitIfStmt(stmt ast.IfStmt) (any, error) {
 		return nil, err
 	}
 	var signal any
-	if IsTruthy(evaluatedExpr) {
+	if evaluatedExpr.Truthy() {
 		signal, err = i.exec(stmt.ThenBranch)
 	} else if stmt.ElseBranch != nil {
 		signal, err = i.exec(stmt.ElseBranch)
@@ -97,20 +165,28 @@ func (i *Interpreter) VisitIfStmt(stmt ast.IfStmt) (any, error) {
 	if control, ok := signal.(ControlSig); ok {
 		return control, nil
 	}
+	if ret, ok := signal.(ReturnSig); ok {
+		return ret, nil
+	}
 
 	return nil, nil
 
 }
 
 // VisitAssign handles assignment expressions in the AST.
-// It evaluates the right-hand side value, then assigns it to the variable in the current Environment.
+// It evaluates the right-hand side value, then assigns it to the variable in the Environment the
+// resolver bound it to, falling back to the globals for unresolved names.
 // Returns the assigned value and any error encountered during evaluation or assignment.
 func (i *Interpreter) VisitAssign(expr ast.Assign) (any, error) {
 	value, err := i.eval(expr.Value)
 	if err != nil {
 		return nil, err
 	}
-	err = i.environment.Assign(expr.Name, value)
+	if distance, ok := i.locals[expr.Binding]; ok {
+		i.environment.AssignAt(distance, expr.Name, value)
+		return value, nil
+	}
+	err = i.globals.Assign(expr.Name, value)
 	if err != nil {
 		return nil, err
 	}
@@ -130,6 +206,9 @@ func (i *Interpreter) VisitBlockStmt(blockStmt ast.Block) (any, error) {
 	if control, ok := s.(ControlSig); ok {
 		return control, nil
 	}
+	if ret, ok := s.(ReturnSig); ok {
+		return ret, nil
+	}
 	return nil, nil
 }
 
@@ -138,21 +217,31 @@ func (i *Interpreter) VisitBlockStmt(blockStmt ast.Block) (any, error) {
 // along with any potential error. Literal expressions represent constant
 // values such as numbers, strings, or booleans in the abstract syntax tree.
 func (i *Interpreter) VisitLiteral(expr ast.Literal) (any, error) {
-	return expr.Value, nil
+	return LiteralValue(expr.Value), nil
 }
 
 // VisitUnary evaluates a unary expression in the abstract syntax tree (AST).
 // It performs a post-order evaluation of the operand and applies the unary operator.
 func (i *Interpreter) VisitUnary(expr ast.Unary) (any, error) {
-	right, _ := i.eval(expr.Right) // POST ORDER EVALUATION
+	right, err := i.eval(expr.Right) // POST ORDER EVALUATION
+	if err != nil {
+		return nil, err
+	}
 	switch expr.Operator.Type {
 	case token.MINUS:
-		return -right.(float64), nil
+		err := checkIfNumber(right, expr.Operator)
+		if err != nil {
+			return nil, err
+		}
+		return NumberValue(-right.AsNumber()), nil
 	case token.BANG:
-		return !IsTruthy(right), nil
+		return BoolValue(!right.Truthy()), nil
 	default:
 		return nil, errors.ExecutionError{Type: errors.RUNTIME_ERROR,
+			Code:    errors.INTERNAL_ERROR,
 			Line:    expr.Operator.Line,
+			Column:  expr.Operator.Column,
+			Length:  len(expr.Operator.Lexeme),
 			Where:   expr.Operator.Char,
 			Message: fmt.Sprintf("%s is not a valid operator", expr.Operator.Lexeme)}
 	}
@@ -166,14 +255,26 @@ func (i *Interpreter) VisitExpressionStmt(stmt ast.ExpressionStmt) (any, error)
 }
 
 // VisitPrintStmt evaluates a PrintStmt node in the abstract syntax tree (AST)
-// and prints the result of the evaluated expression to the standard output.
+// and prints the result of the evaluated expression to the output.
 // It takes a PrintStmt as input, evaluates its Expression field, and formats
 // the result using the stringify function before printing it.
-// Returns nil for both the result and error as this function is primarily
-// used for side effects (printing).
+// Returns nil as the result as this function is primarily used for side
+// effects (printing), along with any error raised by the expression.
 func (i *Interpreter) VisitPrintStmt(stmt ast.PrintStmt) (any, error) {
-	value, _ := i.eval(stmt.Expression)
-	fmt.Print(stringify(value))
+	value, err := i.eval(stmt.Expression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(i.out, stringify(value)); err != nil {
+		// The output belongs to the Go program, like native functions do
+		return nil, errors.ExecutionError{Type: errors.RUNTIME_ERROR,
+			Code:    errors.NATIVE_ERROR,
+			Line:    stmt.Start.Line,
+			Column:  stmt.Start.Column,
+			Length:  len("print"),
+			Where:   stmt.Start.Offset,
+			Message: err.Error()}
+	}
 	return nil, nil
 }
 
@@ -186,9 +287,14 @@ func (i *Interpreter) VisitGrouping(expr ast.Grouping) (any, error) {
 
 // eval evaluates the given AST expression by delegating the evaluation
 // to the expression's Accept method. It returns the result of the evaluation
-// along with any error encountered during the process.
-func (i *Interpreter) eval(expr ast.Expr) (any, error) {
-	return expr.Accept(i)
+// along with any error encountered during the process. Every expression
+// visitor of the Interpreter hands back a Value.
+func (i *Interpreter) eval(expr ast.Expr) (Value, error) {
+	value, err := expr.Accept(i)
+	if err != nil {
+		return Nil, err
+	}
+	return value.(Value), nil
 }
 
 // exec executes the given statement by invoking its Accept method,
@@ -213,6 +319,10 @@ func (i *Interpreter) execBlock(stmts []ast.Stmt, environment *Environment) (any
 		if err != nil {
 			return nil, err
 		}
+		// A return unwinds the block straight away
+		if ret, ok := s.(ReturnSig); ok {
+			return ret, nil
+		}
 		if control, ok := s.(ControlSig); ok {
 			sig = control
 			// We do not run the rest of the statements
@@ -236,11 +346,11 @@ func (i *Interpreter) VisitLogical(expr ast.Logical) (any, error) {
 		return nil, err
 	}
 	if token.OR == expr.Operator.Type {
-		if IsTruthy(left) {
+		if left.Truthy() {
 			return left, nil
 		}
 	} else {
-		if !IsTruthy(left) {
+		if !left.Truthy() {
 			return left, nil
 		}
 	}
@@ -256,7 +366,10 @@ func (i *Interpreter) VisitWhileStmt(expr ast.WhileStmt) (any, error) {
 	if err != nil {
 		return nil, err
 	}
-	for IsTruthy(condition) {
+	for condition.Truthy() {
+		if err := i.interrupted(expr.Start.Line, expr.Start.Column, expr.Start.Offset, len("while")); err != nil {
+			return nil, err
+		}
 		s, err := i.exec(expr.Body)
 		if err != nil {
 			return nil, err
@@ -266,7 +379,13 @@ func (i *Interpreter) VisitWhileStmt(expr ast.WhileStmt) (any, error) {
 				break
 			}
 		}
-		condition, _ = i.eval(expr.Condition)
+		if ret, ok := s.(ReturnSig); ok {
+			return ret, nil
+		}
+		condition, err = i.eval(expr.Condition)
+		if err != nil {
+			return nil, err
+		}
 	}
 	return nil, nil
 }
@@ -286,165 +405,398 @@ func (i *Interpreter) VisitContinueStmt() (any, error) {
 	return CONTINUE, nil
 }
 
+// VisitFunctionStmt turns a function declaration into a callable value and
+// binds it to the function name in the current Environment. The function
+// captures that Environment so it can still reach its enclosing scope
+// after the block it was declared in has finished executing.
+func (i *Interpreter) VisitFunctionStmt(stmt ast.FunctionStmt) (any, error) {
+	i.environment.Define(stmt.Name.Lexeme, CallableValue(NewFunction(stmt, i.environment, false)))
+	return nil, nil
+}
+
+// VisitClassStmt turns a class declaration into a class value holding its
+// methods and binds it to the class name in the current Environment.
+// When the class has a superclass, the methods close over an extra
+// Environment that binds 'super' to it.
+func (i *Interpreter) VisitClassStmt(stmt ast.ClassStmt) (any, error) {
+	var superclass *Class
+	if stmt.Superclass != nil {
+		if stmt.Superclass.Name.Lexeme == stmt.Name.Lexeme {
+			return nil, errors.ExecutionError{Type: errors.RUNTIME_ERROR,
+				Code:    errors.INHERIT_FROM_SELF,
+				Line:    stmt.Superclass.Name.Line,
+				Column:  stmt.Superclass.Name.Column,
+				Length:  len(stmt.Superclass.Name.Lexeme),
+				Where:   stmt.Superclass.Name.Char,
+				Message: "A class can't inherit from itself."}
+		}
+		value, err := i.eval(*stmt.Superclass)
+		if err != nil {
+			return nil, err
+		}
+		class := value.AsClass()
+		if class == nil {
+			return nil, errors.ExecutionError{Type: errors.RUNTIME_ERROR,
+				Code:    errors.SUPERCLASS_NOT_CLASS,
+				Line:    stmt.Superclass.Name.Line,
+				Column:  stmt.Superclass.Name.Column,
+				Length:  len(stmt.Superclass.Name.Lexeme),
+				Where:   stmt.Superclass.Name.Char,
+				Message: "Superclass must be a class."}
+		}
+		superclass = class
+	}
+
+	environment := i.environment
+	if superclass != nil {
+		environment = NewEnvironment(i.environment)
+		environment.Define("super", ClassValue(superclass))
+	}
+	methods := make(map[string]*Function, len(stmt.Methods))
+	for _, method := range stmt.Methods {
+		methods[method.Name.Lexeme] = NewFunction(method, environment, method.Name.Lexeme == "init")
+	}
+	i.environment.Define(stmt.Name.Lexeme, ClassValue(NewClass(stmt.Name.Lexeme, superclass, methods)))
+	return nil, nil
+}
+
+// VisitSuper looks up a method on the superclass of the class the current
+// method was declared in, and binds it to the current instance. The
+// Environment binding 'this' always sits right inside the one binding 'super'.
+func (i *Interpreter) VisitSuper(expr ast.Super) (any, error) {
+	distance := i.locals[expr.Binding]
+	superclass := i.environment.GetAt(distance, "super").AsClass()
+	instance := i.environment.GetAt(distance-1, "this").AsInstance()
+	method, ok := superclass.FindMethod(expr.Method.Lexeme)
+	if !ok {
+		return nil, errors.ExecutionError{Type: errors.RUNTIME_ERROR,
+			Code:    errors.UNDEFINED_PROPERTY,
+			Line:    expr.Method.Line,
+			Column:  expr.Method.Column,
+			Length:  len(expr.Method.Lexeme),
+			Where:   expr.Method.Char,
+			Message: fmt.Sprintf("Undefined property '%s'.", expr.Method.Lexeme)}
+	}
+	return CallableValue(method.Bind(instance)), nil
+}
+
+// VisitGet evaluates a property access. Only instances have properties.
+func (i *Interpreter) VisitGet(expr ast.Get) (any, error) {
+	object, err := i.eval(expr.Object)
+	if err != nil {
+		return nil, err
+	}
+	if object.IsInstance() {
+		return object.AsInstance().Get(expr.Name)
+	}
+	if object.IsHost() {
+		value, ok, err := object.AsHost().Get(expr.Name.Lexeme)
+		return value, hostError(ok, err, expr.Name)
+	}
+	return nil, errors.ExecutionError{Type: errors.RUNTIME_ERROR,
+		Code:    errors.NOT_AN_INSTANCE,
+		Line:    expr.Name.Line,
+		Column:  expr.Name.Column,
+		Length:  len(expr.Name.Lexeme),
+		Where:   expr.Name.Char,
+		Message: "Only instances have properties."}
+}
+
+// VisitSet evaluates the object and then the value, and stores the value
+// in the named field of the instance. The assigned value is returned.
+func (i *Interpreter) VisitSet(expr ast.Set) (any, error) {
+	object, err := i.eval(expr.Object)
+	if err != nil {
+		return nil, err
+	}
+	if !object.IsInstance() && !object.IsHost() {
+		return nil, errors.ExecutionError{Type: errors.RUNTIME_ERROR,
+			Code:    errors.NOT_AN_INSTANCE,
+			Line:    expr.Name.Line,
+			Column:  expr.Name.Column,
+			Length:  len(expr.Name.Lexeme),
+			Where:   expr.Name.Char,
+			Message: "Only instances have fields."}
+	}
+	value, err := i.eval(expr.Value)
+	if err != nil {
+		return nil, err
+	}
+	if object.IsHost() {
+		ok, err := object.AsHost().Set(expr.Name.Lexeme, value)
+		return value, hostError(ok, err, expr.Name)
+	}
+	object.AsInstance().Set(expr.Name, value)
+	return value, nil
+}
+
+// hostError turns the outcome of a property access on a host object into
+// a runtime error at the property, if it failed.
+func hostError(ok bool, err error, name token.Token) error {
+	if err != nil {
+		return nativeError(err, name)
+	}
+	if !ok {
+		return errors.ExecutionError{Type: errors.RUNTIME_ERROR,
+			Code:    errors.UNDEFINED_PROPERTY,
+			Line:    name.Line,
+			Column:  name.Column,
+			Length:  len(name.Lexeme),
+			Where:   name.Char,
+			Message: fmt.Sprintf("Undefined property '%s'.", name.Lexeme)}
+	}
+	return nil
+}
+
+// VisitIncrement evaluates the object once, and adds one to the named
+// property of the instance or host object the way '+' does. The
+// incremented value is returned.
+func (i *Interpreter) VisitIncrement(expr ast.Increment) (any, error) {
+	object, err := i.eval(expr.Object)
+	if err != nil {
+		return nil, err
+	}
+	if object.IsHost() {
+		value, ok, err := object.AsHost().Get(expr.Name.Lexeme)
+		if err := hostError(ok, err, expr.Name); err != nil {
+			return nil, err
+		}
+		incremented, err := add(value, NumberValue(1), expr.Operator)
+		if err != nil {
+			return nil, err
+		}
+		ok, err = object.AsHost().Set(expr.Name.Lexeme, incremented)
+		return incremented, hostError(ok, err, expr.Name)
+	}
+	if !object.IsInstance() {
+		return nil, errors.ExecutionError{Type: errors.RUNTIME_ERROR,
+			Code:    errors.NOT_AN_INSTANCE,
+			Line:    expr.Name.Line,
+			Column:  expr.Name.Column,
+			Length:  len(expr.Name.Lexeme),
+			Where:   expr.Name.Char,
+			Message: "Only instances have fields."}
+	}
+	value, err := object.AsInstance().Get(expr.Name)
+	if err != nil {
+		return nil, err
+	}
+	incremented, err := add(value, NumberValue(1), expr.Operator)
+	if err != nil {
+		return nil, err
+	}
+	object.AsInstance().Set(expr.Name, incremented)
+	return incremented, nil
+}
+
+// VisitThis looks up the instance a method has been bound to.
+func (i *Interpreter) VisitThis(expr ast.This) (any, error) {
+	return i.lookUpVariable(expr.Keyword, expr.Binding)
+}
+
+// VisitReturnStmt evaluates the optional return value and hands it back
+// as a ReturnSig, which unwinds the enclosing blocks and loops until the
+// surrounding function call picks it up.
+func (i *Interpreter) VisitReturnStmt(stmt ast.ReturnStmt) (any, error) {
+	value := Nil
+	if stmt.Value != nil {
+		var err error
+		value, err = i.eval(stmt.Value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ReturnSig{Value: value}, nil
+}
+
+// VisitCall evaluates the callee and its arguments from left to right and
+// invokes the callee. Calling something that is not a Callable, or passing
+// the wrong number of arguments, is a runtime error.
+func (i *Interpreter) VisitCall(expr ast.Call) (any, error) {
+	callee, err := i.eval(expr.Callee)
+	if err != nil {
+		return nil, err
+	}
+	arguments := make([]Value, 0, len(expr.Arguments))
+	for _, argument := range expr.Arguments {
+		value, err := i.eval(argument)
+		if err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, value)
+	}
+	if !callee.IsCallable() {
+		return nil, errors.ExecutionError{Type: errors.RUNTIME_ERROR,
+			Code:    errors.NOT_CALLABLE,
+			Line:    expr.Paren.Line,
+			Column:  expr.Paren.Column,
+			Length:  len(expr.Paren.Lexeme),
+			Where:   expr.Paren.Char,
+			Message: "Can only call functions and classes."}
+	}
+	function := callee.AsCallable()
+	if function.Arity() != VARIADIC && len(arguments) != function.Arity() {
+		return nil, errors.ExecutionError{Type: errors.RUNTIME_ERROR,
+			Code:    errors.WRONG_ARGUMENT_COUNT,
+			Line:    expr.Paren.Line,
+			Column:  expr.Paren.Column,
+			Length:  len(expr.Paren.Lexeme),
+			Where:   expr.Paren.Char,
+			Message: fmt.Sprintf("Expected %d arguments but got %d.", function.Arity(), len(arguments))}
+	}
+	return i.call(function, expr.Paren, arguments)
+}
+
 // VisitBinary evaluates a binary expression by visiting its left and right operands
 // and applying the operator specified in the expression. It supports various operators
-// such as arithmetic, comparison, logical, and string concatenation.
+// such as arithmetic, comparison, logical, and string concatenation. Operands of the
+// wrong kind are reported as runtime errors.
 func (i *Interpreter) VisitBinary(expr ast.Binary) (any, error) {
-	left, _ := i.eval(expr.Left)
-	right, _ := i.eval(expr.Right)
+	left, err := i.eval(expr.Left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := i.eval(expr.Right)
+	if err != nil {
+		return nil, err
+	}
 	switch expr.Operator.Type {
-	case token.MINUS:
-		return right.(float64) - left.(float64), nil
-	case token.PLUS:
-		// Check if the operands are strings
-		if leftValue, ok := left.(string); ok {
-			if rightValue, ok := right.(string); ok {
-				return leftValue + rightValue, nil
-			}
-			err := checkIfNumber(right, expr.Operator)
-			if err != nil {
-				return nil, err
-			}
-			return leftValue + strconv.FormatFloat(right.(float64), 'g', -1, 64), nil
-		}
-		if rightValue, ok := right.(string); ok {
-			// We know that the right is a string, so we need to check if the left is a number
-			err := checkIfNumber(left, expr.Operator)
-			if err != nil {
-				return nil, err
-			}
-			return rightValue + strconv.FormatFloat(left.(float64), 'g', -1, 64), nil
-		}
+	case token.PLUS, token.INC:
+		return add(left, right, expr.Operator)
+	case token.MINUS, token.DEC:
 		err := checkIfNumbers(left, right, expr.Operator)
 		if err != nil {
 			return nil, err
 		}
-		return right.(float64) + left.(float64), nil
-	case token.INC:
-		return left.(float64) + right.(float64), nil
-
-	case token.DEC:
-		return left.(float64) - right.(float64), nil
+		return NumberValue(left.AsNumber() - right.AsNumber()), nil
 	case token.SLASH:
 		err := checkIfNumbers(left, right, expr.Operator)
 		if err != nil {
 			return nil, err
 		}
-		return right.(float64) / left.(float64), nil
+		return NumberValue(left.AsNumber() / right.AsNumber()), nil
 	case token.STAR:
 		err := checkIfNumbers(left, right, expr.Operator)
 		if err != nil {
 			return nil, err
 		}
-		return right.(float64) * left.(float64), nil
+		return NumberValue(left.AsNumber() * right.AsNumber()), nil
 	case token.GREATER:
 		err := checkIfNumbers(left, right, expr.Operator)
 		if err != nil {
 			return nil, err
 		}
-		return left.(float64) > right.(float64), nil
+		return BoolValue(left.AsNumber() > right.AsNumber()), nil
 	case token.GREATER_EQUAL:
 		err := checkIfNumbers(left, right, expr.Operator)
 		if err != nil {
 			return nil, err
 		}
-		return left.(float64) >= right.(float64), nil
+		return BoolValue(left.AsNumber() >= right.AsNumber()), nil
 	case token.LESS:
 		err := checkIfNumbers(left, right, expr.Operator)
 		if err != nil {
 			return nil, err
 		}
-		return left.(float64) < right.(float64), nil
+		return BoolValue(left.AsNumber() < right.AsNumber()), nil
 	case token.LESS_EQUAL:
 		err := checkIfNumbers(left, right, expr.Operator)
 		if err != nil {
 			return nil, err
 		}
-		return left.(float64) <= right.(float64), nil
+		return BoolValue(left.AsNumber() <= right.AsNumber()), nil
 	case token.BANG_EQUAL:
-		return !isEqual(left, right), nil
+		return BoolValue(!isEqual(left, right)), nil
 	case token.EQUAL_EQUAL:
-		return isEqual(left, right), nil
+		return BoolValue(isEqual(left, right)), nil
 	case token.AND:
 		err := checkIfBooleans(left, right, expr.Operator)
 		if err != nil {
 			return nil, err
 		}
-		return left.(bool) && right.(bool), nil
+		return BoolValue(left.AsBool() && right.AsBool()), nil
 	case token.OR:
 		err := checkIfBooleans(left, right, expr.Operator)
 		if err != nil {
 			return nil, err
 		}
-		return left.(bool) || right.(bool), nil
+		return BoolValue(left.AsBool() || right.AsBool()), nil
 	default:
 		return nil, errors.ExecutionError{Type: errors.RUNTIME_ERROR,
+			Code:    errors.INTERNAL_ERROR,
 			Line:    expr.Operator.Line,
+			Column:  expr.Operator.Column,
+			Length:  len(expr.Operator.Lexeme),
 			Where:   expr.Operator.Char,
 			Message: fmt.Sprintf("%s is not a valid operator", expr.Operator.Lexeme)}
 	}
 }
 
-func isEqual(left, right any) bool {
-	if left == nil && right == nil {
-		return true
-	}
-	if left == nil {
-		return false
-	}
-	return left == right
+func isEqual(left, right Value) bool {
+	return left.Equal(right)
 }
-func IsTruthy(object any) bool {
-	switch v := object.(type) {
-	case nil:
-		return false
-	case string:
-		return len(v) > 0
-	case int:
-		return v > 0 || v < 0
-	case bool:
-		return v
-	case float64:
-		return v > 0.0
-	default:
-		return false
-	}
+
+// IsTruthy reports whether a value counts as true in a condition.
+func IsTruthy(object Value) bool {
+	return object.Truthy()
 }
 
-func stringify(object any) string {
-	switch value := object.(type) {
-	case float64:
-		return strconv.FormatFloat(value, 'g', -1, 64)
-	case string:
-		return value
-	case nil:
-		return ""
-	default:
-		return fmt.Sprint(value)
+func stringify(object Value) string {
+	return object.String()
+}
+
+func checkIfNumber(object Value, operator token.Token) error {
+	if !object.IsNumber() {
+		return operandError(object, "number", operator)
 	}
+	return nil
 }
 
-func checkIfNumber(object any, operator token.Token) error {
-	if _, ok := object.(float64); !ok {
-		return errors.ExecutionError{Type: errors.RUNTIME_ERROR,
-			Line:    operator.Line,
-			Where:   operator.Char,
-			Message: fmt.Sprintf("'%v' Operand must be a number", object)}
+func checkIfBoolean(object Value, operator token.Token) error {
+	if !object.IsBool() {
+		return operandError(object, "boolean", operator)
 	}
 	return nil
 }
 
-func checkIfBoolean(object any, operator token.Token) error {
-	if _, ok := object.(bool); !ok {
-		return errors.ExecutionError{Type: errors.RUNTIME_ERROR,
-			Line:    operator.Line,
-			Where:   operator.Char,
-			Message: fmt.Sprintf("'%v' Operand must be a boolean", object)}
+// add handles '+', which adds numbers and concatenates strings. A string
+// and a number are concatenated with the number formatted as text.
+func add(left, right Value, operator token.Token) (Value, error) {
+	if left.IsString() || right.IsString() {
+		err := checkIfConcatenable(left, right, operator)
+		if err != nil {
+			return Nil, err
+		}
+		return StringValue(left.String() + right.String()), nil
+	}
+	err := checkIfNumbers(left, right, operator)
+	if err != nil {
+		return Nil, err
+	}
+	return NumberValue(left.AsNumber() + right.AsNumber()), nil
+}
+
+// checkIfConcatenable accepts a string joined with either a string or a number.
+func checkIfConcatenable(left, right Value, operator token.Token) error {
+	for _, operand := range []Value{left, right} {
+		if !operand.IsString() && !operand.IsNumber() {
+			return operandError(operand, "string or a number", operator)
+		}
 	}
 	return nil
 }
 
-func checkIfBooleans(left, right any, operator token.Token) error {
+func operandError(object Value, expected string, operator token.Token) error {
+	return errors.ExecutionError{Type: errors.RUNTIME_ERROR,
+		Code:    errors.INVALID_OPERAND,
+		Line:    operator.Line,
+		Column:  operator.Column,
+		Length:  len(operator.Lexeme),
+		Where:   operator.Char,
+		Message: fmt.Sprintf("'%v' Operand must be a %s", object, expected)}
+}
+
+func checkIfBooleans(left, right Value, operator token.Token) error {
 	err := checkIfBoolean(left, operator)
 	if err != nil {
 		return err
@@ -456,7 +808,7 @@ func checkIfBooleans(left, right any, operator token.Token) error {
 	return nil
 }
 
-func checkIfNumbers(left, right any, operator token.Token) error {
+func checkIfNumbers(left, right Value, operator token.Token) error {
 	err := checkIfNumber(left, operator)
 	if err != nil {
 		return err
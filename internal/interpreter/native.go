@@ -0,0 +1,41 @@
+package interpreter
+
+import "fmt"
+
+// VARIADIC is the arity of a native function that takes any number of
+// arguments.
+const VARIADIC = -1
+
+// NativeFunction is a function written in Go, like those a host program
+// defines for its scripts. Params is the number of arguments it takes, or
+// VARIADIC. An error coming out of Function is reported as a runtime error
+// at the call.
+type NativeFunction struct {
+	Name     string
+	Params   int
+	Function func(arguments []Value) (Value, error)
+}
+
+// NewNativeFunction wraps a Go function into a callable value.
+func NewNativeFunction(name string, params int, function func(arguments []Value) (Value, error)) *NativeFunction {
+	return &NativeFunction{Name: name, Params: params, Function: function}
+}
+
+// Arity returns the number of arguments the function takes, or VARIADIC.
+func (native *NativeFunction) Arity() int {
+	return native.Params
+}
+
+// Call hands the arguments to the Go function.
+func (native *NativeFunction) Call(interpreter *Interpreter, arguments []Value) (Value, error) {
+	return native.Function(arguments)
+}
+
+// String is used by stringify when a native function gets printed.
+// Functions converted from Go values may have no name.
+func (native *NativeFunction) String() string {
+	if native.Name == "" {
+		return "<native fn>"
+	}
+	return fmt.Sprintf("<native fn %s>", native.Name)
+}
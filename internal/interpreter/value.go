@@ -0,0 +1,228 @@
+package interpreter
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ValueKind tells which of the fields of a Value is in use.
+type ValueKind uint8
+
+const (
+	NIL_VALUE ValueKind = iota
+	BOOL_VALUE
+	NUMBER_VALUE
+	STRING_VALUE
+	FUNCTION_VALUE
+	CLASS_VALUE
+	INSTANCE_VALUE
+	OBJECT_VALUE
+	HOST_VALUE
+)
+
+var kindNames = [...]string{
+	NIL_VALUE:      "nil",
+	BOOL_VALUE:     "boolean",
+	NUMBER_VALUE:   "number",
+	STRING_VALUE:   "string",
+	FUNCTION_VALUE: "function",
+	CLASS_VALUE:    "class",
+	INSTANCE_VALUE: "instance",
+	OBJECT_VALUE:   "object",
+	HOST_VALUE:     "host object",
+}
+
+func (kind ValueKind) String() string {
+	return kindNames[kind]
+}
+
+// Value is a runtime value of the language. It is a small tagged union:
+// booleans and numbers live in number, strings and objects live in object,
+// so that the common scalar values never have to be boxed.
+type Value struct {
+	kind   ValueKind
+	number float64
+	object any
+}
+
+// Nil is the zero Value.
+var Nil = Value{}
+
+// BoolValue wraps a Go bool.
+func BoolValue(b bool) Value {
+	if b {
+		return Value{kind: BOOL_VALUE, number: 1}
+	}
+	return Value{kind: BOOL_VALUE}
+}
+
+// NumberValue wraps a Go float64.
+func NumberValue(number float64) Value {
+	return Value{kind: NUMBER_VALUE, number: number}
+}
+
+// StringValue wraps a Go string.
+func StringValue(s string) Value {
+	return Value{kind: STRING_VALUE, object: s}
+}
+
+// CallableValue wraps a function, or anything else that can be called
+// but is not a class.
+func CallableValue(callable Callable) Value {
+	if class, ok := callable.(*Class); ok {
+		return ClassValue(class)
+	}
+	return Value{kind: FUNCTION_VALUE, object: callable}
+}
+
+// ClassValue wraps a class.
+func ClassValue(class *Class) Value {
+	return Value{kind: CLASS_VALUE, object: class}
+}
+
+// InstanceValue wraps an instance of a class.
+func InstanceValue(instance *Instance) Value {
+	return Value{kind: INSTANCE_VALUE, object: instance}
+}
+
+// ObjectValue wraps a value owned by another runtime, such as the closures
+// and instances of the virtual machine. Objects are true and are compared
+// by identity.
+func ObjectValue(object fmt.Stringer) Value {
+	return Value{kind: OBJECT_VALUE, object: object}
+}
+
+// LiteralValue converts the value of a literal produced by the scanner.
+func LiteralValue(literal any) Value {
+	switch v := literal.(type) {
+	case bool:
+		return BoolValue(v)
+	case float64:
+		return NumberValue(v)
+	case string:
+		return StringValue(v)
+	default:
+		return Nil
+	}
+}
+
+// Kind returns which kind of value this is.
+func (v Value) Kind() ValueKind {
+	return v.kind
+}
+
+func (v Value) IsNil() bool {
+	return v.kind == NIL_VALUE
+}
+
+func (v Value) IsBool() bool {
+	return v.kind == BOOL_VALUE
+}
+
+func (v Value) IsNumber() bool {
+	return v.kind == NUMBER_VALUE
+}
+
+func (v Value) IsString() bool {
+	return v.kind == STRING_VALUE
+}
+
+func (v Value) IsInstance() bool {
+	return v.kind == INSTANCE_VALUE
+}
+
+// IsCallable reports whether the value can be called: functions and classes.
+func (v Value) IsCallable() bool {
+	return v.kind == FUNCTION_VALUE || v.kind == CLASS_VALUE
+}
+
+// AsBool returns the boolean; it is only meaningful when IsBool holds.
+func (v Value) AsBool() bool {
+	return v.number != 0
+}
+
+// AsNumber returns the number; it is only meaningful when IsNumber holds.
+func (v Value) AsNumber() float64 {
+	return v.number
+}
+
+// AsString returns the string, or "" when the value is not a string.
+func (v Value) AsString() string {
+	s, _ := v.object.(string)
+	return s
+}
+
+// AsCallable returns the callable, or nil when the value cannot be called.
+func (v Value) AsCallable() Callable {
+	callable, _ := v.object.(Callable)
+	return callable
+}
+
+// AsClass returns the class, or nil when the value is not a class.
+func (v Value) AsClass() *Class {
+	class, _ := v.object.(*Class)
+	return class
+}
+
+// AsInstance returns the instance, or nil when the value is not an instance.
+func (v Value) AsInstance() *Instance {
+	instance, _ := v.object.(*Instance)
+	return instance
+}
+
+// AsObject returns the object wrapped by ObjectValue, or nil when the value
+// is not an object.
+func (v Value) AsObject() fmt.Stringer {
+	if v.kind != OBJECT_VALUE {
+		return nil
+	}
+	return v.object.(fmt.Stringer)
+}
+
+// Truthy implements the truthiness rules of the language: nil, false,
+// zero and the empty string are false; everything else is true.
+func (v Value) Truthy() bool {
+	switch v.kind {
+	case NIL_VALUE:
+		return false
+	case BOOL_VALUE, NUMBER_VALUE:
+		return v.number != 0
+	case STRING_VALUE:
+		return v.AsString() != ""
+	default:
+		return true
+	}
+}
+
+// Equal compares two values. Values of different kinds are never equal;
+// functions, classes and instances are compared by identity.
+func (v Value) Equal(other Value) bool {
+	if v.kind != other.kind {
+		return false
+	}
+	switch v.kind {
+	case NIL_VALUE:
+		return true
+	case BOOL_VALUE, NUMBER_VALUE:
+		return v.number == other.number
+	default:
+		return v.object == other.object
+	}
+}
+
+// String formats the value the way 'print' shows it. Nil prints as
+// nothing at all.
+func (v Value) String() string {
+	switch v.kind {
+	case NIL_VALUE:
+		return ""
+	case BOOL_VALUE:
+		return strconv.FormatBool(v.AsBool())
+	case NUMBER_VALUE:
+		return strconv.FormatFloat(v.number, 'g', -1, 64)
+	case STRING_VALUE:
+		return v.AsString()
+	default:
+		return fmt.Sprint(v.object)
+	}
+}
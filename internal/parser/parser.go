@@ -2,19 +2,26 @@ package parser
 
 import (
 	"fmt"
+	"unicode/utf8"
 
 	"github.com/go-interpreter/internal/ast"
 	"github.com/go-interpreter/internal/errors"
 	"github.com/go-interpreter/internal/token"
 )
 
+// maxArguments caps the number of parameters a function can declare and
+// the number of arguments a call can pass.
+const maxArguments = 255
+
 // Parser is responsible for processing a sequence of tokens and
 // converting them into a meaningful structure, typically an
 // Abstract Syntax Tree (AST). It keeps track of the tokens to
 // be parsed and the current position within the token stream.
+// File names the source in the spans of the nodes.
 type Parser struct {
 	Tokens    []token.Token
 	Current   int
+	File      string
 	loopDepth int
 }
 
@@ -30,26 +37,62 @@ func NewParser(tokens []token.Token) Parser {
 }
 
 // Parse parses the input source code into a slice of abstract syntax tree (AST) statements.
-// It continues parsing until the end of the input is reached or an error occurs.
-// Returns the parsed statements or an error if parsing fails.
-func (parser *Parser) Parse() []ast.Stmt {
+// It continues parsing until the end of the input is reached. After an error the parser
+// synchronizes to the next statement and carries on, so that every syntax error is reported
+// at once. Statements that failed to parse are left out; the program is only meant to be
+// run when no errors came back.
+func (parser *Parser) Parse() ([]ast.Stmt, []errors.ExecutionError) {
 	var statements []ast.Stmt
+	var parseErrors []errors.ExecutionError
 	for !parser.isAtEnd() {
 		decs, err := parser.Declarations()
 		if err != nil {
-			fmt.Println(err)
+			parseErrors = append(parseErrors, parser.diagnostic(err))
+			continue
 		}
 		statements = append(statements, decs)
 	}
-	return statements
+	return statements, parseErrors
+}
+
+// diagnostic turns whatever went wrong into an ExecutionError, so that
+// callers always get structured errors back.
+func (parser *Parser) diagnostic(err error) errors.ExecutionError {
+	if executionError, ok := err.(errors.ExecutionError); ok {
+		return executionError
+	}
+	return errors.ExecutionError{
+		Type:    errors.PARSER_ERROR,
+		Line:    parser.peek().Line,
+		Column:  parser.peek().Column,
+		Length:  len(parser.peek().Lexeme),
+		Where:   parser.peek().Char,
+		Message: err.Error(),
+	}
 }
 
 // Declarations parses a declaration statement from the input tokens.
+// If the current token is a CLASS keyword, it parses a class declaration.
+// If the current token is a FUN keyword, it parses a function declaration.
 // If the current token is a VAR keyword, it parses a variable declaration.
 // Otherwise, it parses a general statement. If an error occurs during parsing,
 // the parser attempts to recover by synchronizing to the next valid statement boundary.
 // Returns the parsed statement node, or nil if parsing fails.
 func (parser *Parser) Declarations() (ast.Stmt, error) {
+	if parser.match(token.CLASS) {
+		stmt, err := parser.classDeclaration()
+		if err != nil {
+			parser.synchronize()
+		}
+		return stmt, err
+	}
+	if parser.match(token.FUN) {
+		stmt, err := parser.function("function", parser.previous())
+		if err != nil {
+			parser.synchronize()
+		}
+		return stmt, err
+	}
 	if parser.match(token.VAR) {
 		stmt, err := parser.varDeclaration()
 		if err != nil {
@@ -65,11 +108,106 @@ func (parser *Parser) Declarations() (ast.Stmt, error) {
 
 }
 
+// classDeclaration parses a class name, an optional '< Superclass' clause
+// and a body of method declarations enclosed in braces. Methods are
+// written like functions without the leading 'fun' keyword.
+func (parser *Parser) classDeclaration() (ast.Stmt, error) {
+	keyword := parser.previous()
+	name, err := parser.consume(token.IDENTIFIER, "Expect class name.")
+	if err != nil {
+		return nil, err
+	}
+	var superclass *ast.Variable
+	if parser.match(token.LESS) {
+		superName, err := parser.consume(token.IDENTIFIER, "Expect superclass name.")
+		if err != nil {
+			return nil, err
+		}
+		superclass = &ast.Variable{Span: parser.span(superName), Name: superName, Binding: ast.NewBinding(superName)}
+	}
+	_, err = parser.consume(token.LEFT_BRACE, "Expect '{' before class body.")
+	if err != nil {
+		return nil, err
+	}
+	methods := make([]ast.FunctionStmt, 0)
+	for !parser.check(token.RIGHT_BRACE) && !parser.isAtEnd() {
+		method, err := parser.function("method", parser.peek())
+		if err != nil {
+			return nil, err
+		}
+		methods = append(methods, method.(ast.FunctionStmt))
+	}
+	_, err = parser.consume(token.RIGHT_BRACE, "Expect '}' after class body.")
+	if err != nil {
+		return nil, err
+	}
+	return ast.ClassStmt{Span: parser.span(keyword), Name: name, Superclass: superclass, Methods: methods}, nil
+}
+
+// function parses a named function declaration: the name, a parenthesised
+// list of parameters, and a block body. The kind is only used to produce
+// friendlier error messages and first is where the declaration starts, the
+// 'fun' keyword or the name of a method. Loops enclosing the declaration do
+// not extend into the body, so a bare 'break' inside the function is still
+// rejected.
+func (parser *Parser) function(kind string, first token.Token) (ast.Stmt, error) {
+	name, err := parser.consume(token.IDENTIFIER, fmt.Sprintf("Expect %s name.", kind))
+	if err != nil {
+		return nil, err
+	}
+	_, err = parser.consume(token.LEFT_PAREN, fmt.Sprintf("Expect '(' after %s name.", kind))
+	if err != nil {
+		return nil, err
+	}
+	params := make([]token.Token, 0)
+	if !parser.check(token.RIGHT_PAREN) {
+		for {
+			if len(params) >= maxArguments {
+				return nil, errors.ExecutionError{
+					Type:    errors.PARSER_ERROR,
+					Code:    errors.TOO_MANY_PARAMETERS,
+					Line:    parser.peek().Line,
+					Column:  parser.peek().Column,
+					Length:  len(parser.peek().Lexeme),
+					Where:   parser.peek().Char,
+					Message: fmt.Sprintf("Can't have more than %d parameters.", maxArguments),
+				}
+			}
+			param, err := parser.consume(token.IDENTIFIER, "Expect parameter name.")
+			if err != nil {
+				return nil, err
+			}
+			params = append(params, param)
+			if !parser.match(token.COMMA) {
+				break
+			}
+		}
+	}
+	_, err = parser.consume(token.RIGHT_PAREN, "Expect ')' after parameters.")
+	if err != nil {
+		return nil, err
+	}
+	_, err = parser.consume(token.LEFT_BRACE, fmt.Sprintf("Expect '{' before %s body.", kind))
+	if err != nil {
+		return nil, err
+	}
+
+	enclosingLoopDepth := parser.loopDepth
+	parser.loopDepth = 0
+	body, err := parser.block()
+	parser.loopDepth = enclosingLoopDepth
+	if err != nil {
+		return nil, err
+	}
+	return ast.FunctionStmt{Span: parser.span(first), Name: name, Params: params, Body: body}, nil
+}
+
 // varDeclaration parses a variable declaration statement from the input tokens.
 // It expects an identifier for the variable name, optionally followed by an
 // initializer expression if an '=' token is present, and requires a terminating
 // semicolon. If parsing fails at any stage, it returns an error.
 func (parser *Parser) varDeclaration() (ast.Stmt, error) {
+	keyword := parser.previous()
 	tokenName, err := parser.consume(token.IDENTIFIER, "Expect variable name.")
 	if err != nil {
 		return nil, err
@@ -86,7 +224,7 @@ func (parser *Parser) varDeclaration() (ast.Stmt, error) {
 	if err != nil {
 		return nil, err
 	}
-	return ast.VarStmt{Name: tokenName, Initializer: initializer}, nil
+	return ast.VarStmt{Span: parser.span(keyword), Name: tokenName, Initializer: initializer}, nil
 
 }
 
@@ -96,9 +234,13 @@ func (parser *Parser) varDeclaration() (ast.Stmt, error) {
 // If not, it assumes the statement is an expression statement and parses it
 // accordingly. Returns an error if parsing fails at any stage.
 func (parser *Parser) statement() (ast.Stmt, error) {
+	first := parser.peek()
 	if parser.match(token.IF) {
 		return parser.ifStatement()
 	}
+	if parser.match(token.RETURN) {
+		return parser.returnStatement()
+	}
 	if parser.match(token.PRINT) {
 		printStatement, err := parser.printStatement()
 		if err != nil {
@@ -126,7 +268,10 @@ func (parser *Parser) statement() (ast.Stmt, error) {
 		if parser.loopDepth == 0 {
 			return nil, errors.ExecutionError{
 				Type:    errors.PARSER_ERROR,
+				Code:    errors.BREAK_OUTSIDE_LOOP,
 				Line:    parser.previous().Line,
+				Column:  parser.previous().Column,
+				Length:  len(parser.previous().Lexeme),
 				Where:   parser.previous().Char,
 				Message: "'break' not inside the loop",
 			}
@@ -141,7 +286,10 @@ func (parser *Parser) statement() (ast.Stmt, error) {
 		if parser.loopDepth == 0 {
 			return nil, errors.ExecutionError{
 				Type:    errors.PARSER_ERROR,
+				Code:    errors.CONTINUE_OUTSIDE_LOOP,
 				Line:    parser.previous().Line,
+				Column:  parser.previous().Column,
+				Length:  len(parser.previous().Lexeme),
 				Where:   parser.previous().Char,
 				Message: "'continue' not inside the loop",
 			}
@@ -158,42 +306,69 @@ func (parser *Parser) statement() (ast.Stmt, error) {
 		if err != nil {
 			return nil, err
 		}
-		return ast.Block{Statements: statementsBlock}, nil
+		return ast.Block{Span: parser.span(first), Statements: statementsBlock}, nil
 	}
 	// It must be an expression statement
 	expressionStmt, err := parser.expression()
 	if err != nil {
 		return nil, err
 	}
-	return ast.ExpressionStmt{Expression: expressionStmt}, nil
+	_, err = parser.consume(token.SEMICOLON, "Expect ';' after expression.")
+	if err != nil {
+		return nil, err
+	}
+	return ast.ExpressionStmt{Span: parser.span(first), Expression: expressionStmt}, nil
+}
+
+// returnStatement parses a 'return' statement. The returned value is
+// optional; a bare 'return;' hands nil back to the caller.
+func (parser *Parser) returnStatement() (ast.Stmt, error) {
+	keyword := parser.previous()
+	var value ast.Expr
+	if !parser.check(token.SEMICOLON) {
+		var err error
+		value, err = parser.expression()
+		if err != nil {
+			return nil, err
+		}
+	}
+	_, err := parser.consume(token.SEMICOLON, "Expect ';' after return value.")
+	if err != nil {
+		return nil, err
+	}
+	return ast.ReturnStmt{Span: parser.span(keyword), Keyword: keyword, Value: value}, nil
 }
 
 // breakStatement parses a 'break' statement in the source code.
 // It expects a terminating semicolon after the 'break' keyword.
 // Returns an AST node representing the break statement or an error if parsing fails.
 func (parser *Parser) breakStatement() (ast.Stmt, error) {
+	keyword := parser.previous()
 	_, err := parser.consume(token.SEMICOLON, "Expect ';' at the end of the break statement.")
 	if err != nil {
 		return nil, err
 	}
-	return ast.BreakStmt{}, nil
+	return ast.BreakStmt{Span: parser.span(keyword)}, nil
 }
 
 // continueStatement parses a 'continue' statement in the source code.
 // It expects a terminating semicolon after the 'continue' keyword.
 // Returns an AST node representing the continue statement or an error if parsing fails.
 func (parser *Parser) continueStatement() (ast.Stmt, error) {
+	keyword := parser.previous()
 	_, err := parser.consume(token.SEMICOLON, "Expect ';' at the end of the Continue statement.'")
 	if err != nil {
 		return nil, err
 	}
-	return ast.ContinueStmt{}, nil
+	return ast.ContinueStmt{Span: parser.span(keyword)}, nil
 }
 
 // forStatement Parses an for statement and then converts that
 // in a while statement. It "desugars" the for loop back into a
-// while loop.
+// while loop. The nodes made up for it span the whole for statement,
+// except the statement running the increment, which spans the increment.
 func (parser *Parser) forStatement() (ast.Stmt, error) {
+	keyword := parser.previous()
 	_, err := parser.consume(token.LEFT_PAREN, "Except '(' aftger 'for'.")
 	if err != nil {
 		return nil, err
@@ -228,11 +403,16 @@ func (parser *Parser) forStatement() (ast.Stmt, error) {
 	}
 
 	var increment ast.Expr = nil
+	var incrementSpan ast.Span
 	if !parser.check(token.RIGHT_PAREN) {
+		first := parser.peek()
 		increment, err = parser.expression()
 		if err != nil {
 			return nil, err
 		}
+		incrementSpan = parser.span(first)
+		// Older scripts terminate the increment with a ';' as well
+		parser.match(token.SEMICOLON)
 	}
 
 	_, err = parser.consume(
@@ -249,12 +429,15 @@ func (parser *Parser) forStatement() (ast.Stmt, error) {
 	}
 
 	// De-sugaring begins here
+	span := parser.span(keyword)
 	if increment != nil {
 		// for(var i = 0; i < 10;)
 		body = ast.Block{
+			Span: span,
 			Statements: []ast.Stmt{
 				body,
 				ast.ExpressionStmt{
+					Span:       incrementSpan,
 					Expression: increment,
 				},
 			},
@@ -263,11 +446,13 @@ func (parser *Parser) forStatement() (ast.Stmt, error) {
 
 	if condition == nil {
 		condition = ast.Literal{
+			Span:  span,
 			Value: true,
 		}
 	}
 
 	body = ast.WhileStmt{
+		Span:      span,
 		Condition: condition,
 		Body:      body,
 	}
@@ -275,6 +460,7 @@ func (parser *Parser) forStatement() (ast.Stmt, error) {
 	// variable will be initialised in the block
 	if initialiser != nil {
 		body = ast.Block{
+			Span: span,
 			Statements: []ast.Stmt{
 				initialiser,
 				body,
@@ -285,6 +471,7 @@ func (parser *Parser) forStatement() (ast.Stmt, error) {
 }
 
 func (parser *Parser) whileStatement() (ast.Stmt, error) {
+	keyword := parser.previous()
 	_, err := parser.consume(
 		token.LEFT_PAREN,
 		"Expect '(' after 'while'",
@@ -309,10 +496,11 @@ func (parser *Parser) whileStatement() (ast.Stmt, error) {
 		return nil, err
 	}
 	parser.loopDepth -= 1
-	return ast.WhileStmt{Condition: expr, Body: body}, nil
+	return ast.WhileStmt{Span: parser.span(keyword), Condition: expr, Body: body}, nil
 
 }
 func (parser *Parser) ifStatement() (ast.Stmt, error) {
+	keyword := parser.previous()
 	_, err := parser.consume(token.LEFT_PAREN, "Expect '(' after 'if'.")
 	if err != nil {
 		return nil, err
@@ -340,7 +528,7 @@ func (parser *Parser) ifStatement() (ast.Stmt, error) {
 		}
 	}
 
-	return ast.IfStmt{Condition: condition, ThenBranch: thenBranch, ElseBranch: elseBranch}, nil
+	return ast.IfStmt{Span: parser.span(keyword), Condition: condition, ThenBranch: thenBranch, ElseBranch: elseBranch}, nil
 
 }
 
@@ -367,6 +555,7 @@ func (parser *Parser) block() ([]ast.Stmt, error) {
 // Returns an abstract syntax tree (AST) node representing the print statement
 // or an error if parsing fails.
 func (parser *Parser) printStatement() (ast.Stmt, error) {
+	keyword := parser.previous()
 	value, err := parser.expression()
 	if err != nil {
 		return nil, err
@@ -375,7 +564,7 @@ func (parser *Parser) printStatement() (ast.Stmt, error) {
 	if err != nil {
 		return nil, err
 	}
-	return ast.PrintStmt{Expression: value}, nil
+	return ast.PrintStmt{Span: parser.span(keyword), Expression: value}, nil
 
 }
 
@@ -393,10 +582,12 @@ func (parser *Parser) expression() (ast.Expr, error) {
 // assignment parses an assignment expression from the input tokens.
 // It first parses an equality expression. If the next token is an assignment operator ('='),
 // it recursively parses the right-hand side as another assignment expression.
-// If the left-hand side is a variable, it constructs an Assign AST node.
+// If the left-hand side is a variable, it constructs an Assign AST node; if it is
+// a property access, it constructs a Set AST node, or an Increment one for '++'.
 // Otherwise, it returns a parser error indicating an invalid assignment target.
 // Returns the constructed assignment expression or an error if parsing fails.
 func (parser *Parser) assignment() (ast.Expr, error) {
+	first := parser.peek()
 	expr, err := parser.or()
 	if err != nil {
 		return nil, err
@@ -404,29 +595,33 @@ func (parser *Parser) assignment() (ast.Expr, error) {
 
 	if parser.match(token.INC) {
 
+		// x++ is desugared into x = x ++ 1. The made up assignment and
+		// addition span x++, and the 1 spans the operator
+		span := parser.span(first)
+		operator := parser.previous()
 		variable, isVariable := expr.(ast.Variable)
 		if isVariable {
-			operator := parser.previous()
-			_, err = parser.consume(token.SEMICOLON, "Expect ';' at end of the expression")
-			if err != nil {
-				return nil, err
-			}
-
-			return ast.Assign{Name: variable.Name,
+			return ast.Assign{Span: span, Name: variable.Name, Binding: ast.NewBinding(variable.Name),
 				Value: ast.Binary{
-					Left: ast.Variable{
-						Name: variable.Name,
-					},
+					Span:     span,
+					Left:     variable,
 					Operator: operator,
 					Right: ast.Literal{
+						Span:  parser.span(operator),
 						Value: float64(1),
 					},
 				}}, nil
 		}
+		if get, isGet := expr.(ast.Get); isGet {
+			return ast.Increment{Span: span, Object: get.Object, Name: get.Name, Operator: operator}, nil
+		}
 
 		return nil, errors.ExecutionError{
 			Type:    errors.PARSER_ERROR,
+			Code:    errors.INVALID_ASSIGNMENT_TARGET,
 			Line:    parser.previous().Line,
+			Column:  parser.previous().Column,
+			Length:  len(parser.previous().Lexeme),
 			Where:   parser.previous().Char,
 			Message: fmt.Sprintf("Unexpected token '%v'", parser.previous().Lexeme),
 		}
@@ -440,15 +635,17 @@ func (parser *Parser) assignment() (ast.Expr, error) {
 		}
 		variable, isInstanceOfVariable := expr.(ast.Variable)
 		if isInstanceOfVariable {
-			_, err = parser.consume(token.SEMICOLON, "Expect ';' at end of the expression")
-			if err != nil {
-				return nil, err
-			}
-			return ast.Assign{Name: variable.Name, Value: value}, nil
+			return ast.Assign{Span: parser.span(first), Name: variable.Name, Value: value, Binding: ast.NewBinding(variable.Name)}, nil
+		}
+		if get, isGet := expr.(ast.Get); isGet {
+			return ast.Set{Span: parser.span(first), Object: get.Object, Name: get.Name, Value: value}, nil
 		}
 		return nil, errors.ExecutionError{
 			Type:    errors.PARSER_ERROR,
+			Code:    errors.INVALID_ASSIGNMENT_TARGET,
 			Line:    equals.Line,
+			Column:  equals.Column,
+			Length:  len(equals.Lexeme),
 			Where:   equals.Char,
 			Message: fmt.Sprintf("Unexpected token '%v'", equals.Lexeme),
 		}
@@ -457,6 +654,7 @@ func (parser *Parser) assignment() (ast.Expr, error) {
 }
 
 func (parser *Parser) or() (ast.Expr, error) {
+	first := parser.peek()
 	expr, err := parser.and()
 	if err != nil {
 		return nil, err
@@ -468,13 +666,14 @@ func (parser *Parser) or() (ast.Expr, error) {
 		if err != nil {
 			return nil, err
 		}
-		expr = ast.Logical{Left: expr, Operator: operator, Right: right}
+		expr = ast.Logical{Span: parser.span(first), Left: expr, Operator: operator, Right: right}
 	}
 	return expr, nil
 
 }
 
 func (parser *Parser) and() (ast.Expr, error) {
+	first := parser.peek()
 	expr, err := parser.equality()
 	if err != nil {
 		return nil, err
@@ -483,8 +682,9 @@ func (parser *Parser) and() (ast.Expr, error) {
 		operator := parser.previous()
 		right, err := parser.equality()
 		if err != nil {
-			expr = ast.Logical{Left: expr, Operator: operator, Right: right}
+			return nil, err
 		}
+		expr = ast.Logical{Span: parser.span(first), Left: expr, Operator: operator, Right: right}
 	}
 	return expr, nil
 }
@@ -495,6 +695,7 @@ func (parser *Parser) and() (ast.Expr, error) {
 // node with the operator and the right-hand side expression.
 // Returns the constructed expression or an error if parsing fails.
 func (parser *Parser) equality() (ast.Expr, error) {
+	first := parser.peek()
 	expr, err := parser.comparison()
 	if err != nil {
 		return nil, err
@@ -505,7 +706,7 @@ func (parser *Parser) equality() (ast.Expr, error) {
 		if err != nil {
 			return nil, err
 		}
-		expr = ast.Binary{Left: expr, Operator: operator, Right: right}
+		expr = ast.Binary{Span: parser.span(first), Left: expr, Operator: operator, Right: right}
 	}
 	return expr, nil
 }
@@ -517,6 +718,7 @@ func (parser *Parser) equality() (ast.Expr, error) {
 // a binary expression node. The process repeats for chained comparisons.
 // Returns the constructed expression or an error if parsing fails.
 func (parser *Parser) comparison() (ast.Expr, error) {
+	first := parser.peek()
 	expr, err := parser.term()
 	if err != nil {
 		return nil, err
@@ -527,7 +729,7 @@ func (parser *Parser) comparison() (ast.Expr, error) {
 		if err != nil {
 			return nil, err
 		}
-		expr = ast.Binary{Left: expr, Operator: operator, Right: right}
+		expr = ast.Binary{Span: parser.span(first), Left: expr, Operator: operator, Right: right}
 	}
 	return expr, nil
 }
@@ -537,6 +739,7 @@ func (parser *Parser) comparison() (ast.Expr, error) {
 // operators. The method first parses a factor and then checks for any subsequent
 // addition or subtraction operators, combining them into a binary expression tree.
 func (parser *Parser) term() (ast.Expr, error) {
+	first := parser.peek()
 	expr, err := parser.factor()
 	if err != nil {
 		return nil, err
@@ -547,7 +750,7 @@ func (parser *Parser) term() (ast.Expr, error) {
 		if err != nil {
 			return nil, err
 		}
-		expr = ast.Binary{Left: expr, Operator: operator, Right: right}
+		expr = ast.Binary{Span: parser.span(first), Left: expr, Operator: operator, Right: right}
 	}
 	return expr, nil
 }
@@ -559,6 +762,7 @@ func (parser *Parser) term() (ast.Expr, error) {
 // Binary AST node with the left operand, operator, and right operand.
 // Returns the resulting expression or an error if parsing fails.
 func (parser *Parser) factor() (ast.Expr, error) {
+	first := parser.peek()
 	expr, err := parser.unary()
 	if err != nil {
 		return nil, err
@@ -569,7 +773,7 @@ func (parser *Parser) factor() (ast.Expr, error) {
 		if err != nil {
 			return nil, err
 		}
-		expr = ast.Binary{Left: expr, Operator: operator, Right: right}
+		expr = ast.Binary{Span: parser.span(first), Left: expr, Operator: operator, Right: right}
 	}
 	return expr, nil
 }
@@ -579,7 +783,7 @@ func (parser *Parser) factor() (ast.Expr, error) {
 // If the current token matches a unary operator, this function recursively
 // parses the operand and constructs an abstract syntax tree (AST) node
 // representing the unary expression. If no unary operator is matched, it
-// delegates parsing to the primary expression parser.
+// delegates parsing to the call expression parser.
 //
 // Returns an AST expression node representing the unary expression or
 // primary expression, along with any error encountered during parsing.
@@ -590,20 +794,85 @@ func (parser *Parser) unary() (ast.Expr, error) {
 		if err != nil {
 			return nil, err
 		}
-		return ast.Unary{Operator: operator, Right: right}, nil
+		return ast.Unary{Span: parser.span(operator), Operator: operator, Right: right}, nil
+	}
+	call, err := parser.call()
+	if err != nil {
+		return nil, err
+	}
+	return call, nil
+}
+
+// call parses a primary expression followed by any number of argument
+// lists and property accesses, so that chains such as f(1)(2) and
+// a.b().c are handled as well.
+func (parser *Parser) call() (ast.Expr, error) {
+	first := parser.peek()
+	expr, err := parser.primary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if parser.match(token.LEFT_PAREN) {
+			expr, err = parser.finishCall(expr, first)
+			if err != nil {
+				return nil, err
+			}
+		} else if parser.match(token.DOT) {
+			name, err := parser.consume(token.IDENTIFIER, "Expect property name after '.'.")
+			if err != nil {
+				return nil, err
+			}
+			expr = ast.Get{Span: parser.span(first), Object: expr, Name: name}
+		} else {
+			break
+		}
 	}
-	primary, err := parser.primary()
+	return expr, nil
+}
+
+// finishCall parses the comma separated arguments of a call whose '('
+// has already been consumed and wraps the callee in a Call node. The
+// closing parenthesis is kept on the node to report runtime errors, and
+// first is the first token of the callee.
+func (parser *Parser) finishCall(callee ast.Expr, first token.Token) (ast.Expr, error) {
+	arguments := make([]ast.Expr, 0)
+	if !parser.check(token.RIGHT_PAREN) {
+		for {
+			if len(arguments) >= maxArguments {
+				return nil, errors.ExecutionError{
+					Type:    errors.PARSER_ERROR,
+					Code:    errors.TOO_MANY_ARGUMENTS,
+					Line:    parser.peek().Line,
+					Column:  parser.peek().Column,
+					Length:  len(parser.peek().Lexeme),
+					Where:   parser.peek().Char,
+					Message: fmt.Sprintf("Can't have more than %d arguments.", maxArguments),
+				}
+			}
+			argument, err := parser.expression()
+			if err != nil {
+				return nil, err
+			}
+			arguments = append(arguments, argument)
+			if !parser.match(token.COMMA) {
+				break
+			}
+		}
+	}
+	paren, err := parser.consume(token.RIGHT_PAREN, "Expect ')' after arguments.")
 	if err != nil {
 		return nil, err
 	}
-	return primary, nil
+	return ast.Call{Span: parser.span(first), Callee: callee, Paren: paren, Arguments: arguments}, nil
 }
 
 // primary parses a primary expression in the source code and returns an
 // abstract syntax tree (AST) representation of the expression or an error
 // if parsing fails. A primary expression can be a literal value (e.g., true,
-// false, nil, numbers, or strings), a grouped expression enclosed in
-// parentheses, or an unexpected token.
+// false, nil, numbers, or strings), a variable, 'this', a 'super' method
+// access, a grouped expression
+// enclosed in parentheses, or an unexpected token.
 //
 // The function uses a switch statement to match the current token against
 // various cases, such as boolean literals, nil, numeric or string literals,
@@ -616,39 +885,68 @@ func (parser *Parser) unary() (ast.Expr, error) {
 func (parser *Parser) primary() (ast.Expr, error) {
 	switch {
 	case parser.match(token.FALSE):
-		return ast.Literal{Value: false}, nil
+		return ast.Literal{Span: parser.span(parser.previous()), Value: false}, nil
 	case parser.match(token.TRUE):
-		return ast.Literal{Value: true}, nil
+		return ast.Literal{Span: parser.span(parser.previous()), Value: true}, nil
 	case parser.match(token.NIL):
-		return ast.Literal{Value: nil}, nil
+		return ast.Literal{Span: parser.span(parser.previous()), Value: nil}, nil
 	case parser.match(token.NUMBER, token.STRING):
-		return ast.Literal{Value: parser.previous().Literal}, nil
+		return ast.Literal{Span: parser.span(parser.previous()), Value: parser.previous().Literal}, nil
+	case parser.match(token.SUPER):
+		keyword := parser.previous()
+		_, err := parser.consume(token.DOT, "Expect '.' after 'super'.")
+		if err != nil {
+			return nil, err
+		}
+		method, err := parser.consume(token.IDENTIFIER, "Expect superclass method name.")
+		if err != nil {
+			return nil, err
+		}
+		return ast.Super{Span: parser.span(keyword), Keyword: keyword, Method: method, Binding: ast.NewBinding(keyword)}, nil
+	case parser.match(token.THIS):
+		keyword := parser.previous()
+		return ast.This{Span: parser.span(keyword), Keyword: keyword, Binding: ast.NewBinding(keyword)}, nil
 	case parser.match(token.IDENTIFIER):
-		return ast.Variable{Name: parser.previous()}, nil
+		name := parser.previous()
+		return ast.Variable{Span: parser.span(name), Name: name, Binding: ast.NewBinding(name)}, nil
 	case parser.match(token.LEFT_PAREN):
-		expr, e := parser.expression()
-		if e != nil {
-			fmt.Println(fmt.Errorf("%v", e))
+		paren := parser.previous()
+		expr, err := parser.expression()
+		if err != nil {
+			return nil, err
 		}
-		_, err := parser.consume(token.RIGHT_PAREN, "Expect ')' after expression.")
+		_, err = parser.consume(token.RIGHT_PAREN, "Expect ')' after expression.")
 		if err != nil {
 			return nil, err
 		}
-		return ast.Grouping{Expression: expr}, nil
+		return ast.Grouping{Span: parser.span(paren), Expression: expr}, nil
 	default:
 		// We probaby don't want to panic here because we are syncing the parser
 		// We will catch it in parser.match(token.LEFT_PAREN) and report it back to
 		// the stdout
 		peek := parser.peek()
+		message := fmt.Sprintf("Unexpected token '%v'", peek.Lexeme)
+		if peek.Type == token.EOF {
+			message = "Unexpected end of input."
+		}
 		return nil, errors.ExecutionError{
 			Type:    errors.PARSER_ERROR,
-			Line:    parser.previous().Line,
-			Where:   parser.previous().Char,
-			Message: fmt.Sprintf("Unexpected token '%v'", peek.Lexeme),
+			Code:    errors.EXPECTED_EXPRESSION,
+			Line:    peek.Line,
+			Column:  peek.Column,
+			Length:  len(peek.Lexeme),
+			Where:   peek.Char,
+			Message: message,
 		}
 	}
 }
 
+// span returns the span of a node from its first token to the last token
+// consumed.
+func (parser *Parser) span(first token.Token) ast.Span {
+	return ast.TokenSpan(parser.File, first, parser.previous())
+}
+
 // Comparison parses a comparison expression from the list of tokens.
 // It returns the root node of the abstract syntax tree.
 func (parser *Parser) match(types ...token.TokenType) bool {
@@ -701,14 +999,51 @@ func (parser *Parser) consume(type_ token.TokenType, message string) (token.Toke
 	if parser.check(type_) {
 		return parser.advance(), nil
 	}
+	peek := parser.peek()
+	if parser.Current > 0 && (peek.Type == token.EOF || peek.Line > parser.previous().Line) {
+		// The missing token belongs at the end of the line before, which is
+		// where the user will go looking for it
+		previous := parser.previous()
+		return token.Token{}, errors.ExecutionError{
+			Type:    errors.PARSER_ERROR,
+			Code:    missingTokenCode(type_),
+			Line:    previous.Line,
+			Column:  previous.Column + utf8.RuneCountInString(previous.Lexeme),
+			Length:  1,
+			Where:   previous.Char + len(previous.Lexeme),
+			Message: message,
+		}
+	}
 	return token.Token{}, errors.ExecutionError{
 		Type:    errors.PARSER_ERROR,
-		Line:    parser.peek().Line,
-		Where:   parser.peek().Char,
+		Code:    missingTokenCode(type_),
+		Line:    peek.Line,
+		Column:  peek.Column,
+		Length:  len(peek.Lexeme),
+		Where:   peek.Char,
 		Message: message,
 	}
 }
 
+// missingTokenCodes are the codes of the errors about a missing token,
+// by the type of token that was expected.
+var missingTokenCodes = map[token.TokenType]errors.Code{
+	token.SEMICOLON:   errors.MISSING_SEMICOLON,
+	token.LEFT_PAREN:  errors.MISSING_PARENTHESIS,
+	token.RIGHT_PAREN: errors.MISSING_PARENTHESIS,
+	token.LEFT_BRACE:  errors.MISSING_BRACE,
+	token.RIGHT_BRACE: errors.MISSING_BRACE,
+	token.IDENTIFIER:  errors.EXPECTED_NAME,
+	token.DOT:         errors.MISSING_DOT,
+}
+
+func missingTokenCode(type_ token.TokenType) errors.Code {
+	if code, ok := missingTokenCodes[type_]; ok {
+		return code
+	}
+	return errors.INTERNAL_ERROR
+}
+
 // This function synchronizes the parser by skipping tokens until it finds
 // a token of a certain type or reaches the end of the input.
 // It is used to recover from errors in the parsing process.
@@ -718,10 +1053,11 @@ func (parser *Parser) consume(type_ token.TokenType, message string) (token.Toke
 func (parser *Parser) synchronize() {
 	parser.advance()
 	for !parser.isAtEnd() {
-		switch parser.previous().Type {
-		case token.SEMICOLON: //until we reach the sync point
+		if parser.previous().Type == token.SEMICOLON { //until we reach the sync point
 			return
-		case token.CLASS, token.FUN, token.VAR, token.IF, token.WHILE, token.PRINT:
+		}
+		switch parser.peek().Type {
+		case token.CLASS, token.FUN, token.VAR, token.FOR, token.IF, token.WHILE, token.PRINT, token.RETURN:
 			return
 		default:
 			parser.advance()
@@ -1,80 +1,300 @@
+// Package printer turns abstract syntax trees back into text, to see what
+// the parser made of a program.
 package printer
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/go-interpreter/internal/ast"
 )
 
-// PrintAST is a visitor implementation for converting abstract syntax trees into their string representation.
+// Format is the way a tree is printed.
+type Format string
+
+const (
+	// TREE_FORMAT prints a node per line, its children indented below it.
+	TREE_FORMAT Format = "tree"
+	// SEXPR_FORMAT prints a Lisp-style S-expression per statement.
+	SEXPR_FORMAT Format = "sexpr"
+)
+
+// Print prints statements in the given format.
+func Print(format Format, stmts []ast.Stmt) string {
+	if format == SEXPR_FORMAT {
+		return (&PrintSExpr{}).PrintStmts(stmts)
+	}
+	return (&PrintAST{}).PrintStmts(stmts)
+}
+
+// PrintAST is a visitor implementation for converting abstract syntax trees
+// into an indented tree: a line per node, with the children of a node
+// indented below it. Children whose role is not obvious from their position,
+// like the branches of an if, are listed under a label.
 type PrintAST struct {
+	out         strings.Builder
 	indentation int
 }
 
-// VisitBinary generates a string representation of a binary
-// expression by recursively visiting its left, right, and operator.
-func (printer *PrintAST) VisitBinary(node ast.Binary) (interface{}, error) {
-	printer.indentation++
-	left, _ := node.Left.Accept(printer)
-	right, _ := node.Right.Accept(printer)
-	printer.indentation--
-	return fmt.Sprintf("%sBinary(\n%s\n%s%s %s\n%s\n%s)",
-		strings.Repeat("  ", printer.indentation),
-		left.(string),
-		strings.Repeat("  ", printer.indentation+1),
-		node.Operator.Lexeme,
-		strings.Repeat("  ", printer.indentation+1),
-		right.(string),
-		strings.Repeat("  ", printer.indentation),
-	), nil
-}
-
-// VisitGrouping creates a string representation of a grouping expression by recursively visiting its inner expression.
-func (printer *PrintAST) VisitGrouping(node ast.Grouping) (interface{}, error) {
-	printer.indentation++
-	expr, _ := node.Expression.Accept(printer)
-	printer.indentation--
-	return fmt.Sprintf("%sGrouping(\n%s\n%s)",
-		strings.Repeat("  ", printer.indentation),
-		expr.(string),
-		strings.Repeat("  ", printer.indentation),
-	), nil
+// Print returns the tree of an expression.
+func (printer *PrintAST) Print(expression ast.Expr) string {
+	printer.out.Reset()
+	printer.expr(expression)
+	return printer.out.String()
 }
 
-// VisitLiteral generates a string representation of a literal expression based on its value.
-func (printer *PrintAST) VisitLiteral(node ast.Literal) (interface{}, error) {
-	return fmt.Sprintf("%sLiteral(%v)",
-		strings.Repeat("  ", printer.indentation),
-		node.Value,
-	), nil
+// PrintStmts returns the trees of statements, one after the other.
+func (printer *PrintAST) PrintStmts(stmts []ast.Stmt) string {
+	printer.out.Reset()
+	for _, stmt := range stmts {
+		printer.stmt(stmt)
+	}
+	return printer.out.String()
 }
 
-// VisitUnary generates a string representation of a unary expression by visiting its operator and operand.
-func (printer *PrintAST) VisitUnary(node ast.Unary) (interface{}, error) {
-	printer.indentation++
-	right, _ := node.Right.Accept(printer)
-	printer.indentation--
-	return fmt.Sprintf("%sUnary(\n%s%s\n%s)",
-		strings.Repeat("  ", printer.indentation),
-		strings.Repeat("  ", printer.indentation+1),
-		node.Operator.Lexeme,
-		right.(string),
-	), nil
+// line writes a node at the current indentation.
+func (printer *PrintAST) line(format string, args ...any) {
+	printer.out.WriteString(strings.Repeat("  ", printer.indentation))
+	fmt.Fprintf(&printer.out, format, args...)
+	printer.out.WriteByte('\n')
 }
 
-func (printer *PrintAST) VisitVariable(node ast.Variable) (interface{}, error) {
+// children writes the nodes printed by print one level deeper.
+func (printer *PrintAST) children(print func()) {
 	printer.indentation++
-	variableName, _ := node.Accept(printer)
+	print()
 	printer.indentation--
-	return fmt.Sprintf("%Variable(\n%s%s\n)",
-		strings.Repeat("  ", printer.indentation),
-		strings.Repeat("  ", printer.indentation+1),
-		variableName,
-	), nil
 }
 
-func (printer *PrintAST) Print(expression ast.Expr) string {
-	expr, _ := expression.Accept(printer)
-	return expr.(string)
+// labelled writes a label and, below it, the nodes printed by print.
+func (printer *PrintAST) labelled(label string, print func()) {
+	printer.children(func() {
+		printer.line("%s:", label)
+		printer.children(print)
+	})
+}
+
+func (printer *PrintAST) expr(expression ast.Expr) {
+	expression.Accept(printer)
+}
+
+func (printer *PrintAST) stmt(stmt ast.Stmt) {
+	stmt.Accept(printer)
+}
+
+// VisitBinary prints the operator of a binary expression and its operands below it.
+func (printer *PrintAST) VisitBinary(node ast.Binary) (any, error) {
+	printer.line("Binary %s", node.Operator.Lexeme)
+	printer.children(func() {
+		printer.expr(node.Left)
+		printer.expr(node.Right)
+	})
+	return nil, nil
+}
+
+// VisitLogical prints a logical expression like a binary one.
+func (printer *PrintAST) VisitLogical(node ast.Logical) (any, error) {
+	printer.line("Logical %s", node.Operator.Lexeme)
+	printer.children(func() {
+		printer.expr(node.Left)
+		printer.expr(node.Right)
+	})
+	return nil, nil
+}
+
+// VisitGrouping prints the parenthesised expression below the grouping.
+func (printer *PrintAST) VisitGrouping(node ast.Grouping) (any, error) {
+	printer.line("Grouping")
+	printer.children(func() { printer.expr(node.Expression) })
+	return nil, nil
+}
+
+// VisitLiteral prints the value of a literal as it would be written in a program.
+func (printer *PrintAST) VisitLiteral(node ast.Literal) (any, error) {
+	printer.line("Literal %s", literal(node.Value))
+	return nil, nil
+}
+
+// VisitUnary prints the operator of a unary expression and its operand below it.
+func (printer *PrintAST) VisitUnary(node ast.Unary) (any, error) {
+	printer.line("Unary %s", node.Operator.Lexeme)
+	printer.children(func() { printer.expr(node.Right) })
+	return nil, nil
+}
+
+// VisitVariable prints the name of a variable.
+func (printer *PrintAST) VisitVariable(node ast.Variable) (any, error) {
+	printer.line("Variable %s", node.Name.Lexeme)
+	return nil, nil
+}
+
+// VisitAssign prints the name assigned to and the value below it.
+func (printer *PrintAST) VisitAssign(node ast.Assign) (any, error) {
+	printer.line("Assign %s", node.Name.Lexeme)
+	printer.children(func() { printer.expr(node.Value) })
+	return nil, nil
+}
+
+// VisitCall prints the callee and the arguments of a call.
+func (printer *PrintAST) VisitCall(node ast.Call) (any, error) {
+	printer.line("Call")
+	printer.labelled("callee", func() { printer.expr(node.Callee) })
+	if len(node.Arguments) > 0 {
+		printer.labelled("arguments", func() {
+			for _, argument := range node.Arguments {
+				printer.expr(argument)
+			}
+		})
+	}
+	return nil, nil
+}
+
+// VisitGet prints the name of a property and the object it is read from below it.
+func (printer *PrintAST) VisitGet(node ast.Get) (any, error) {
+	printer.line("Get %s", node.Name.Lexeme)
+	printer.children(func() { printer.expr(node.Object) })
+	return nil, nil
+}
+
+// VisitSet prints the name of a property, the object it is set on and the value.
+func (printer *PrintAST) VisitSet(node ast.Set) (any, error) {
+	printer.line("Set %s", node.Name.Lexeme)
+	printer.labelled("object", func() { printer.expr(node.Object) })
+	printer.labelled("value", func() { printer.expr(node.Value) })
+	return nil, nil
+}
+
+// VisitIncrement prints the name of a property and the object it is
+// incremented on below it.
+func (printer *PrintAST) VisitIncrement(node ast.Increment) (any, error) {
+	printer.line("Increment %s", node.Name.Lexeme)
+	printer.children(func() { printer.expr(node.Object) })
+	return nil, nil
+}
+
+// VisitThis prints a this expression.
+func (printer *PrintAST) VisitThis(node ast.This) (any, error) {
+	printer.line("This")
+	return nil, nil
+}
+
+// VisitSuper prints the name of the superclass method.
+func (printer *PrintAST) VisitSuper(node ast.Super) (any, error) {
+	printer.line("Super %s", node.Method.Lexeme)
+	return nil, nil
+}
+
+// VisitExpressionStmt prints the expression of the statement below it.
+func (printer *PrintAST) VisitExpressionStmt(node ast.ExpressionStmt) (any, error) {
+	printer.line("Expression")
+	printer.children(func() { printer.expr(node.Expression) })
+	return nil, nil
+}
+
+// VisitPrintStmt prints the printed expression below the statement.
+func (printer *PrintAST) VisitPrintStmt(node ast.PrintStmt) (any, error) {
+	printer.line("Print")
+	printer.children(func() { printer.expr(node.Expression) })
+	return nil, nil
+}
+
+// VisitVarStmt prints the name of a variable and its initializer, if any, below it.
+func (printer *PrintAST) VisitVarStmt(node ast.VarStmt) (any, error) {
+	printer.line("Var %s", node.Name.Lexeme)
+	if node.Initializer != nil {
+		printer.children(func() { printer.expr(node.Initializer) })
+	}
+	return nil, nil
+}
+
+// VisitBlockStmt prints the statements of a block below it.
+func (printer *PrintAST) VisitBlockStmt(node ast.Block) (any, error) {
+	printer.line("Block")
+	printer.children(func() {
+		for _, stmt := range node.Statements {
+			printer.stmt(stmt)
+		}
+	})
+	return nil, nil
+}
+
+// VisitIfStmt prints the condition and the branches of an if statement.
+func (printer *PrintAST) VisitIfStmt(node ast.IfStmt) (any, error) {
+	printer.line("If")
+	printer.labelled("condition", func() { printer.expr(node.Condition) })
+	printer.labelled("then", func() { printer.stmt(node.ThenBranch) })
+	if node.ElseBranch != nil {
+		printer.labelled("else", func() { printer.stmt(node.ElseBranch) })
+	}
+	return nil, nil
+}
+
+// VisitWhileStmt prints the condition and the body of a loop. For loops are
+// printed as the while loops the parser turns them into.
+func (printer *PrintAST) VisitWhileStmt(node ast.WhileStmt) (any, error) {
+	printer.line("While")
+	printer.labelled("condition", func() { printer.expr(node.Condition) })
+	printer.labelled("body", func() { printer.stmt(node.Body) })
+	return nil, nil
+}
+
+// VisitBreakStmt prints a break statement.
+func (printer *PrintAST) VisitBreakStmt() (any, error) {
+	printer.line("Break")
+	return nil, nil
+}
+
+// VisitContinueStmt prints a continue statement.
+func (printer *PrintAST) VisitContinueStmt() (any, error) {
+	printer.line("Continue")
+	return nil, nil
+}
+
+// VisitFunctionStmt prints the signature of a function and its body below it.
+func (printer *PrintAST) VisitFunctionStmt(node ast.FunctionStmt) (any, error) {
+	printer.line("Function %s(%s)", node.Name.Lexeme, strings.Join(node.ParamNames(), ", "))
+	printer.children(func() {
+		for _, stmt := range node.Body {
+			printer.stmt(stmt)
+		}
+	})
+	return nil, nil
+}
+
+// VisitReturnStmt prints the returned value, if any, below the statement.
+func (printer *PrintAST) VisitReturnStmt(node ast.ReturnStmt) (any, error) {
+	printer.line("Return")
+	if node.Value != nil {
+		printer.children(func() { printer.expr(node.Value) })
+	}
+	return nil, nil
+}
+
+// VisitClassStmt prints the name and superclass of a class and its methods below it.
+func (printer *PrintAST) VisitClassStmt(node ast.ClassStmt) (any, error) {
+	if node.Superclass != nil {
+		printer.line("Class %s < %s", node.Name.Lexeme, node.Superclass.Name.Lexeme)
+	} else {
+		printer.line("Class %s", node.Name.Lexeme)
+	}
+	printer.children(func() {
+		for _, method := range node.Methods {
+			printer.stmt(method)
+		}
+	})
+	return nil, nil
+}
+
+// literal writes a literal value the way it is written in a program.
+func literal(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "nil"
+	case string:
+		return strconv.Quote(value)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
 }
@@ -0,0 +1,172 @@
+package printer
+
+import (
+	"strings"
+
+	"github.com/go-interpreter/internal/ast"
+)
+
+// PrintSExpr is a visitor implementation for converting abstract syntax trees
+// into Lisp-style S-expressions, like (print (+ 1 (* 2 3))). Operators,
+// keywords and literals stand for themselves.
+type PrintSExpr struct{}
+
+// Print returns the S-expression of an expression.
+func (printer *PrintSExpr) Print(expression ast.Expr) string {
+	return printer.expr(expression)
+}
+
+// PrintStmt returns the S-expression of a statement.
+func (printer *PrintSExpr) PrintStmt(stmt ast.Stmt) string {
+	return printer.stmt(stmt)
+}
+
+// PrintStmts returns the S-expressions of statements, one per line.
+func (printer *PrintSExpr) PrintStmts(stmts []ast.Stmt) string {
+	var out strings.Builder
+	for _, stmt := range stmts {
+		out.WriteString(printer.stmt(stmt))
+		out.WriteByte('\n')
+	}
+	return out.String()
+}
+
+func (printer *PrintSExpr) expr(expression ast.Expr) string {
+	result, _ := expression.Accept(printer)
+	return result.(string)
+}
+
+func (printer *PrintSExpr) stmt(stmt ast.Stmt) string {
+	result, _ := stmt.Accept(printer)
+	return result.(string)
+}
+
+func (printer *PrintSExpr) stmts(stmts []ast.Stmt) []string {
+	parts := make([]string, 0, len(stmts))
+	for _, stmt := range stmts {
+		parts = append(parts, printer.stmt(stmt))
+	}
+	return parts
+}
+
+// parenthesize puts a head and its parts, already printed, in a list.
+func parenthesize(head string, parts ...string) string {
+	return "(" + strings.Join(append([]string{head}, parts...), " ") + ")"
+}
+
+func (printer *PrintSExpr) VisitBinary(node ast.Binary) (any, error) {
+	return parenthesize(node.Operator.Lexeme, printer.expr(node.Left), printer.expr(node.Right)), nil
+}
+
+func (printer *PrintSExpr) VisitLogical(node ast.Logical) (any, error) {
+	return parenthesize(node.Operator.Lexeme, printer.expr(node.Left), printer.expr(node.Right)), nil
+}
+
+func (printer *PrintSExpr) VisitGrouping(node ast.Grouping) (any, error) {
+	return parenthesize("group", printer.expr(node.Expression)), nil
+}
+
+func (printer *PrintSExpr) VisitLiteral(node ast.Literal) (any, error) {
+	return literal(node.Value), nil
+}
+
+func (printer *PrintSExpr) VisitUnary(node ast.Unary) (any, error) {
+	return parenthesize(node.Operator.Lexeme, printer.expr(node.Right)), nil
+}
+
+func (printer *PrintSExpr) VisitVariable(node ast.Variable) (any, error) {
+	return node.Name.Lexeme, nil
+}
+
+func (printer *PrintSExpr) VisitAssign(node ast.Assign) (any, error) {
+	return parenthesize("=", node.Name.Lexeme, printer.expr(node.Value)), nil
+}
+
+func (printer *PrintSExpr) VisitCall(node ast.Call) (any, error) {
+	parts := []string{printer.expr(node.Callee)}
+	for _, argument := range node.Arguments {
+		parts = append(parts, printer.expr(argument))
+	}
+	return parenthesize("call", parts...), nil
+}
+
+func (printer *PrintSExpr) VisitGet(node ast.Get) (any, error) {
+	return parenthesize(".", printer.expr(node.Object), node.Name.Lexeme), nil
+}
+
+func (printer *PrintSExpr) VisitSet(node ast.Set) (any, error) {
+	return parenthesize("=", parenthesize(".", printer.expr(node.Object), node.Name.Lexeme), printer.expr(node.Value)), nil
+}
+
+func (printer *PrintSExpr) VisitIncrement(node ast.Increment) (any, error) {
+	return parenthesize("++", parenthesize(".", printer.expr(node.Object), node.Name.Lexeme)), nil
+}
+
+func (printer *PrintSExpr) VisitThis(node ast.This) (any, error) {
+	return "this", nil
+}
+
+func (printer *PrintSExpr) VisitSuper(node ast.Super) (any, error) {
+	return parenthesize("super", node.Method.Lexeme), nil
+}
+
+func (printer *PrintSExpr) VisitExpressionStmt(node ast.ExpressionStmt) (any, error) {
+	return parenthesize(";", printer.expr(node.Expression)), nil
+}
+
+func (printer *PrintSExpr) VisitPrintStmt(node ast.PrintStmt) (any, error) {
+	return parenthesize("print", printer.expr(node.Expression)), nil
+}
+
+func (printer *PrintSExpr) VisitVarStmt(node ast.VarStmt) (any, error) {
+	if node.Initializer == nil {
+		return parenthesize("var", node.Name.Lexeme), nil
+	}
+	return parenthesize("var", node.Name.Lexeme, printer.expr(node.Initializer)), nil
+}
+
+func (printer *PrintSExpr) VisitBlockStmt(node ast.Block) (any, error) {
+	return parenthesize("block", printer.stmts(node.Statements)...), nil
+}
+
+func (printer *PrintSExpr) VisitIfStmt(node ast.IfStmt) (any, error) {
+	if node.ElseBranch == nil {
+		return parenthesize("if", printer.expr(node.Condition), printer.stmt(node.ThenBranch)), nil
+	}
+	return parenthesize("if", printer.expr(node.Condition), printer.stmt(node.ThenBranch), printer.stmt(node.ElseBranch)), nil
+}
+
+func (printer *PrintSExpr) VisitWhileStmt(node ast.WhileStmt) (any, error) {
+	return parenthesize("while", printer.expr(node.Condition), printer.stmt(node.Body)), nil
+}
+
+func (printer *PrintSExpr) VisitBreakStmt() (any, error) {
+	return "(break)", nil
+}
+
+func (printer *PrintSExpr) VisitContinueStmt() (any, error) {
+	return "(continue)", nil
+}
+
+func (printer *PrintSExpr) VisitFunctionStmt(node ast.FunctionStmt) (any, error) {
+	parts := append([]string{node.Name.Lexeme, "(" + strings.Join(node.ParamNames(), " ") + ")"}, printer.stmts(node.Body)...)
+	return parenthesize("fun", parts...), nil
+}
+
+func (printer *PrintSExpr) VisitReturnStmt(node ast.ReturnStmt) (any, error) {
+	if node.Value == nil {
+		return "(return)", nil
+	}
+	return parenthesize("return", printer.expr(node.Value)), nil
+}
+
+func (printer *PrintSExpr) VisitClassStmt(node ast.ClassStmt) (any, error) {
+	parts := []string{node.Name.Lexeme}
+	if node.Superclass != nil {
+		parts = append(parts, parenthesize("<", node.Superclass.Name.Lexeme))
+	}
+	for _, method := range node.Methods {
+		parts = append(parts, printer.stmt(method))
+	}
+	return parenthesize("class", parts...), nil
+}
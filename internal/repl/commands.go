@@ -0,0 +1,142 @@
+package repl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/go-interpreter/internal/analysis"
+	"github.com/go-interpreter/internal/interpreter"
+	"github.com/go-interpreter/internal/printer"
+	"github.com/go-interpreter/internal/scanner"
+)
+
+// errQuit is returned by the ':quit' command to end the session.
+var errQuit = errors.New("quit")
+
+// command is a meta-command of the interactive session, typed as ':name'.
+// run gets whatever follows the name on the line.
+type command struct {
+	usage string
+	help  string
+	run   func(repl *Repl, arg string, out io.Writer) error
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"env":    {":env", "show the bindings of the global environment", envCommand},
+		"tokens": {":tokens <source>", "show the tokens the scanner produces for source", tokensCommand},
+		"ast":    {":ast <source>", "show the parse tree of source", astCommand},
+		"load":   {":load <file>", "run a file in the current session", loadCommand},
+		"reset":  {":reset", "forget everything defined so far", resetCommand},
+		"save":   {":save <file>", "write the inputs accepted so far to file", saveCommand},
+		"help":   {":help", "show this list", helpCommand},
+		"quit":   {":quit", "end the session", quitCommand},
+	}
+}
+
+// runCommand runs a line starting with ':'.
+func (repl *Repl) runCommand(line string, out io.Writer) error {
+	name, arg, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(line), ":"), " ")
+	cmd, ok := commands[name]
+	if !ok {
+		return fmt.Errorf("unknown command ':%s' (try :help)", name)
+	}
+	return cmd.run(repl, strings.TrimSpace(arg), out)
+}
+
+func envCommand(repl *Repl, _ string, out io.Writer) error {
+	values := repl.interpreter.Globals().Values
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(out, "%s = %s\n", name, echo(values[name]))
+	}
+	return nil
+}
+
+func tokensCommand(_ *Repl, source string, out io.Writer) error {
+	if source == "" {
+		return errors.New("usage: :tokens <source>")
+	}
+	tokenScanner := scanner.NewTokenScanner(source)
+	tokens, scanErrors := tokenScanner.ScanTokens()
+	for _, tok := range tokens {
+		fmt.Fprintln(out, tok.Describe())
+	}
+	for _, scanError := range scanErrors {
+		fmt.Fprintln(out, scanError)
+	}
+	return nil
+}
+
+func astCommand(_ *Repl, source string, out io.Writer) error {
+	if source == "" {
+		return errors.New("usage: :ast <source>")
+	}
+	stmts, diagnostics := analysis.Parse(analysis.Program{Source: terminate(source)})
+	if len(diagnostics) > 0 {
+		for _, diagnostic := range diagnostics {
+			fmt.Fprintln(out, diagnostic)
+		}
+		return nil
+	}
+	fmt.Fprint(out, printer.Print(printer.TREE_FORMAT, stmts))
+	return nil
+}
+
+func loadCommand(repl *Repl, path string, out io.Writer) error {
+	if path == "" {
+		return errors.New("usage: :load <file>")
+	}
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	repl.eval(string(source), out)
+	return nil
+}
+
+func resetCommand(repl *Repl, _ string, _ io.Writer) error {
+	repl.interpreter = interpreter.NewInterpreter()
+	repl.interpreter.SetInput(repl.input)
+	repl.history = nil
+	repl.transcript.Reset()
+	repl.line = 1
+	return nil
+}
+
+func saveCommand(repl *Repl, path string, out io.Writer) error {
+	if path == "" {
+		return errors.New("usage: :save <file>")
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(repl.history, "")), 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "saved %d inputs to %s\n", len(repl.history), path)
+	return nil
+}
+
+func helpCommand(_ *Repl, _ string, out io.Writer) error {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(out, "%-18s %s\n", commands[name].usage, commands[name].help)
+	}
+	return nil
+}
+
+func quitCommand(*Repl, string, io.Writer) error {
+	return errQuit
+}
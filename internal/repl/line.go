@@ -0,0 +1,90 @@
+package repl
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// lineReader reads one line of input at a time, showing the prompt when
+// there is someone to show it to.
+type lineReader interface {
+	ReadLine(prompt string) (string, error)
+}
+
+// newLineReader picks a line editor when in is a terminal, and a plain
+// buffered reader otherwise (piped input, tests).
+func newLineReader(in io.Reader, out io.Writer) lineReader {
+	if file, ok := in.(*os.File); ok && term.IsTerminal(int(file.Fd())) {
+		terminal := term.NewTerminal(struct {
+			io.Reader
+			io.Writer
+		}{in, out}, "")
+		return &terminalReader{fd: int(file.Fd()), terminal: terminal}
+	}
+	return &plainReader{reader: bufio.NewReader(in)}
+}
+
+// terminalReader gives the user line editing and history. The terminal is
+// only kept in raw mode while a line is being read, so that the program's
+// own output is not mangled.
+type terminalReader struct {
+	fd       int
+	terminal *term.Terminal
+}
+
+func (reader *terminalReader) ReadLine(prompt string) (string, error) {
+	state, err := term.MakeRaw(reader.fd)
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(reader.fd, state)
+	if width, height, err := term.GetSize(reader.fd); err == nil && width > 0 {
+		_ = reader.terminal.SetSize(width, height)
+	}
+	reader.terminal.SetPrompt(prompt)
+	return reader.terminal.ReadLine()
+}
+
+// plainReader reads lines without echoing prompts.
+type plainReader struct {
+	reader *bufio.Reader
+}
+
+func (reader *plainReader) ReadLine(string) (string, error) {
+	line, err := reader.reader.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+// sessionInput is what readLine reads in an interactive session: the lines
+// of the session itself, from the reader the inputs come from, so that
+// neither takes a line buffered for the other.
+type sessionInput struct {
+	reader  lineReader
+	pending []byte
+}
+
+func (input *sessionInput) Read(p []byte) (int, error) {
+	if len(input.pending) == 0 {
+		line, err := input.reader.ReadLine("")
+		if err != nil {
+			return 0, err
+		}
+		input.pending = []byte(line + "\n")
+	}
+	n := copy(p, input.pending)
+	input.pending = input.pending[n:]
+	return n, nil
+}
+
+// IsTerminal reports whether w is a terminal, which is when colours are used.
+func IsTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(file.Fd()))
+}
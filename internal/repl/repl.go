@@ -2,66 +2,350 @@ package repl
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"strconv"
+	"strings"
 
 	// "github.com/go-interpreter/internal/interpreter"
+	"github.com/go-interpreter/internal/analysis"
+	"github.com/go-interpreter/internal/ast"
+	"github.com/go-interpreter/internal/compiler"
+	"github.com/go-interpreter/internal/errors"
 	"github.com/go-interpreter/internal/interpreter"
 	"github.com/go-interpreter/internal/scanner"
+	"github.com/go-interpreter/internal/token"
+	"github.com/go-interpreter/internal/vm"
+)
+
+// Backend selects how a parsed program gets executed.
+type Backend string
+
+const (
+	TREE_WALKER Backend = "tree"
+	BYTECODE_VM Backend = "vm"
+)
 
-	parser "github.com/go-interpreter/internal/parser"
+const (
+	PROMPT              = "> "
+	CONTINUATION_PROMPT = "... "
 )
 
-// TODO(ME): NEED TO MAKE BETTER. COMING SOON.
+// Repl runs programs, either whole files or line by line in an interactive
+// session. HadError records that a file had syntax or resolution errors and
+// was not run; HadRuntimeError that it was run but failed. Format is how
+// the diagnostics of files are written out, and Errors where to. Output is
+// where files print to, and Input what they read with readLine; an
+// interactive session prints to its own output, and readLine reads the
+// next line of its input. A single Interpreter is kept for the lifetime of
+// the Repl, so whatever one input defines is still there for the next one.
+// input is what the interpreter reads, handed to it again on ':reset'.
+// transcript holds every input scanned so far and line is the line the
+// next input starts at, so that a diagnostic points into the right input
+// even when it is raised by a function defined several inputs ago.
+// history keeps the inputs that ran without errors, for ':save'.
 type Repl struct {
-	HadError bool
+	HadError        bool
+	HadRuntimeError bool
+	Backend         Backend
+	Format          errors.Format
+	Errors          io.Writer
+	Output          io.Writer
+	Input           io.Reader
+	interpreter     interpreter.Interpreter
+	input           io.Reader
+	line            int
+	transcript      strings.Builder
+	history         []string
 }
 
 func NewRepl() *Repl {
-	return &Repl{}
+	return &Repl{interpreter: interpreter.NewInterpreter(), line: 1, Format: errors.TEXT_FORMAT, Errors: os.Stderr,
+		Output: os.Stdout, Input: os.Stdin}
+}
 
+// setInput hands the interpreter what readLine reads.
+func (repl *Repl) setInput(in io.Reader) {
+	repl.input = in
+	repl.interpreter.SetInput(in)
 }
 
-func (repl *Repl) LoadProgram(path string) {
-	if repl.HadError {
-		panic("Program had errors!")
+// Start reads inputs from in until it runs out, and evaluates each of them
+// with the tree-walking interpreter. Lines are collected until all braces
+// and parentheses are closed, so blocks can span several lines. The value
+// of an expression statement is echoed to out. A line starting with ':'
+// is a meta-command, see ':help'.
+func (repl *Repl) Start(in io.Reader, out io.Writer) error {
+	reader := newLineReader(in, out)
+	repl.setInput(&sessionInput{reader: reader})
+	var source strings.Builder
+	for {
+		prompt := PROMPT
+		if source.Len() > 0 {
+			prompt = CONTINUATION_PROMPT
+		}
+		line, err := reader.ReadLine(prompt)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if source.Len() == 0 && strings.HasPrefix(strings.TrimSpace(line), ":") {
+			err := repl.runCommand(line, out)
+			if err == errQuit {
+				return nil
+			}
+			if err != nil {
+				fmt.Fprintf(out, "error: %v\n", err)
+			}
+			continue
+		}
+		source.WriteString(line)
+		source.WriteString("\n")
+		if strings.TrimSpace(source.String()) == "" {
+			source.Reset()
+			continue
+		}
+		if !isComplete(source.String()) {
+			continue
+		}
+		repl.eval(source.String(), out)
+		source.Reset()
 	}
-	err := repl.loadProgram(path)
-	if err != nil {
-		panic(err)
+}
+
+// eval runs one complete input of the interactive session, and remembers
+// it if it ran without errors.
+func (repl *Repl) eval(source string, out io.Writer) {
+	source = terminate(source)
+	if repl.runInput(source, out) {
+		repl.history = append(repl.history, source)
+	}
+}
+
+// terminate lets a lone expression be typed without its semicolon.
+func terminate(source string) string {
+	trimmed := strings.TrimSpace(source)
+	if !strings.HasSuffix(trimmed, ";") && !strings.HasSuffix(trimmed, "}") {
+		return trimmed + ";\n"
 	}
+	return source
 }
 
-// Runfile We want to scan the tokens in a file
-// We want to scan correct tokens defined
-// in out hypothetical language
-func (repl *Repl) loadProgram(path string) error {
+// runInput scans, parses, resolves and interprets one input, and reports
+// whether all of that went without errors. Output that 'print' leaves in
+// the middle of a line is ended before anything else is written, so it
+// never runs into an echoed value, a diagnostic or the next prompt.
+func (repl *Repl) runInput(source string, out io.Writer) bool {
+	repl.transcript.WriteString(source)
+	renderer := errors.Renderer{File: "<repl>", Source: repl.transcript.String(), Color: IsTerminal(out)}
+	output := &lineWriter{out: out}
+	defer output.endLine()
+	repl.interpreter.SetFile(renderer.File)
+	repl.interpreter.SetOutput(output)
+
+	program := analysis.Program{File: renderer.File, Source: source, Line: repl.line}
+	repl.line += strings.Count(source, "\n")
+	stmts, diagnostics := analysis.Analyse(program, &repl.interpreter)
+	if len(diagnostics) > 0 {
+		for _, diagnostic := range diagnostics {
+			fmt.Fprint(out, renderer.Render(diagnostic))
+		}
+		return false
+	}
+	for _, stmt := range stmts {
+		if exprStmt, ok := stmt.(ast.ExpressionStmt); ok {
+			value, err := repl.interpreter.Evaluate(exprStmt.Expression)
+			output.endLine()
+			if err != nil {
+				renderError(out, renderer, err)
+				return false
+			}
+			fmt.Fprintln(out, echo(value))
+			continue
+		}
+		if err := repl.interpreter.Execute(stmt); err != nil {
+			output.endLine()
+			renderError(out, renderer, err)
+			return false
+		}
+	}
+	return true
+}
+
+// lineWriter remembers whether what was written through it last stopped
+// in the middle of a line.
+type lineWriter struct {
+	out     io.Writer
+	midLine bool
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	if len(p) > 0 {
+		w.midLine = p[len(p)-1] != '\n'
+	}
+	return w.out.Write(p)
+}
+
+// endLine ends the line the output stopped in, if any.
+func (w *lineWriter) endLine() {
+	if w.midLine {
+		fmt.Fprintln(w)
+	}
+}
+
+// isComplete reports whether every brace and parenthesis opened in source
+// has been closed.
+func isComplete(source string) bool {
+	tokenScanner := scanner.NewTokenScanner(source)
+	for tokenScanner.Current < len(tokenScanner.Source) {
+		tokenScanner.Start = tokenScanner.Current
+		// Errors are reported once the input is actually run
+		_ = tokenScanner.ScanToken()
+	}
+	depth := 0
+	for _, tok := range tokenScanner.Tokens {
+		switch tok.Type {
+		case token.LEFT_PAREN, token.LEFT_BRACE:
+			depth++
+		case token.RIGHT_PAREN, token.RIGHT_BRACE:
+			depth--
+		}
+	}
+	return depth <= 0
+}
+
+// echo formats a value the way the interactive session shows it. Unlike
+// print, it makes nil and strings recognisable.
+func echo(value interpreter.Value) string {
+	switch {
+	case value.IsNil():
+		return "nil"
+	case value.IsString():
+		return strconv.Quote(value.AsString())
+	default:
+		return value.String()
+	}
+}
 
-	// We are reading the program text here
-	file, err := os.ReadFile(path)
+// LoadProgram runs the program in the file at path, like Run. It returns an
+// error when the file cannot be read, or when the program has errors or
+// fails; the diagnostics themselves have been reported to Errors by then.
+func (repl *Repl) LoadProgram(path string) error {
+	source, err := os.ReadFile(path)
 	if err != nil {
-		_ = fmt.Errorf("an error occured during the program file read: %s", err)
-		return err
+		return fmt.Errorf("cannot read the program: %w", err)
+	}
+	repl.Run(path, string(source))
+	if repl.HadError {
+		return fmt.Errorf("%s has errors", path)
+	}
+	if repl.HadRuntimeError {
+		return fmt.Errorf("%s failed", path)
 	}
-	// We store that byte file for scanning
-	tokenScanner := scanner.NewTokenScanner(string(file))
-	repl.run(&tokenScanner)
 	return nil
 }
 
-// This your token scanner for the program
-func (repl *Repl) run(tokenScanner *scanner.TokenScanner) {
-	_ = tokenScanner.ScanTokens()
-	p := parser.NewParser(tokenScanner.Tokens)
-	inter := interpreter.NewInterpreter()
-	parsedStatments := p.Parse()
-	err := inter.Interpret(parsedStatments)
+// Run scans, parses and resolves the program, and only executes it when
+// none of that produced diagnostics. Diagnostics and runtime errors go to
+// Errors, in the chosen Format, and are remembered in HadError and
+// HadRuntimeError. name is the file the diagnostics refer to.
+func (repl *Repl) Run(name string, source string) {
+	reporter := repl.reporter(name, source)
+	defer repl.flush(reporter)
+	repl.interpreter.SetFile(name)
+	repl.interpreter.SetOutput(repl.Output)
+	repl.setInput(repl.Input)
+	stmts, ok := repl.analyse(reporter, name, source)
+	if !ok {
+		return
+	}
+	var err error
+	if repl.Backend == BYTECODE_VM {
+		script, ok := repl.compile(reporter, stmts)
+		if !ok {
+			return
+		}
+		machine := vm.NewVM(repl.Output)
+		machine.SetInput(repl.Input)
+		machine.SetFile(name)
+		err = machine.Interpret(script)
+	} else {
+		err = repl.interpreter.Interpret(stmts)
+	}
 	if err != nil {
-		repl.HadError = true
-		fmt.Println(err)
+		repl.HadRuntimeError = true
+		reporter.Report(asDiagnostic(err))
 	}
-	if repl.HadError {
+}
+
+// Check reports the diagnostics of the program, like Run, without running it.
+func (repl *Repl) Check(name string, source string) {
+	reporter := repl.reporter(name, source)
+	defer repl.flush(reporter)
+	repl.analyse(reporter, name, source)
+}
+
+// analyse scans, parses and resolves the program, and reports whether that
+// went without diagnostics.
+func (repl *Repl) analyse(reporter errors.Reporter, name string, source string) ([]ast.Stmt, bool) {
+	// The resolver also guards the bytecode backend against scoping mistakes
+	stmts, diagnostics := analysis.Analyse(analysis.Program{File: name, Source: source}, &repl.interpreter)
+	if repl.report(reporter, diagnostics) {
+		return nil, false
+	}
+	return stmts, true
+}
+
+func (repl *Repl) reporter(name string, source string) errors.Reporter {
+	return errors.NewReporter(repl.Format, repl.Errors, name, source, IsTerminal(repl.Errors))
+}
+
+func (repl *Repl) flush(reporter errors.Reporter) {
+	if err := reporter.Flush(); err != nil {
+		fmt.Fprintf(repl.Errors, "error: %v\n", err)
+	}
+}
+
+// report hands diagnostics to the reporter and reports whether there were any.
+func (repl *Repl) report(reporter errors.Reporter, diagnostics []errors.ExecutionError) bool {
+	for _, diagnostic := range diagnostics {
+		reporter.Report(diagnostic)
+	}
+	if len(diagnostics) == 0 {
+		return false
+	}
+	repl.HadError = true
+	return true
+}
+
+// renderError prints an error, quoting the source when it is a diagnostic.
+func renderError(out io.Writer, renderer errors.Renderer, err error) {
+	if diagnostic, ok := errors.AsExecutionError(err); ok {
+		fmt.Fprint(out, renderer.Render(diagnostic))
 		return
 	}
-	// astPrinter := printer.PrintAST{}
-	// astPrinter.Print(expr)
+	fmt.Fprintf(out, "error: %v\n", err)
+}
+
+// compile compiles the program for the virtual machine. A program the
+// compiler rejects never ran, so its error counts as a program error
+// rather than a runtime one.
+func (repl *Repl) compile(reporter errors.Reporter, stmts []ast.Stmt) (*compiler.Function, bool) {
+	script, err := compiler.NewCompiler().Compile(stmts)
+	if err != nil {
+		repl.report(reporter, []errors.ExecutionError{asDiagnostic(err)})
+		return nil, false
+	}
+	return script, true
+}
+
+// asDiagnostic turns an error into a diagnostic the reporter can write out.
+func asDiagnostic(err error) errors.ExecutionError {
+	diagnostic, ok := errors.AsExecutionError(err)
+	if !ok {
+		diagnostic = errors.ExecutionError{Type: errors.PROGRAM_ERROR, Message: err.Error()}
+	}
+	return diagnostic
 }
@@ -0,0 +1,352 @@
+package resolver
+
+import (
+	"github.com/go-interpreter/internal/ast"
+	"github.com/go-interpreter/internal/errors"
+	"github.com/go-interpreter/internal/interpreter"
+	"github.com/go-interpreter/internal/token"
+)
+
+/*
+	The resolver is a static pass that runs after parsing and before
+	interpreting. It walks the whole tree once and, for every variable
+	reference, works out how many scopes lie between the use and the
+	declaration. The interpreter then jumps straight to that Environment
+	instead of searching the chain by name at every access.
+	Global variables are left unresolved; they are looked up dynamically.
+*/
+
+// FunctionType tracks what kind of function body is being resolved.
+type FunctionType int
+
+const (
+	NONE_FUNCTION FunctionType = iota
+	FUNCTION
+	METHOD
+	INITIALIZER
+)
+
+// ClassType tracks whether we are inside a class, and whether it has a superclass.
+type ClassType int
+
+const (
+	NONE_CLASS ClassType = iota
+	CLASS
+	SUBCLASS
+)
+
+// Resolver computes the scope depth of every local variable reference
+// and hands it to the interpreter. It also reports scoping mistakes
+// that can be detected without running the program.
+type Resolver struct {
+	interpreter     *interpreter.Interpreter
+	scopes          []map[string]bool
+	currentFunction FunctionType
+	currentClass    ClassType
+	Errors          []errors.ExecutionError
+}
+
+// NewResolver creates a resolver that records its bindings in the given interpreter.
+func NewResolver(interpreter *interpreter.Interpreter) *Resolver {
+	return &Resolver{
+		interpreter:     interpreter,
+		scopes:          make([]map[string]bool, 0),
+		currentFunction: NONE_FUNCTION,
+		currentClass:    NONE_CLASS,
+	}
+}
+
+// Resolve resolves a list of statements and returns every error found
+// along the way. The program must not be interpreted if any are returned.
+func (r *Resolver) Resolve(stmts []ast.Stmt) []errors.ExecutionError {
+	r.resolveStmts(stmts)
+	return r.Errors
+}
+
+func (r *Resolver) VisitBlockStmt(stmt ast.Block) (any, error) {
+	r.beginScope()
+	r.resolveStmts(stmt.Statements)
+	r.endScope()
+	return nil, nil
+}
+
+func (r *Resolver) VisitClassStmt(stmt ast.ClassStmt) (any, error) {
+	enclosingClass := r.currentClass
+	r.currentClass = CLASS
+	defer func() { r.currentClass = enclosingClass }()
+
+	r.declare(stmt.Name)
+	r.define(stmt.Name)
+
+	if stmt.Superclass != nil {
+		if stmt.Name.Lexeme == stmt.Superclass.Name.Lexeme {
+			r.error(stmt.Superclass.Name, errors.INHERIT_FROM_SELF, "A class can't inherit from itself.")
+		}
+		r.currentClass = SUBCLASS
+		r.resolveExpr(*stmt.Superclass)
+		r.beginScope()
+		r.peekScope()["super"] = true
+	}
+
+	r.beginScope()
+	r.peekScope()["this"] = true
+	for _, method := range stmt.Methods {
+		declaration := METHOD
+		if method.Name.Lexeme == "init" {
+			declaration = INITIALIZER
+		}
+		r.resolveFunction(method, declaration)
+	}
+	r.endScope()
+
+	if stmt.Superclass != nil {
+		r.endScope()
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitExpressionStmt(stmt ast.ExpressionStmt) (any, error) {
+	r.resolveExpr(stmt.Expression)
+	return nil, nil
+}
+
+// VisitFunctionStmt defines the name before resolving the body so that
+// a function can refer to itself recursively.
+func (r *Resolver) VisitFunctionStmt(stmt ast.FunctionStmt) (any, error) {
+	r.declare(stmt.Name)
+	r.define(stmt.Name)
+	r.resolveFunction(stmt, FUNCTION)
+	return nil, nil
+}
+
+func (r *Resolver) VisitIfStmt(stmt ast.IfStmt) (any, error) {
+	r.resolveExpr(stmt.Condition)
+	r.resolveStmt(stmt.ThenBranch)
+	if stmt.ElseBranch != nil {
+		r.resolveStmt(stmt.ElseBranch)
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitPrintStmt(stmt ast.PrintStmt) (any, error) {
+	r.resolveExpr(stmt.Expression)
+	return nil, nil
+}
+
+func (r *Resolver) VisitReturnStmt(stmt ast.ReturnStmt) (any, error) {
+	if r.currentFunction == NONE_FUNCTION {
+		r.error(stmt.Keyword, errors.TOP_LEVEL_RETURN, "Can't return from top-level code.")
+	}
+	if stmt.Value != nil {
+		if r.currentFunction == INITIALIZER {
+			r.error(stmt.Keyword, errors.RETURN_FROM_INITIALIZER, "Can't return a value from an initializer.")
+		}
+		r.resolveExpr(stmt.Value)
+	}
+	return nil, nil
+}
+
+// VisitVarStmt splits binding into declaring and defining, so that an
+// initializer referring to the variable being declared can be caught.
+func (r *Resolver) VisitVarStmt(stmt ast.VarStmt) (any, error) {
+	r.declare(stmt.Name)
+	if stmt.Initializer != nil {
+		r.resolveExpr(stmt.Initializer)
+	}
+	r.define(stmt.Name)
+	return nil, nil
+}
+
+func (r *Resolver) VisitWhileStmt(stmt ast.WhileStmt) (any, error) {
+	r.resolveExpr(stmt.Condition)
+	r.resolveStmt(stmt.Body)
+	return nil, nil
+}
+
+func (r *Resolver) VisitBreakStmt() (any, error) {
+	return nil, nil
+}
+
+func (r *Resolver) VisitContinueStmt() (any, error) {
+	return nil, nil
+}
+
+func (r *Resolver) VisitAssign(expr ast.Assign) (any, error) {
+	r.resolveExpr(expr.Value)
+	r.resolveLocal(expr.Binding, expr.Name)
+	return nil, nil
+}
+
+func (r *Resolver) VisitBinary(expr ast.Binary) (any, error) {
+	r.resolveExpr(expr.Left)
+	r.resolveExpr(expr.Right)
+	return nil, nil
+}
+
+func (r *Resolver) VisitCall(expr ast.Call) (any, error) {
+	r.resolveExpr(expr.Callee)
+	for _, argument := range expr.Arguments {
+		r.resolveExpr(argument)
+	}
+	return nil, nil
+}
+
+// VisitGet only resolves the object; properties are looked up dynamically.
+func (r *Resolver) VisitGet(expr ast.Get) (any, error) {
+	r.resolveExpr(expr.Object)
+	return nil, nil
+}
+
+func (r *Resolver) VisitGrouping(expr ast.Grouping) (any, error) {
+	r.resolveExpr(expr.Expression)
+	return nil, nil
+}
+
+func (r *Resolver) VisitLiteral(expr ast.Literal) (any, error) {
+	return nil, nil
+}
+
+func (r *Resolver) VisitLogical(expr ast.Logical) (any, error) {
+	r.resolveExpr(expr.Left)
+	r.resolveExpr(expr.Right)
+	return nil, nil
+}
+
+func (r *Resolver) VisitSet(expr ast.Set) (any, error) {
+	r.resolveExpr(expr.Value)
+	r.resolveExpr(expr.Object)
+	return nil, nil
+}
+
+// VisitIncrement only resolves the object, like VisitGet.
+func (r *Resolver) VisitIncrement(expr ast.Increment) (any, error) {
+	r.resolveExpr(expr.Object)
+	return nil, nil
+}
+
+func (r *Resolver) VisitSuper(expr ast.Super) (any, error) {
+	if r.currentClass == NONE_CLASS {
+		r.error(expr.Keyword, errors.SUPER_OUTSIDE_CLASS, "Can't use 'super' outside of a class.")
+	} else if r.currentClass != SUBCLASS {
+		r.error(expr.Keyword, errors.SUPER_WITHOUT_SUPERCLASS, "Can't use 'super' in a class with no superclass.")
+	}
+	r.resolveLocal(expr.Binding, expr.Keyword)
+	return nil, nil
+}
+
+func (r *Resolver) VisitThis(expr ast.This) (any, error) {
+	if r.currentClass == NONE_CLASS {
+		r.error(expr.Keyword, errors.THIS_OUTSIDE_CLASS, "Can't use 'this' outside of a class.")
+		return nil, nil
+	}
+	r.resolveLocal(expr.Binding, expr.Keyword)
+	return nil, nil
+}
+
+func (r *Resolver) VisitUnary(expr ast.Unary) (any, error) {
+	r.resolveExpr(expr.Right)
+	return nil, nil
+}
+
+// VisitVariable reports a variable read inside its own initializer: it
+// has been declared in the innermost scope but is not yet defined.
+func (r *Resolver) VisitVariable(expr ast.Variable) (any, error) {
+	if len(r.scopes) > 0 {
+		if defined, declared := r.peekScope()[expr.Name.Lexeme]; declared && !defined {
+			r.error(expr.Name, errors.READ_IN_OWN_INITIALIZER, "Can't read local variable in its own initializer.")
+		}
+	}
+	r.resolveLocal(expr.Binding, expr.Name)
+	return nil, nil
+}
+
+func (r *Resolver) resolveStmts(stmts []ast.Stmt) {
+	for _, stmt := range stmts {
+		r.resolveStmt(stmt)
+	}
+}
+
+func (r *Resolver) resolveStmt(stmt ast.Stmt) {
+	// Statements that failed to parse are left as nil
+	if stmt == nil {
+		return
+	}
+	_, _ = stmt.Accept(r)
+}
+
+func (r *Resolver) resolveExpr(expr ast.Expr) {
+	_, _ = expr.Accept(r)
+}
+
+// resolveFunction resolves a function body in a scope of its own that
+// holds the parameters.
+func (r *Resolver) resolveFunction(function ast.FunctionStmt, functionType FunctionType) {
+	enclosingFunction := r.currentFunction
+	r.currentFunction = functionType
+	defer func() { r.currentFunction = enclosingFunction }()
+
+	r.beginScope()
+	for _, param := range function.Params {
+		r.declare(param)
+		r.define(param)
+	}
+	r.resolveStmts(function.Body)
+	r.endScope()
+}
+
+// resolveLocal walks the scopes from the innermost outwards and records,
+// under the binding of the reference, the number of hops to the scope
+// that declares the name. Names that are not found in any scope are
+// assumed to be global.
+func (r *Resolver) resolveLocal(binding *ast.Binding, name token.Token) {
+	for index := len(r.scopes) - 1; index >= 0; index-- {
+		if _, ok := r.scopes[index][name.Lexeme]; ok {
+			r.interpreter.Resolve(binding, len(r.scopes)-1-index)
+			return
+		}
+	}
+}
+
+func (r *Resolver) beginScope() {
+	r.scopes = append(r.scopes, make(map[string]bool))
+}
+
+func (r *Resolver) endScope() {
+	r.scopes = r.scopes[:len(r.scopes)-1]
+}
+
+func (r *Resolver) peekScope() map[string]bool {
+	return r.scopes[len(r.scopes)-1]
+}
+
+// declare adds the name to the innermost scope, marked as not ready yet.
+func (r *Resolver) declare(name token.Token) {
+	if len(r.scopes) == 0 {
+		return
+	}
+	scope := r.peekScope()
+	if _, ok := scope[name.Lexeme]; ok {
+		r.error(name, errors.ALREADY_DECLARED, "Already a variable with this name in this scope.")
+	}
+	scope[name.Lexeme] = false
+}
+
+// define marks the name as fully initialised and available for use.
+func (r *Resolver) define(name token.Token) {
+	if len(r.scopes) == 0 {
+		return
+	}
+	r.peekScope()[name.Lexeme] = true
+}
+
+func (r *Resolver) error(name token.Token, code errors.Code, message string) {
+	r.Errors = append(r.Errors, errors.ExecutionError{
+		Type:    errors.RESOLVER_ERROR,
+		Code:    code,
+		Line:    name.Line,
+		Column:  name.Column,
+		Length:  len(name.Lexeme),
+		Where:   name.Char,
+		Message: message,
+	})
+}
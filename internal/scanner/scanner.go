@@ -3,6 +3,8 @@ package scanner
 import (
 	"fmt"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/go-interpreter/internal/errors"
 	"github.com/go-interpreter/internal/token"
@@ -23,12 +25,16 @@ import (
 // TokenScanner Basically a TokenScanner that keeps the information
 // of the scanned tokens in a stack. It also tracks the state of the
 // scanning; such as the start and current(or it could be the end if current = EOF)
+// and the line being scanned, counting from 1.
+// Comments are not tokens the parser sees, but they are kept in Comments,
+// in order, so that source can be printed back with them.
 type TokenScanner struct {
-	Source  string
-	Tokens  []token.Token
-	Start   int
-	Current int
-	Line    int
+	Source   string
+	Tokens   []token.Token
+	Comments []token.Token
+	Start    int
+	Current  int
+	Line     int
 }
 
 // NewTokenScanner Init This initializes the source code
@@ -37,21 +43,25 @@ func NewTokenScanner(source string) TokenScanner {
 		Source:  source,
 		Start:   0,
 		Current: 0,
-		Line:    0,
+		Line:    1,
 	}
 }
 
 // ScanTokens scans the source code and produces a list of tokens based on the language grammar.
-func (scanner *TokenScanner) ScanTokens() []token.Token {
+// Scanning goes on after an error so that every problem in the source is reported at once;
+// the tokens are only meant to be parsed when no errors came back.
+func (scanner *TokenScanner) ScanTokens() ([]token.Token, []errors.ExecutionError) {
+	var scanErrors []errors.ExecutionError
 	for !scanner.isAtEnd() {
 		scanner.Start = scanner.Current
 		err := scanner.ScanToken()
 		if err != nil {
-			fmt.Println(err)
+			scanErrors = append(scanErrors, err.(errors.ExecutionError))
 		}
 	}
-	scanner.Tokens = append(scanner.Tokens, token.Token{Type: token.EOF, Lexeme: "", Literal: nil, Line: scanner.Line})
-	return scanner.Tokens
+	scanner.Tokens = append(scanner.Tokens, token.Token{Type: token.EOF, Lexeme: "", Literal: nil,
+		Line: scanner.Line, Column: scanner.column(len(scanner.Source)), Char: len(scanner.Source)})
+	return scanner.Tokens, scanErrors
 }
 
 // ScanToken reads the next character in the source and determines the appropriate token type to add to the token list.
@@ -117,6 +127,13 @@ func (scanner *TokenScanner) ScanToken() error {
 			for scanner.peek() != "\n" && !scanner.isAtEnd() {
 				scanner.advance()
 			}
+			scanner.Comments = append(scanner.Comments, token.Token{
+				Type:   token.COMMENT,
+				Lexeme: strings.TrimRight(scanner.Source[scanner.Start:scanner.Current], "\r"),
+				Line:   scanner.Line,
+				Column: scanner.column(scanner.Start),
+				Char:   scanner.Start,
+			})
 		} else {
 			scanner.AddToken(token.SLASH)
 		}
@@ -138,9 +155,12 @@ func (scanner *TokenScanner) ScanToken() error {
 			scanner.identifier()
 		} else {
 			return errors.ExecutionError{Type: errors.SCANNER_ERROR,
+				Code:    errors.UNEXPECTED_CHARACTER,
 				Line:    scanner.Line,
-				Where:   scanner.Current,
-				Message: "Unexpected Error"}
+				Column:  scanner.column(scanner.Start),
+				Length:  1,
+				Where:   scanner.Start,
+				Message: fmt.Sprintf("Unexpected character '%s'.", c)}
 		}
 	}
 	return nil
@@ -151,25 +171,42 @@ func (scanner *TokenScanner) string() error {
 	// Two cases run here:
 	//	1) continue scanning until you find " and you are not at the EOL
 	//  2) did not find " but you are the EOL
+	// The token is reported on the line it starts on, so the lines it
+	// spans are only counted once it has been added.
+	newlines := 0
 	for scanner.peek() != "\"" && !scanner.isAtEnd() {
 		if scanner.peek() == "\n" {
-			scanner.Line++
+			newlines++
 		}
 		scanner.advance()
 	}
+	defer func() { scanner.Line += newlines }()
 	// If we are the EOL, we have an unterminated string
 	if scanner.isAtEnd() {
 		return errors.ExecutionError{Type: errors.SCANNER_ERROR,
+			Code:    errors.UNTERMINATED_STRING,
 			Line:    scanner.Line,
-			Where:   scanner.Current,
-			Message: "Unterminated string",
+			Column:  scanner.column(scanner.Start),
+			Length:  1,
+			Where:   scanner.Start,
+			Message: "Unterminated string.",
+			Hint:    "add a closing '\"' to end the string",
 		}
 	}
 	scanner.advance()
 	value := scanner.Source[scanner.Start+1 : scanner.Current-1]
-	value, err := strconv.Unquote(`"` + value + `"`) // From raw to an actual string
+	// From raw to an actual string. Unquote does not take raw newlines, which
+	// strings are allowed to span, so those are escaped first.
+	value, err := strconv.Unquote(`"` + strings.ReplaceAll(value, "\n", `\n`) + `"`)
 	if err != nil {
-		return err
+		return errors.ExecutionError{Type: errors.SCANNER_ERROR,
+			Code:    errors.INVALID_ESCAPE,
+			Line:    scanner.Line,
+			Column:  scanner.column(scanner.Start),
+			Length:  utf8.RuneCountInString(scanner.Source[scanner.Start:scanner.Current]),
+			Where:   scanner.Start,
+			Message: "Invalid escape sequence in string.",
+		}
 	}
 	scanner.addToken(token.STRING, value)
 	return nil
@@ -249,11 +286,19 @@ func (scanner *TokenScanner) addToken(tokenType token.TokenType, literal any) {
 		Lexeme:  text,
 		Literal: literal,
 		Line:    scanner.Line,
+		Column:  scanner.column(scanner.Start),
 		Char:    scanner.Start,
 	})
 
 }
 
+// column returns the 1-based column of the byte at offset, counted in characters
+// from the start of its line.
+func (scanner *TokenScanner) column(offset int) int {
+	lineStart := strings.LastIndexByte(scanner.Source[:offset], '\n') + 1
+	return utf8.RuneCountInString(scanner.Source[lineStart:offset]) + 1
+}
+
 // advance() - Scans and advances
 func (scanner *TokenScanner) advance() string {
 	char := scanner.Source[scanner.Current]
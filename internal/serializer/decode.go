@@ -0,0 +1,248 @@
+package serializer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-interpreter/internal/ast"
+	"github.com/go-interpreter/internal/token"
+)
+
+// Unmarshal reads statements from their JSON form, as written by Marshal.
+// Every variable reference gets a Binding of its own, as from the parser.
+func Unmarshal(data []byte) ([]ast.Stmt, error) {
+	d := &decoder{}
+	stmts := d.stmts(data)
+	if d.err != nil {
+		return nil, d.err
+	}
+	return stmts, nil
+}
+
+// fields are the fields of a node, still to be decoded.
+type fields map[string]json.RawMessage
+
+// decoder builds nodes from their JSON form. It keeps the first error it
+// runs into and returns zero values from then on, so nodes are decoded
+// without checking every field.
+type decoder struct {
+	err error
+}
+
+func (d *decoder) fail(format string, args ...any) {
+	if d.err == nil {
+		d.err = fmt.Errorf(format, args...)
+	}
+}
+
+func isNull(raw json.RawMessage) bool {
+	return len(raw) == 0 || bytes.Equal(raw, []byte("null"))
+}
+
+// fields decodes an object and returns its kind.
+func (d *decoder) fields(raw json.RawMessage) (string, fields) {
+	if d.err != nil {
+		return "", nil
+	}
+	var node fields
+	if err := json.Unmarshal(raw, &node); err != nil {
+		d.fail("node: %v", err)
+		return "", nil
+	}
+	var kind string
+	if err := json.Unmarshal(node["kind"], &kind); err != nil {
+		d.fail("node without a kind: %s", raw)
+		return "", nil
+	}
+	return kind, node
+}
+
+func (d *decoder) value(raw json.RawMessage, value any) {
+	if d.err != nil || isNull(raw) {
+		return
+	}
+	if err := json.Unmarshal(raw, value); err != nil {
+		d.fail("%v", err)
+	}
+}
+
+func (d *decoder) token(raw json.RawMessage) token.Token {
+	var tok Token
+	d.value(raw, &tok)
+	return token.Token{
+		Type:    tok.Type,
+		Lexeme:  tok.Lexeme,
+		Literal: tok.Literal,
+		Line:    tok.Line,
+		Column:  tok.Column,
+		Char:    tok.Offset,
+	}
+}
+
+func (d *decoder) span(node fields) ast.Span {
+	var span Span
+	d.value(node["span"], &span)
+	return ast.Span{File: span.File, Start: ast.Position(span.Start), End: ast.Position(span.End)}
+}
+
+func (d *decoder) tokens(raw json.RawMessage) []token.Token {
+	var encoded []json.RawMessage
+	d.value(raw, &encoded)
+	tokens := make([]token.Token, 0, len(encoded))
+	for _, tok := range encoded {
+		tokens = append(tokens, d.token(tok))
+	}
+	return tokens
+}
+
+// optionalExpr decodes an expression that may be null.
+func (d *decoder) optionalExpr(raw json.RawMessage) ast.Expr {
+	if isNull(raw) {
+		return nil
+	}
+	return d.expr(raw)
+}
+
+func (d *decoder) expr(raw json.RawMessage) ast.Expr {
+	if isNull(raw) {
+		d.fail("missing expression")
+		return nil
+	}
+	kind, node := d.fields(raw)
+	if d.err != nil {
+		return nil
+	}
+	switch kind {
+	case "Binary":
+		return ast.Binary{Span: d.span(node), Left: d.expr(node["left"]), Operator: d.token(node["operator"]), Right: d.expr(node["right"])}
+	case "Logical":
+		return ast.Logical{Span: d.span(node), Left: d.expr(node["left"]), Operator: d.token(node["operator"]), Right: d.expr(node["right"])}
+	case "Grouping":
+		return ast.Grouping{Span: d.span(node), Expression: d.expr(node["expression"])}
+	case "Literal":
+		var value any
+		d.value(node["value"], &value)
+		return ast.Literal{Span: d.span(node), Value: value}
+	case "Unary":
+		return ast.Unary{Span: d.span(node), Operator: d.token(node["operator"]), Right: d.expr(node["right"])}
+	case "Variable":
+		name := d.token(node["name"])
+		return ast.Variable{Span: d.span(node), Name: name, Binding: ast.NewBinding(name)}
+	case "Assign":
+		name := d.token(node["name"])
+		return ast.Assign{Span: d.span(node), Name: name, Value: d.expr(node["value"]), Binding: ast.NewBinding(name)}
+	case "Call":
+		return ast.Call{Span: d.span(node), Callee: d.expr(node["callee"]), Paren: d.token(node["paren"]), Arguments: d.exprs(node["arguments"])}
+	case "Get":
+		return ast.Get{Span: d.span(node), Object: d.expr(node["object"]), Name: d.token(node["name"])}
+	case "Set":
+		return ast.Set{Span: d.span(node), Object: d.expr(node["object"]), Name: d.token(node["name"]), Value: d.expr(node["value"])}
+	case "Increment":
+		return ast.Increment{Span: d.span(node), Object: d.expr(node["object"]), Name: d.token(node["name"]), Operator: d.token(node["operator"])}
+	case "This":
+		keyword := d.token(node["keyword"])
+		return ast.This{Span: d.span(node), Keyword: keyword, Binding: ast.NewBinding(keyword)}
+	case "Super":
+		keyword := d.token(node["keyword"])
+		return ast.Super{Span: d.span(node), Keyword: keyword, Method: d.token(node["method"]), Binding: ast.NewBinding(keyword)}
+	}
+	d.fail("unknown expression kind %q", kind)
+	return nil
+}
+
+func (d *decoder) exprs(raw json.RawMessage) []ast.Expr {
+	var nodes []json.RawMessage
+	d.value(raw, &nodes)
+	exprs := make([]ast.Expr, 0, len(nodes))
+	for _, node := range nodes {
+		exprs = append(exprs, d.expr(node))
+	}
+	return exprs
+}
+
+// optionalStmt decodes a statement that may be null.
+func (d *decoder) optionalStmt(raw json.RawMessage) ast.Stmt {
+	if isNull(raw) {
+		return nil
+	}
+	return d.stmt(raw)
+}
+
+func (d *decoder) stmt(raw json.RawMessage) ast.Stmt {
+	if isNull(raw) {
+		d.fail("missing statement")
+		return nil
+	}
+	kind, node := d.fields(raw)
+	if d.err != nil {
+		return nil
+	}
+	switch kind {
+	case "ExpressionStmt":
+		return ast.ExpressionStmt{Span: d.span(node), Expression: d.expr(node["expression"])}
+	case "PrintStmt":
+		return ast.PrintStmt{Span: d.span(node), Expression: d.expr(node["expression"])}
+	case "VarStmt":
+		return ast.VarStmt{Span: d.span(node), Name: d.token(node["name"]), Initializer: d.optionalExpr(node["initializer"])}
+	case "Block":
+		return ast.Block{Span: d.span(node), Statements: d.stmts(node["statements"])}
+	case "IfStmt":
+		return ast.IfStmt{
+			Span:       d.span(node),
+			Condition:  d.expr(node["condition"]),
+			ThenBranch: d.stmt(node["thenBranch"]),
+			ElseBranch: d.optionalStmt(node["elseBranch"]),
+		}
+	case "WhileStmt":
+		return ast.WhileStmt{Span: d.span(node), Condition: d.expr(node["condition"]), Body: d.stmt(node["body"])}
+	case "BreakStmt":
+		return ast.BreakStmt{Span: d.span(node)}
+	case "ContinueStmt":
+		return ast.ContinueStmt{Span: d.span(node)}
+	case "FunctionStmt":
+		return d.function(node)
+	case "ReturnStmt":
+		return ast.ReturnStmt{Span: d.span(node), Keyword: d.token(node["keyword"]), Value: d.optionalExpr(node["value"])}
+	case "ClassStmt":
+		return d.class(node)
+	}
+	d.fail("unknown statement kind %q", kind)
+	return nil
+}
+
+func (d *decoder) stmts(raw json.RawMessage) []ast.Stmt {
+	var nodes []json.RawMessage
+	d.value(raw, &nodes)
+	stmts := make([]ast.Stmt, 0, len(nodes))
+	for _, node := range nodes {
+		stmts = append(stmts, d.stmt(node))
+	}
+	return stmts
+}
+
+func (d *decoder) function(node fields) ast.FunctionStmt {
+	return ast.FunctionStmt{Span: d.span(node), Name: d.token(node["name"]), Params: d.tokens(node["params"]), Body: d.stmts(node["body"])}
+}
+
+func (d *decoder) class(node fields) ast.ClassStmt {
+	class := ast.ClassStmt{Span: d.span(node), Name: d.token(node["name"])}
+	if superclass := d.optionalExpr(node["superclass"]); superclass != nil {
+		variable, ok := superclass.(ast.Variable)
+		if !ok {
+			d.fail("the superclass of %s is not a variable", class.Name.Lexeme)
+		}
+		class.Superclass = &variable
+	}
+	var methods []json.RawMessage
+	d.value(node["methods"], &methods)
+	class.Methods = make([]ast.FunctionStmt, 0, len(methods))
+	for _, method := range methods {
+		kind, fields := d.fields(method)
+		if d.err == nil && kind != "FunctionStmt" {
+			d.fail("method of %s is a %s, not a FunctionStmt", class.Name.Lexeme, kind)
+		}
+		class.Methods = append(class.Methods, d.function(fields))
+	}
+	return class
+}
@@ -0,0 +1,239 @@
+// Package serializer converts abstract syntax trees to JSON and back, for
+// tools outside the interpreter and for snapshots of the parser output.
+//
+// A program is an array of statements. Every node is an object with a
+// "kind", the name of its type in the ast package, and its fields under
+// their names in camel case, along with the "span" of source it was parsed
+// from. Tokens keep their type, lexeme, literal and position, and absent
+// children are null:
+//
+//	[{"expression": {"kind": "Literal", "span": {...}, "value": 1}, "kind": "PrintStmt", "span": {...}}]
+package serializer
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/go-interpreter/internal/ast"
+	"github.com/go-interpreter/internal/token"
+)
+
+// Token is the JSON form of a token. Offset is the byte offset of the token
+// from the start of the source.
+type Token struct {
+	Type    token.TokenType `json:"type"`
+	Lexeme  string          `json:"lexeme"`
+	Literal any             `json:"literal,omitempty"`
+	Line    int             `json:"line"`
+	Column  int             `json:"column"`
+	Offset  int             `json:"offset"`
+}
+
+// Span is the JSON form of the span of a node. The file is left out when
+// the source had no name.
+type Span struct {
+	File  string   `json:"file,omitempty"`
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Position is the JSON form of a position in the source.
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Offset int `json:"offset"`
+}
+
+// object is the JSON form of a node: its kind and its fields.
+type object map[string]any
+
+// Marshal returns the JSON form of statements.
+func Marshal(stmts []ast.Stmt) ([]byte, error) {
+	return marshal(stmts, "")
+}
+
+// MarshalIndent is like Marshal but indents the JSON, for people to read.
+func MarshalIndent(stmts []ast.Stmt) ([]byte, error) {
+	return marshal(stmts, "  ")
+}
+
+// marshal writes names like <stdin> as they are rather than escaping them
+// for HTML.
+func marshal(stmts []ast.Stmt, indent string) ([]byte, error) {
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", indent)
+	if err := encoder.Encode(encodeStmts(stmts)); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(out.Bytes(), []byte("\n")), nil
+}
+
+// encoder is a visitor implementation building the JSON form of nodes.
+type encoder struct{}
+
+func encodeStmts(stmts []ast.Stmt) []object {
+	nodes := make([]object, 0, len(stmts))
+	for _, stmt := range stmts {
+		nodes = append(nodes, encodeStmt(stmt))
+	}
+	return nodes
+}
+
+func encodeStmt(stmt ast.Stmt) object {
+	if stmt == nil {
+		return nil
+	}
+	result, _ := stmt.Accept(encoder{})
+	return withSpan(result.(object), stmt.Location())
+}
+
+func encodeExpr(expr ast.Expr) object {
+	if expr == nil {
+		return nil
+	}
+	result, _ := expr.Accept(encoder{})
+	return withSpan(result.(object), expr.Location())
+}
+
+func encodeExprs(exprs []ast.Expr) []object {
+	nodes := make([]object, 0, len(exprs))
+	for _, expr := range exprs {
+		nodes = append(nodes, encodeExpr(expr))
+	}
+	return nodes
+}
+
+func withSpan(node object, span ast.Span) object {
+	node["span"] = Span{
+		File:  span.File,
+		Start: Position(span.Start),
+		End:   Position(span.End),
+	}
+	return node
+}
+
+func encodeToken(tok token.Token) Token {
+	return Token{
+		Type:    tok.Type,
+		Lexeme:  tok.Lexeme,
+		Literal: tok.Literal,
+		Line:    tok.Line,
+		Column:  tok.Column,
+		Offset:  tok.Char,
+	}
+}
+
+func encodeTokens(tokens []token.Token) []Token {
+	encoded := make([]Token, 0, len(tokens))
+	for _, tok := range tokens {
+		encoded = append(encoded, encodeToken(tok))
+	}
+	return encoded
+}
+
+func (encoder) VisitBinary(node ast.Binary) (any, error) {
+	return object{"kind": "Binary", "left": encodeExpr(node.Left), "operator": encodeToken(node.Operator), "right": encodeExpr(node.Right)}, nil
+}
+
+func (encoder) VisitLogical(node ast.Logical) (any, error) {
+	return object{"kind": "Logical", "left": encodeExpr(node.Left), "operator": encodeToken(node.Operator), "right": encodeExpr(node.Right)}, nil
+}
+
+func (encoder) VisitGrouping(node ast.Grouping) (any, error) {
+	return object{"kind": "Grouping", "expression": encodeExpr(node.Expression)}, nil
+}
+
+func (encoder) VisitLiteral(node ast.Literal) (any, error) {
+	return object{"kind": "Literal", "value": node.Value}, nil
+}
+
+func (encoder) VisitUnary(node ast.Unary) (any, error) {
+	return object{"kind": "Unary", "operator": encodeToken(node.Operator), "right": encodeExpr(node.Right)}, nil
+}
+
+func (encoder) VisitVariable(node ast.Variable) (any, error) {
+	return object{"kind": "Variable", "name": encodeToken(node.Name)}, nil
+}
+
+func (encoder) VisitAssign(node ast.Assign) (any, error) {
+	return object{"kind": "Assign", "name": encodeToken(node.Name), "value": encodeExpr(node.Value)}, nil
+}
+
+func (encoder) VisitCall(node ast.Call) (any, error) {
+	return object{"kind": "Call", "callee": encodeExpr(node.Callee), "paren": encodeToken(node.Paren), "arguments": encodeExprs(node.Arguments)}, nil
+}
+
+func (encoder) VisitGet(node ast.Get) (any, error) {
+	return object{"kind": "Get", "object": encodeExpr(node.Object), "name": encodeToken(node.Name)}, nil
+}
+
+func (encoder) VisitSet(node ast.Set) (any, error) {
+	return object{"kind": "Set", "object": encodeExpr(node.Object), "name": encodeToken(node.Name), "value": encodeExpr(node.Value)}, nil
+}
+
+func (encoder) VisitIncrement(node ast.Increment) (any, error) {
+	return object{"kind": "Increment", "object": encodeExpr(node.Object), "name": encodeToken(node.Name), "operator": encodeToken(node.Operator)}, nil
+}
+
+func (encoder) VisitThis(node ast.This) (any, error) {
+	return object{"kind": "This", "keyword": encodeToken(node.Keyword)}, nil
+}
+
+func (encoder) VisitSuper(node ast.Super) (any, error) {
+	return object{"kind": "Super", "keyword": encodeToken(node.Keyword), "method": encodeToken(node.Method)}, nil
+}
+
+func (encoder) VisitExpressionStmt(node ast.ExpressionStmt) (any, error) {
+	return object{"kind": "ExpressionStmt", "expression": encodeExpr(node.Expression)}, nil
+}
+
+func (encoder) VisitPrintStmt(node ast.PrintStmt) (any, error) {
+	return object{"kind": "PrintStmt", "expression": encodeExpr(node.Expression)}, nil
+}
+
+func (encoder) VisitVarStmt(node ast.VarStmt) (any, error) {
+	return object{"kind": "VarStmt", "name": encodeToken(node.Name), "initializer": encodeExpr(node.Initializer)}, nil
+}
+
+func (encoder) VisitBlockStmt(node ast.Block) (any, error) {
+	return object{"kind": "Block", "statements": encodeStmts(node.Statements)}, nil
+}
+
+func (encoder) VisitIfStmt(node ast.IfStmt) (any, error) {
+	return object{"kind": "IfStmt", "condition": encodeExpr(node.Condition),
+		"thenBranch": encodeStmt(node.ThenBranch), "elseBranch": encodeStmt(node.ElseBranch)}, nil
+}
+
+func (encoder) VisitWhileStmt(node ast.WhileStmt) (any, error) {
+	return object{"kind": "WhileStmt", "condition": encodeExpr(node.Condition), "body": encodeStmt(node.Body)}, nil
+}
+
+func (encoder) VisitBreakStmt() (any, error) {
+	return object{"kind": "BreakStmt"}, nil
+}
+
+func (encoder) VisitContinueStmt() (any, error) {
+	return object{"kind": "ContinueStmt"}, nil
+}
+
+func (encoder) VisitFunctionStmt(node ast.FunctionStmt) (any, error) {
+	return object{"kind": "FunctionStmt", "name": encodeToken(node.Name), "params": encodeTokens(node.Params), "body": encodeStmts(node.Body)}, nil
+}
+
+func (encoder) VisitReturnStmt(node ast.ReturnStmt) (any, error) {
+	return object{"kind": "ReturnStmt", "keyword": encodeToken(node.Keyword), "value": encodeExpr(node.Value)}, nil
+}
+
+func (encoder) VisitClassStmt(node ast.ClassStmt) (any, error) {
+	var superclass object
+	if node.Superclass != nil {
+		superclass = encodeExpr(*node.Superclass)
+	}
+	methods := make([]object, 0, len(node.Methods))
+	for _, method := range node.Methods {
+		methods = append(methods, encodeStmt(method))
+	}
+	return object{"kind": "ClassStmt", "name": encodeToken(node.Name), "superclass": superclass, "methods": methods}, nil
+}
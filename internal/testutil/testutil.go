@@ -0,0 +1,18 @@
+// Package testutil holds the helpers the tests under tests/ share.
+package testutil
+
+import (
+	"testing"
+
+	"github.com/go-interpreter/internal/analysis"
+	"github.com/go-interpreter/internal/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+// Parse scans and parses source, failing the test on any syntax error.
+func Parse(t testing.TB, source string) []ast.Stmt {
+	t.Helper()
+	stmts, diagnostics := analysis.Parse(analysis.Program{Source: source})
+	assert.Empty(t, diagnostics)
+	return stmts
+}
@@ -7,12 +7,14 @@ import (
 )
 
 // Token represents a single unit of lexical information in the source code.
-// It includes its type, lexeme, literal value, and the line on which it appears.
+// It includes its type, lexeme, literal value, and where it appears: the
+// 1-based line and column, and Char, the byte offset from the start of the source.
 type Token struct {
 	Type    TokenType
 	Lexeme  string
 	Literal any
 	Line    int
+	Column  int
 	Char    int
 }
 
@@ -26,6 +28,16 @@ func (token *Token) ToString() string {
 
 // String This will be used by the fmt package to print out to the standard out using this formatted string
 func (token Token) String() string {
-	return fmt.Sprintf("Token<Type=%v, Lexeme=%v, Literal=%v, Line=%v, Char=%v>",
-		token.Type, token.Lexeme, token.Literal, token.Line, token.Char) //nolint:lll
+	return fmt.Sprintf("Token<Type=%v, Lexeme=%v, Literal=%v, Line=%v, Column=%v, Char=%v>",
+		token.Type, token.Lexeme, token.Literal, token.Line, token.Column, token.Char) //nolint:lll
+}
+
+// Describe formats the token for dumps of the scanner output, as its
+// position, type, lexeme and, for literals, value.
+func (token Token) Describe() string {
+	description := fmt.Sprintf("%d:%d\t%-13s %s", token.Line, token.Column, token.Type, token.Lexeme)
+	if token.Literal != nil {
+		description += fmt.Sprintf(" (%v)", token.Literal)
+	}
+	return description
 }
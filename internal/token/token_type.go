@@ -1,5 +1,10 @@
 package token
 
+import (
+	"fmt"
+	"strconv"
+)
+
 // TokenType represents the category or type of a token in a lexical analysis process, such as operators, keywords, or literals.
 type TokenType int
 
@@ -60,6 +65,9 @@ const (
 	CONTINUE
 
 	// MISC
+	// COMMENT is a '//' comment. Comments never reach the parser; the
+	// scanner keeps them aside as trivia for tools that print source.
+	COMMENT
 	EOF
 )
 
@@ -84,3 +92,74 @@ var Keywords = map[string]TokenType{
 	"break":    BREAK,
 	"continue": CONTINUE,
 }
+
+var tokenTypeNames = [...]string{
+	LEFT_PAREN:    "LEFT_PAREN",
+	RIGHT_PAREN:   "RIGHT_PAREN",
+	LEFT_BRACE:    "LEFT_BRACE",
+	RIGHT_BRACE:   "RIGHT_BRACE",
+	COMMA:         "COMMA",
+	DOT:           "DOT",
+	MINUS:         "MINUS",
+	PLUS:          "PLUS",
+	SEMICOLON:     "SEMICOLON",
+	SLASH:         "SLASH",
+	STAR:          "STAR",
+	BANG:          "BANG",
+	BANG_EQUAL:    "BANG_EQUAL",
+	EQUAL:         "EQUAL",
+	EQUAL_EQUAL:   "EQUAL_EQUAL",
+	GREATER:       "GREATER",
+	GREATER_EQUAL: "GREATER_EQUAL",
+	LESS:          "LESS",
+	LESS_EQUAL:    "LESS_EQUAL",
+	INC:           "INC",
+	DEC:           "DEC",
+	IDENTIFIER:    "IDENTIFIER",
+	STRING:        "STRING",
+	NUMBER:        "NUMBER",
+	AND:           "AND",
+	CLASS:         "CLASS",
+	ELSE:          "ELSE",
+	FALSE:         "FALSE",
+	FUN:           "FUN",
+	FOR:           "FOR",
+	IF:            "IF",
+	NIL:           "NIL",
+	OR:            "OR",
+	PRINT:         "PRINT",
+	RETURN:        "RETURN",
+	SUPER:         "SUPER",
+	THIS:          "THIS",
+	TRUE:          "TRUE",
+	VAR:           "VAR",
+	WHILE:         "WHILE",
+	BREAK:         "BREAK",
+	CONTINUE:      "CONTINUE",
+	COMMENT:       "COMMENT",
+	EOF:           "EOF",
+}
+
+// String returns the name of the token type, e.g. LEFT_PAREN.
+func (tokenType TokenType) String() string {
+	if tokenType < 0 || int(tokenType) >= len(tokenTypeNames) {
+		return strconv.Itoa(int(tokenType))
+	}
+	return tokenTypeNames[tokenType]
+}
+
+// MarshalText writes the token type by its name, so it reads well in JSON.
+func (tokenType TokenType) MarshalText() ([]byte, error) {
+	return []byte(tokenType.String()), nil
+}
+
+// UnmarshalText reads a token type from its name.
+func (tokenType *TokenType) UnmarshalText(text []byte) error {
+	for value, name := range tokenTypeNames {
+		if name == string(text) {
+			*tokenType = TokenType(value)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown token type %q", text)
+}
@@ -0,0 +1,72 @@
+package vm
+
+import (
+	"fmt"
+
+	"github.com/go-interpreter/internal/compiler"
+	"github.com/go-interpreter/internal/interpreter"
+)
+
+// Closure is a compiled function together with the variables it
+// captured from the functions enclosing it.
+type Closure struct {
+	Function *compiler.Function
+	Upvalues []*Upvalue
+}
+
+func (closure *Closure) String() string {
+	return closure.Function.String()
+}
+
+// Native is a function built into the virtual machine, written in Go.
+type Native struct {
+	Name     string
+	Arity    int
+	Function func(arguments []interpreter.Value) (interpreter.Value, error)
+}
+
+func (native *Native) String() string {
+	return fmt.Sprintf("<native fn %s>", native.Name)
+}
+
+// Upvalue is a variable captured by a closure. While the variable is
+// still alive on the stack, Slot points at it; once its scope ends the
+// value is moved into Closed and the upvalue is detached from the stack.
+type Upvalue struct {
+	Slot     int
+	Closed   interpreter.Value
+	IsClosed bool
+	Next     *Upvalue
+}
+
+// Class is the runtime representation of a 'class' declaration. Methods
+// inherited from a superclass are copied in when the class is created.
+type Class struct {
+	Name    string
+	Methods map[string]*Closure
+}
+
+func (class *Class) String() string {
+	return class.Name
+}
+
+// Instance is an object created by calling a Class.
+type Instance struct {
+	Class  *Class
+	Fields map[string]interpreter.Value
+}
+
+func (instance *Instance) String() string {
+	return fmt.Sprintf("%s instance", instance.Class.Name)
+}
+
+// BoundMethod is a method looked up on an instance. It remembers the
+// receiver so that it ends up in slot zero when the method is called.
+type BoundMethod struct {
+	Receiver interpreter.Value
+	Method   *Closure
+}
+
+func (bound *BoundMethod) String() string {
+	return bound.Method.String()
+}
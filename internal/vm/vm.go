@@ -0,0 +1,476 @@
+package vm
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/go-interpreter/internal/compiler"
+	"github.com/go-interpreter/internal/errors"
+	"github.com/go-interpreter/internal/interpreter"
+)
+
+// maxFrames bounds the depth of the call stack, turning runaway recursion
+// into a runtime error rather than exhausting memory.
+const maxFrames = 1 << 16
+
+// CallFrame is a function invocation in progress. Slots is the index in
+// the VM stack of the frame's slot zero.
+type CallFrame struct {
+	closure *Closure
+	ip      int
+	slots   int
+}
+
+// VM executes the bytecode produced by internal/compiler. Globals outlive
+// a single Interpret call, so a VM can run several scripts in a row. It
+// shares interpreter.Value with the tree-walker, so both backends agree on
+// truthiness, equality and how values print; closures, classes and
+// instances of the VM are wrapped with interpreter.ObjectValue.
+type VM struct {
+	frames       []CallFrame
+	stack        []interpreter.Value
+	globals      map[string]interpreter.Value
+	openUpvalues *Upvalue
+	out          io.Writer
+	input        *interpreter.Input
+	file         string
+}
+
+// NewVM creates a virtual machine that writes the output of 'print'
+// statements to out, and where readLine reads from standard input.
+func NewVM(out io.Writer) *VM {
+	vm := &VM{
+		frames:  make([]CallFrame, 0, 64),
+		stack:   make([]interpreter.Value, 0, 256),
+		globals: make(map[string]interpreter.Value),
+		out:     out,
+		input:   interpreter.NewInput(os.Stdin),
+	}
+	vm.globals["readLine"] = interpreter.ObjectValue(&Native{Name: "readLine", Function: vm.readLine})
+	return vm
+}
+
+// SetInput sets where readLine reads from.
+func (vm *VM) SetInput(in io.Reader) {
+	vm.input = interpreter.NewInput(in)
+}
+
+// readLine is the builtin of the same name, as in the tree-walking
+// interpreter.
+func (vm *VM) readLine([]interpreter.Value) (interpreter.Value, error) {
+	line, ok, err := vm.input.ReadLine()
+	if !ok {
+		return interpreter.Nil, err
+	}
+	return interpreter.StringValue(line), nil
+}
+
+// SetFile sets the file name that stack traces refer to.
+func (vm *VM) SetFile(file string) {
+	vm.file = file
+}
+
+// Interpret runs a compiled script. It reports runtime errors the same
+// way the tree-walking interpreter does so that both backends behave alike.
+func (vm *VM) Interpret(script *compiler.Function) error {
+	closure := &Closure{Function: script}
+	vm.push(interpreter.ObjectValue(closure))
+	if err := vm.call(closure, 0); err != nil {
+		return fmt.Errorf("error: %w", err)
+	}
+	if err := vm.run(); err != nil {
+		vm.resetStack()
+		return fmt.Errorf("error: %w", err)
+	}
+	return nil
+}
+
+// run is the main loop: it decodes and executes one instruction at a time
+// until the script function returns.
+func (vm *VM) run() error {
+	frame := &vm.frames[len(vm.frames)-1]
+	for {
+		chunk := &frame.closure.Function.Chunk
+		op := compiler.OpCode(chunk.Code[frame.ip])
+		frame.ip++
+
+		switch op {
+		case compiler.OP_CONSTANT:
+			vm.push(interpreter.LiteralValue(chunk.Constants[vm.readShort(frame)]))
+		case compiler.OP_NIL:
+			vm.push(interpreter.Nil)
+		case compiler.OP_TRUE:
+			vm.push(interpreter.BoolValue(true))
+		case compiler.OP_FALSE:
+			vm.push(interpreter.BoolValue(false))
+		case compiler.OP_POP:
+			vm.pop()
+		case compiler.OP_DUP:
+			vm.push(vm.peek(0))
+		case compiler.OP_GET_LOCAL:
+			vm.push(vm.stack[frame.slots+int(vm.readByte(frame))])
+		case compiler.OP_SET_LOCAL:
+			vm.stack[frame.slots+int(vm.readByte(frame))] = vm.peek(0)
+		case compiler.OP_GET_GLOBAL:
+			name := vm.readString(frame)
+			value, ok := vm.globals[name]
+			if !ok {
+				return vm.runtimeError(errors.UNDEFINED_VARIABLE, "Undefined variable %s.", name)
+			}
+			vm.push(value)
+		case compiler.OP_DEFINE_GLOBAL:
+			vm.globals[vm.readString(frame)] = vm.pop()
+		case compiler.OP_SET_GLOBAL:
+			name := vm.readString(frame)
+			if _, ok := vm.globals[name]; !ok {
+				return vm.runtimeError(errors.UNDEFINED_VARIABLE, "Undefined variable %s.", name)
+			}
+			vm.globals[name] = vm.peek(0)
+		case compiler.OP_GET_UPVALUE:
+			vm.push(vm.getUpvalue(frame.closure.Upvalues[vm.readByte(frame)]))
+		case compiler.OP_SET_UPVALUE:
+			vm.setUpvalue(frame.closure.Upvalues[vm.readByte(frame)], vm.peek(0))
+		case compiler.OP_GET_PROPERTY:
+			instance, ok := vm.peek(0).AsObject().(*Instance)
+			if !ok {
+				return vm.runtimeError(errors.NOT_AN_INSTANCE, "Only instances have properties.")
+			}
+			name := vm.readString(frame)
+			if value, ok := instance.Fields[name]; ok {
+				vm.pop()
+				vm.push(value)
+				break
+			}
+			if err := vm.bindMethod(instance.Class, name); err != nil {
+				return err
+			}
+		case compiler.OP_SET_PROPERTY:
+			instance, ok := vm.peek(1).AsObject().(*Instance)
+			if !ok {
+				return vm.runtimeError(errors.NOT_AN_INSTANCE, "Only instances have fields.")
+			}
+			instance.Fields[vm.readString(frame)] = vm.peek(0)
+			value := vm.pop()
+			vm.pop()
+			vm.push(value)
+		case compiler.OP_GET_SUPER:
+			name := vm.readString(frame)
+			superclass := vm.pop().AsObject().(*Class)
+			if err := vm.bindMethod(superclass, name); err != nil {
+				return err
+			}
+		case compiler.OP_EQUAL:
+			right := vm.pop()
+			left := vm.pop()
+			vm.push(interpreter.BoolValue(left.Equal(right)))
+		case compiler.OP_GREATER, compiler.OP_LESS, compiler.OP_SUBTRACT,
+			compiler.OP_MULTIPLY, compiler.OP_DIVIDE:
+			if err := vm.binaryNumber(op); err != nil {
+				return err
+			}
+		case compiler.OP_ADD:
+			if err := vm.add(); err != nil {
+				return err
+			}
+		case compiler.OP_NOT:
+			vm.push(interpreter.BoolValue(!vm.pop().Truthy()))
+		case compiler.OP_NEGATE:
+			value := vm.peek(0)
+			if !value.IsNumber() {
+				return vm.runtimeError(errors.INVALID_OPERAND, "'%v' Operand must be a number", value)
+			}
+			vm.stack[len(vm.stack)-1] = interpreter.NumberValue(-value.AsNumber())
+		case compiler.OP_PRINT:
+			if _, err := fmt.Fprint(vm.out, vm.pop()); err != nil {
+				return vm.runtimeError(errors.NATIVE_ERROR, "%v", err)
+			}
+		case compiler.OP_JUMP:
+			offset := vm.readShort(frame)
+			frame.ip += offset
+		case compiler.OP_JUMP_IF_FALSE:
+			offset := vm.readShort(frame)
+			if !vm.peek(0).Truthy() {
+				frame.ip += offset
+			}
+		case compiler.OP_LOOP:
+			offset := vm.readShort(frame)
+			frame.ip -= offset
+		case compiler.OP_CALL:
+			argCount := int(vm.readByte(frame))
+			if err := vm.callValue(vm.peek(argCount), argCount); err != nil {
+				return err
+			}
+			frame = &vm.frames[len(vm.frames)-1]
+		case compiler.OP_CLOSURE:
+			function := chunk.Constants[vm.readShort(frame)].(*compiler.Function)
+			closure := &Closure{Function: function, Upvalues: make([]*Upvalue, function.UpvalueCount)}
+			for index := range closure.Upvalues {
+				isLocal := vm.readByte(frame)
+				slot := int(vm.readByte(frame))
+				if isLocal == 1 {
+					closure.Upvalues[index] = vm.captureUpvalue(frame.slots + slot)
+				} else {
+					closure.Upvalues[index] = frame.closure.Upvalues[slot]
+				}
+			}
+			vm.push(interpreter.ObjectValue(closure))
+		case compiler.OP_CLOSE_UPVALUE:
+			vm.closeUpvalues(len(vm.stack) - 1)
+			vm.pop()
+		case compiler.OP_RETURN:
+			result := vm.pop()
+			vm.closeUpvalues(frame.slots)
+			vm.frames = vm.frames[:len(vm.frames)-1]
+			if len(vm.frames) == 0 {
+				vm.pop()
+				return nil
+			}
+			vm.stack = vm.stack[:frame.slots]
+			vm.push(result)
+			frame = &vm.frames[len(vm.frames)-1]
+		case compiler.OP_CLASS:
+			vm.push(interpreter.ObjectValue(&Class{Name: vm.readString(frame), Methods: make(map[string]*Closure)}))
+		case compiler.OP_INHERIT:
+			superclass, ok := vm.peek(1).AsObject().(*Class)
+			if !ok {
+				return vm.runtimeError(errors.SUPERCLASS_NOT_CLASS, "Superclass must be a class.")
+			}
+			subclass := vm.peek(0).AsObject().(*Class)
+			for name, method := range superclass.Methods {
+				subclass.Methods[name] = method
+			}
+			vm.pop()
+		case compiler.OP_METHOD:
+			method := vm.peek(0).AsObject().(*Closure)
+			class := vm.peek(1).AsObject().(*Class)
+			class.Methods[vm.readString(frame)] = method
+			vm.pop()
+		default:
+			return vm.runtimeError(errors.INTERNAL_ERROR, "Unknown opcode %d.", op)
+		}
+	}
+}
+
+// callValue dispatches a call on whatever kind of value the callee is.
+func (vm *VM) callValue(callee interpreter.Value, argCount int) error {
+	switch callee := callee.AsObject().(type) {
+	case *Closure:
+		return vm.call(callee, argCount)
+	case *BoundMethod:
+		vm.stack[len(vm.stack)-argCount-1] = callee.Receiver
+		return vm.call(callee.Method, argCount)
+	case *Class:
+		instance := &Instance{Class: callee, Fields: make(map[string]interpreter.Value)}
+		vm.stack[len(vm.stack)-argCount-1] = interpreter.ObjectValue(instance)
+		if initializer, ok := callee.Methods["init"]; ok {
+			return vm.call(initializer, argCount)
+		}
+		if argCount != 0 {
+			return vm.runtimeError(errors.WRONG_ARGUMENT_COUNT, "Expected 0 arguments but got %d.", argCount)
+		}
+		return nil
+	case *Native:
+		if argCount != callee.Arity {
+			return vm.runtimeError(errors.WRONG_ARGUMENT_COUNT, "Expected %d arguments but got %d.", callee.Arity, argCount)
+		}
+		result, err := callee.Function(vm.stack[len(vm.stack)-argCount:])
+		if err != nil {
+			return vm.runtimeError(errors.NATIVE_ERROR, "%v", err)
+		}
+		vm.stack = vm.stack[:len(vm.stack)-argCount-1]
+		vm.push(result)
+		return nil
+	default:
+		return vm.runtimeError(errors.NOT_CALLABLE, "Can only call functions and classes.")
+	}
+}
+
+// call pushes a new frame whose slot zero is the callee already on the stack.
+func (vm *VM) call(closure *Closure, argCount int) error {
+	if argCount != closure.Function.Arity {
+		return vm.runtimeError(errors.WRONG_ARGUMENT_COUNT, "Expected %d arguments but got %d.", closure.Function.Arity, argCount)
+	}
+	if len(vm.frames) == maxFrames {
+		return vm.runtimeError(errors.STACK_OVERFLOW, "Stack overflow.")
+	}
+	vm.frames = append(vm.frames, CallFrame{
+		closure: closure,
+		slots:   len(vm.stack) - argCount - 1,
+	})
+	return nil
+}
+
+// bindMethod replaces the instance on top of the stack with one of its
+// class methods bound to it.
+func (vm *VM) bindMethod(class *Class, name string) error {
+	method, ok := class.Methods[name]
+	if !ok {
+		return vm.runtimeError(errors.UNDEFINED_PROPERTY, "Undefined property '%s'.", name)
+	}
+	bound := &BoundMethod{Receiver: vm.peek(0), Method: method}
+	vm.pop()
+	vm.push(interpreter.ObjectValue(bound))
+	return nil
+}
+
+// captureUpvalue returns the open upvalue for a stack slot, creating it if
+// needed. The list of open upvalues is kept sorted by slot, highest first,
+// so that closures capturing the same variable share one upvalue.
+func (vm *VM) captureUpvalue(slot int) *Upvalue {
+	var previous *Upvalue
+	upvalue := vm.openUpvalues
+	for upvalue != nil && upvalue.Slot > slot {
+		previous = upvalue
+		upvalue = upvalue.Next
+	}
+	if upvalue != nil && upvalue.Slot == slot {
+		return upvalue
+	}
+	created := &Upvalue{Slot: slot, Next: upvalue}
+	if previous == nil {
+		vm.openUpvalues = created
+	} else {
+		previous.Next = created
+	}
+	return created
+}
+
+// closeUpvalues moves every open upvalue at or above the given slot off
+// the stack, right before those slots are discarded.
+func (vm *VM) closeUpvalues(last int) {
+	for vm.openUpvalues != nil && vm.openUpvalues.Slot >= last {
+		upvalue := vm.openUpvalues
+		upvalue.Closed = vm.stack[upvalue.Slot]
+		upvalue.IsClosed = true
+		vm.openUpvalues = upvalue.Next
+	}
+}
+
+func (vm *VM) getUpvalue(upvalue *Upvalue) interpreter.Value {
+	if upvalue.IsClosed {
+		return upvalue.Closed
+	}
+	return vm.stack[upvalue.Slot]
+}
+
+func (vm *VM) setUpvalue(upvalue *Upvalue, value interpreter.Value) {
+	if upvalue.IsClosed {
+		upvalue.Closed = value
+		return
+	}
+	vm.stack[upvalue.Slot] = value
+}
+
+// add handles '+', which adds numbers and concatenates strings. A string
+// and a number are concatenated with the number formatted as text.
+func (vm *VM) add() error {
+	right := vm.peek(0)
+	left := vm.peek(1)
+	if left.IsString() || right.IsString() {
+		for _, operand := range []interpreter.Value{left, right} {
+			if !operand.IsString() && !operand.IsNumber() {
+				return vm.runtimeError(errors.INVALID_OPERAND, "'%v' Operand must be a string or a number", operand)
+			}
+		}
+		vm.pop()
+		vm.pop()
+		vm.push(interpreter.StringValue(left.String() + right.String()))
+		return nil
+	}
+	for _, operand := range []interpreter.Value{left, right} {
+		if !operand.IsNumber() {
+			return vm.runtimeError(errors.INVALID_OPERAND, "'%v' Operand must be a number", operand)
+		}
+	}
+	vm.pop()
+	vm.pop()
+	vm.push(interpreter.NumberValue(left.AsNumber() + right.AsNumber()))
+	return nil
+}
+
+func (vm *VM) binaryNumber(op compiler.OpCode) error {
+	for _, operand := range []interpreter.Value{vm.peek(1), vm.peek(0)} {
+		if !operand.IsNumber() {
+			return vm.runtimeError(errors.INVALID_OPERAND, "'%v' Operand must be a number", operand)
+		}
+	}
+	right := vm.pop().AsNumber()
+	left := vm.pop().AsNumber()
+	switch op {
+	case compiler.OP_GREATER:
+		vm.push(interpreter.BoolValue(left > right))
+	case compiler.OP_LESS:
+		vm.push(interpreter.BoolValue(left < right))
+	case compiler.OP_SUBTRACT:
+		vm.push(interpreter.NumberValue(left - right))
+	case compiler.OP_MULTIPLY:
+		vm.push(interpreter.NumberValue(left * right))
+	case compiler.OP_DIVIDE:
+		vm.push(interpreter.NumberValue(left / right))
+	}
+	return nil
+}
+
+func (vm *VM) readByte(frame *CallFrame) byte {
+	b := frame.closure.Function.Chunk.Code[frame.ip]
+	frame.ip++
+	return b
+}
+
+func (vm *VM) readShort(frame *CallFrame) int {
+	code := frame.closure.Function.Chunk.Code
+	frame.ip += 2
+	return int(code[frame.ip-2])<<8 | int(code[frame.ip-1])
+}
+
+func (vm *VM) readString(frame *CallFrame) string {
+	return frame.closure.Function.Chunk.Constants[vm.readShort(frame)].(string)
+}
+
+func (vm *VM) push(value interpreter.Value) {
+	vm.stack = append(vm.stack, value)
+}
+
+func (vm *VM) pop() interpreter.Value {
+	value := vm.stack[len(vm.stack)-1]
+	vm.stack = vm.stack[:len(vm.stack)-1]
+	return value
+}
+
+func (vm *VM) peek(distance int) interpreter.Value {
+	return vm.stack[len(vm.stack)-1-distance]
+}
+
+func (vm *VM) resetStack() {
+	vm.stack = vm.stack[:0]
+	vm.frames = vm.frames[:0]
+	vm.openUpvalues = nil
+}
+
+// runtimeError builds an error located at the instruction currently
+// being executed by the innermost frame, with a stack trace of all frames.
+func (vm *VM) runtimeError(code errors.Code, format string, args ...any) error {
+	trace := make([]errors.Frame, 0, len(vm.frames))
+	for index := len(vm.frames) - 1; index >= 0; index-- {
+		frame := &vm.frames[index]
+		position := frame.closure.Function.Chunk.Position(frame.ip - 1)
+		name := frame.closure.Function.Name
+		if name == "" {
+			name = errors.SCRIPT_FRAME
+		}
+		trace = append(trace, errors.Frame{Function: name, File: vm.file, Line: position.Line, Column: position.Column})
+	}
+	frame := &vm.frames[len(vm.frames)-1]
+	position := frame.closure.Function.Chunk.Position(frame.ip - 1)
+	return errors.ExecutionError{
+		Type:    errors.RUNTIME_ERROR,
+		Code:    code,
+		Line:    position.Line,
+		Column:  position.Column,
+		Length:  position.Length,
+		Where:   position.Char,
+		Message: fmt.Sprintf(format, args...),
+		Trace:   trace,
+	}
+}
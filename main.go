@@ -3,16 +3,9 @@ package main
 import (
 	"os"
 
-	"github.com/go-interpreter/internal/repl"
+	"github.com/go-interpreter/internal/cli"
 )
 
 func main() {
-	r := repl.NewRepl()
-	if len(os.Args) > 1 {
-		programPath := os.Args[1]
-		r.LoadProgram(programPath)
-	} else {
-		r.LoadProgram("examples/program.txt")
-	}
-
+	os.Exit(cli.Run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
 }
@@ -0,0 +1,278 @@
+package lox
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/go-interpreter/internal/interpreter"
+	"github.com/go-interpreter/internal/token"
+)
+
+// The language has no collections of its own. Go slices become lists, host
+// objects with a 'length' property and a 'get' method taking an index, and
+// Go maps become instances of Map, with a field for every key.
+var mapClass = interpreter.NewClass("Map", nil, map[string]*interpreter.Function{})
+
+var (
+	valueType = reflect.TypeOf(Nil)
+	errorType = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// ValueOf converts a Go value to a Value. Booleans, numbers of any Go type
+// and strings convert to their script counterparts, nil to Nil, slices and
+// arrays to lists and maps with string keys to Maps. Functions become
+// native functions, see Define, and pointers to structs host objects bound
+// with the default options, see Bind. A Value is returned as it is.
+func ValueOf(goValue any) (Value, error) {
+	if goValue == nil {
+		return Nil, nil
+	}
+	return valueOf(reflect.ValueOf(goValue), BindOptions{})
+}
+
+// valueOf converts a Go value, binding the structs in it with options.
+func valueOf(goValue reflect.Value, options BindOptions) (Value, error) {
+	if goValue.Type() == valueType {
+		return goValue.Interface().(Value), nil
+	}
+	switch goValue.Kind() {
+	case reflect.Bool:
+		return Bool(goValue.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Number(float64(goValue.Int())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return Number(float64(goValue.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		return Number(goValue.Float()), nil
+	case reflect.String:
+		return String(goValue.String()), nil
+	case reflect.Interface:
+		if goValue.IsNil() {
+			return Nil, nil
+		}
+		return valueOf(goValue.Elem(), options)
+	case reflect.Slice, reflect.Array:
+		elements := make([]Value, goValue.Len())
+		for index := range elements {
+			element, err := valueOf(goValue.Index(index), options)
+			if err != nil {
+				return Nil, err
+			}
+			elements[index] = element
+		}
+		return newList(elements), nil
+	case reflect.Map:
+		if goValue.Type().Key().Kind() != reflect.String {
+			break
+		}
+		instance := interpreter.NewInstance(mapClass)
+		iterator := goValue.MapRange()
+		for iterator.Next() {
+			field, err := valueOf(iterator.Value(), options)
+			if err != nil {
+				return Nil, err
+			}
+			instance.Set(token.Token{Lexeme: iterator.Key().String()}, field)
+		}
+		return interpreter.InstanceValue(instance), nil
+	case reflect.Func:
+		if !goValue.IsNil() {
+			return nativeOf("", goValue, options)
+		}
+	case reflect.Pointer:
+		if goValue.Type().Elem().Kind() == reflect.Struct {
+			return bind(goValue, options)
+		}
+	case reflect.Struct:
+		// A struct is bound where it is, so that assigning to its fields
+		// changes it, unless it is a copy anyway
+		if !goValue.CanAddr() {
+			pointer := reflect.New(goValue.Type())
+			pointer.Elem().Set(goValue)
+			return bind(pointer, options)
+		}
+		return bind(goValue.Addr(), options)
+	}
+	return Nil, fmt.Errorf("cannot convert %s to a value", goValue.Type())
+}
+
+// list is a slice handed to scripts. Its elements stay on the Go side, so
+// that scripts can read them but never change what a Go function gets back.
+type list struct {
+	elements []Value
+}
+
+// newList returns a list holding the elements.
+func newList(elements []Value) Value {
+	return interpreter.HostValue(&list{elements: elements})
+}
+
+func (l *list) Get(name string) (Value, bool, error) {
+	switch name {
+	case "length":
+		return Number(float64(len(l.elements))), true, nil
+	case "get":
+		return interpreter.CallableValue(interpreter.NewNativeFunction("get", 1, l.get)), true, nil
+	}
+	return Nil, false, nil
+}
+
+func (l *list) Set(name string, value Value) (bool, error) {
+	if name == "length" || name == "get" {
+		return true, fmt.Errorf("cannot assign to %s, lists cannot be changed", name)
+	}
+	return false, nil
+}
+
+func (l *list) get(arguments []Value) (Value, error) {
+	var index int
+	if err := Convert(arguments[0], &index); err != nil {
+		return Nil, err
+	}
+	if index < 0 || index >= len(l.elements) {
+		return Nil, fmt.Errorf("index %d is out of range for a list of length %d", index, len(l.elements))
+	}
+	return l.elements[index], nil
+}
+
+// String is how 'print' shows the list.
+func (l *list) String() string {
+	return fmt.Sprintf("<list of %d>", len(l.elements))
+}
+
+// Convert stores a Value in the Go variable target points to, converting
+// it to the type of the variable: a boolean to a bool, a number to any
+// numeric type that holds it exactly, a string to a string, a list to a
+// slice, an instance to a map with string keys, from its fields, and a host
+// object to the pointer it was bound from. An 'any' gets the natural Go
+// value: nil, bool, float64, string, []any, map[string]any or the pointer
+// of a host object; functions and classes stay Values.
+func Convert(value Value, target any) error {
+	pointer := reflect.ValueOf(target)
+	if pointer.Kind() != reflect.Pointer || pointer.IsNil() {
+		return fmt.Errorf("cannot convert to %T, which is not a pointer", target)
+	}
+	return convert(value, pointer.Elem())
+}
+
+func convert(value Value, target reflect.Value) error {
+	targetType := target.Type()
+	if targetType == valueType {
+		target.Set(reflect.ValueOf(value))
+		return nil
+	}
+	if object, ok := value.AsHost().(*hostObject); ok && object.pointer.Type().AssignableTo(targetType) {
+		target.Set(object.pointer)
+		return nil
+	}
+	switch targetType.Kind() {
+	case reflect.Bool:
+		if value.IsBool() {
+			target.SetBool(value.AsBool())
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number := value.AsNumber()
+		if value.IsNumber() && number == float64(int64(number)) && !target.OverflowInt(int64(number)) {
+			target.SetInt(int64(number))
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		number := value.AsNumber()
+		if value.IsNumber() && number >= 0 && number == float64(uint64(number)) && !target.OverflowUint(uint64(number)) {
+			target.SetUint(uint64(number))
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		if value.IsNumber() {
+			target.SetFloat(value.AsNumber())
+			return nil
+		}
+	case reflect.String:
+		if value.IsString() {
+			target.SetString(value.AsString())
+			return nil
+		}
+	case reflect.Interface:
+		if targetType.NumMethod() == 0 {
+			natural, err := naturalOf(value)
+			if err != nil {
+				return err
+			}
+			if natural != nil {
+				target.Set(reflect.ValueOf(natural))
+			}
+			return nil
+		}
+	case reflect.Slice:
+		if l, ok := value.AsHost().(*list); ok {
+			slice := reflect.MakeSlice(targetType, len(l.elements), len(l.elements))
+			for index, element := range l.elements {
+				if err := convert(element, slice.Index(index)); err != nil {
+					return fmt.Errorf("element %d: %w", index, err)
+				}
+			}
+			target.Set(slice)
+			return nil
+		}
+	case reflect.Map:
+		if instance := value.AsInstance(); instance != nil && targetType.Key().Kind() == reflect.String {
+			goMap := reflect.MakeMapWithSize(targetType, len(instance.Fields))
+			for name, field := range instance.Fields {
+				element := reflect.New(targetType.Elem()).Elem()
+				if err := convert(field, element); err != nil {
+					return fmt.Errorf("field %s: %w", name, err)
+				}
+				goMap.SetMapIndex(reflect.ValueOf(name).Convert(targetType.Key()), element)
+			}
+			target.Set(goMap)
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot use %s as %s", describe(value), targetType)
+}
+
+// naturalOf returns the Go value closest to a Value.
+func naturalOf(value Value) (any, error) {
+	switch {
+	case value.IsNil():
+		return nil, nil
+	case value.IsBool():
+		return value.AsBool(), nil
+	case value.IsNumber():
+		return value.AsNumber(), nil
+	case value.IsString():
+		return value.AsString(), nil
+	case value.IsHost():
+		switch object := value.AsHost().(type) {
+		case *hostObject:
+			return object.pointer.Interface(), nil
+		case *list:
+			var elements []any
+			err := Convert(value, &elements)
+			return elements, err
+		}
+		return value, nil
+	case value.IsInstance():
+		var fields map[string]any
+		err := Convert(value, &fields)
+		return fields, err
+	default:
+		return value, nil
+	}
+}
+
+// describe shows a value in a conversion error.
+func describe(value Value) string {
+	switch {
+	case value.IsNil():
+		return "nil"
+	case value.IsString():
+		return strconv.Quote(value.AsString())
+	case value.IsBool(), value.IsNumber():
+		return fmt.Sprintf("%s %s", value.Kind(), value)
+	default:
+		return value.String()
+	}
+}
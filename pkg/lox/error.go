@@ -0,0 +1,93 @@
+package lox
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-interpreter/internal/errors"
+)
+
+// Frame is an entry of the stack trace of a runtime error: a function that
+// was running and the position it had reached.
+type Frame = errors.Frame
+
+// Diagnostic is a problem found in a source. Kind is the stage that found
+// it, like "Syntax Error" or "Runtime Error", and Code identifies the
+// problem; 'explain <code>' describes it. Line and Column are 1-based.
+// Runtime errors also carry the Trace of the calls that led to them,
+// innermost first.
+type Diagnostic struct {
+	Kind    string
+	Code    string
+	Line    int
+	Column  int
+	Message string
+	Hint    string
+	Trace   []Frame
+}
+
+func (diagnostic Diagnostic) String() string {
+	if diagnostic.Code == "" {
+		return fmt.Sprintf("%d:%d: %s: %s", diagnostic.Line, diagnostic.Column, diagnostic.Kind, diagnostic.Message)
+	}
+	return fmt.Sprintf("%d:%d: %s[%s]: %s", diagnostic.Line, diagnostic.Column, diagnostic.Kind,
+		diagnostic.Code, diagnostic.Message)
+}
+
+// Error is returned when a source cannot be run, with every syntax or
+// resolution problem in it, or when it fails while running, with the
+// runtime error. An error caused by the context being done unwraps to
+// the cause, so errors.Is(err, context.Canceled) holds.
+type Error struct {
+	File        string
+	Diagnostics []Diagnostic
+	cause       error
+}
+
+func (err *Error) Error() string {
+	lines := make([]string, 0, len(err.Diagnostics))
+	for _, diagnostic := range err.Diagnostics {
+		lines = append(lines, err.File+":"+diagnostic.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (err *Error) Unwrap() error {
+	return err.cause
+}
+
+// Runtime reports whether the source ran and failed, rather than having
+// syntax or resolution errors.
+func (err *Error) Runtime() bool {
+	return len(err.Diagnostics) == 1 && err.Diagnostics[0].Kind == errors.RUNTIME_ERROR.String()
+}
+
+func newError(file string, diagnostics []errors.ExecutionError) *Error {
+	err := &Error{File: file, Diagnostics: make([]Diagnostic, 0, len(diagnostics))}
+	for _, diagnostic := range diagnostics {
+		err.Diagnostics = append(err.Diagnostics, Diagnostic{
+			Kind:    diagnostic.Type.String(),
+			Code:    string(diagnostic.Code),
+			Line:    diagnostic.Line,
+			Column:  diagnostic.Column,
+			Message: diagnostic.Message,
+			Hint:    diagnostic.Hint,
+			Trace:   diagnostic.Trace,
+		})
+	}
+	return err
+}
+
+// runtimeError wraps an error coming out of the interpreter.
+func runtimeError(ctx context.Context, file string, err error) *Error {
+	diagnostic, ok := errors.AsExecutionError(err)
+	if !ok {
+		diagnostic = errors.ExecutionError{Type: errors.RUNTIME_ERROR, Message: err.Error()}
+	}
+	runtimeErr := newError(file, []errors.ExecutionError{diagnostic})
+	if diagnostic.Code == errors.INTERRUPTED {
+		runtimeErr.cause = context.Cause(ctx)
+	}
+	return runtimeErr
+}
@@ -0,0 +1,152 @@
+package lox
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/go-interpreter/internal/interpreter"
+)
+
+// BindOptions configures how a Go struct is exposed to scripts. Name maps
+// the Go name of a field or method to the one scripts use, LowerCamelCase
+// by default. When Allow is not empty, scripts only see the fields and
+// methods it lists, by their Go names. Objects reached through the fields
+// and methods of a bound struct are bound with the same options, so Allow
+// lists what scripts may use of those too.
+type BindOptions struct {
+	Name  func(goName string) string
+	Allow []string
+}
+
+// LowerCamelCase is the default name mapping: the leading capitals of a Go
+// name are lowered, so Port is 'port', URL 'url' and HTTPServer 'httpServer'.
+func LowerCamelCase(goName string) string {
+	runes := []rune(goName)
+	for index, r := range runes {
+		if !unicode.IsUpper(r) {
+			break
+		}
+		// The last capital of a run starts the next word, as in HTTPServer
+		if index > 0 && index+1 < len(runes) && unicode.IsLower(runes[index+1]) {
+			break
+		}
+		runes[index] = unicode.ToLower(r)
+	}
+	return string(runes)
+}
+
+// Bind hands a pointer to a Go struct to scripts, as a host object. Scripts
+// read and write its exported fields and call the exported methods of the
+// pointer with '.', converting values like Define does. A struct in a field
+// is bound in turn, so 'config.server.port = 80' changes the Go struct.
+// Objects cannot get new properties, and methods cannot be assigned to.
+func Bind(pointer any, options BindOptions) (Value, error) {
+	return bind(reflect.ValueOf(pointer), options)
+}
+
+// member is a field or method of a host object. index is the path to the
+// field, or the index of the method.
+type member struct {
+	goName string
+	method bool
+	index  []int
+}
+
+// hostObject is a bound struct.
+type hostObject struct {
+	pointer reflect.Value
+	options BindOptions
+	members map[string]member
+}
+
+func bind(pointer reflect.Value, options BindOptions) (Value, error) {
+	if pointer.Kind() != reflect.Pointer || pointer.Type().Elem().Kind() != reflect.Struct {
+		return Nil, fmt.Errorf("cannot bind %s, which is not a pointer to a struct", typeName(pointer))
+	}
+	if pointer.IsNil() {
+		return Nil, nil
+	}
+	if options.Name == nil {
+		options.Name = LowerCamelCase
+	}
+	object := &hostObject{pointer: pointer, options: options, members: make(map[string]member)}
+	allowed := func(goName string) bool {
+		return len(options.Allow) == 0 || slices.Contains(options.Allow, goName)
+	}
+	add := func(m member) error {
+		if !allowed(m.goName) {
+			return nil
+		}
+		name := options.Name(m.goName)
+		if other, ok := object.members[name]; ok {
+			return fmt.Errorf("cannot bind %s: both %s and %s are called %q", pointer.Type(), other.goName, m.goName, name)
+		}
+		object.members[name] = m
+		return nil
+	}
+	for _, field := range reflect.VisibleFields(pointer.Type().Elem()) {
+		if field.IsExported() && !field.Anonymous {
+			if err := add(member{goName: field.Name, index: field.Index}); err != nil {
+				return Nil, err
+			}
+		}
+	}
+	for index := range pointer.NumMethod() {
+		if err := add(member{goName: pointer.Type().Method(index).Name, method: true, index: []int{index}}); err != nil {
+			return Nil, err
+		}
+	}
+	return interpreter.HostValue(object), nil
+}
+
+func (object *hostObject) Get(name string) (Value, bool, error) {
+	m, ok := object.members[name]
+	if !ok {
+		return Nil, false, nil
+	}
+	if m.method {
+		value, err := nativeOf(name, object.pointer.Method(m.index[0]), object.options)
+		return value, true, err
+	}
+	field, err := object.pointer.Elem().FieldByIndexErr(m.index)
+	if err != nil {
+		return Nil, true, err
+	}
+	value, err := valueOf(field, object.options)
+	return value, true, err
+}
+
+func (object *hostObject) Set(name string, value Value) (bool, error) {
+	m, ok := object.members[name]
+	if !ok {
+		return false, nil
+	}
+	if m.method {
+		return true, fmt.Errorf("cannot assign to %s, which is a method", name)
+	}
+	field, err := object.pointer.Elem().FieldByIndexErr(m.index)
+	if err != nil {
+		return true, err
+	}
+	return true, convert(value, field)
+}
+
+// String is how 'print' shows the object: with its String method when it
+// has one, and by its type otherwise.
+func (object *hostObject) String() string {
+	if stringer, ok := object.pointer.Interface().(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return strings.TrimPrefix(object.pointer.Type().String(), "*") + " object"
+}
+
+// typeName names the type of a Go value in errors, which may be invalid.
+func typeName(goValue reflect.Value) string {
+	if !goValue.IsValid() {
+		return "nil"
+	}
+	return goValue.Type().String()
+}
@@ -0,0 +1,137 @@
+// Package lox embeds the language in Go programs. An Interpreter runs
+// sources one after the other, and what one of them defines is still there
+// for the next, so a host can load a script once and then call into it:
+//
+//	interpreter := lox.New(lox.Options{})
+//	_, err := interpreter.Eval(ctx, "fun double(n) { return n * 2; }")
+//	value, err := interpreter.Eval(ctx, "double(21);") // 42
+//
+// Sources run on the tree-walking interpreter. An Interpreter must not be
+// used from several goroutines at once.
+package lox
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/go-interpreter/internal/analysis"
+	"github.com/go-interpreter/internal/ast"
+	"github.com/go-interpreter/internal/errors"
+	"github.com/go-interpreter/internal/interpreter"
+)
+
+// DEFAULT_FILE is what errors call a source evaluated without a file name.
+const DEFAULT_FILE = "<eval>"
+
+// Options configures an Interpreter. The zero value is ready to use.
+type Options struct {
+	// File is the name errors and stack traces give the sources passed to
+	// Eval. It defaults to DEFAULT_FILE.
+	File string
+	// Stdout is where 'print' writes to, standard output by default.
+	// Nothing else is ever written to it.
+	Stdout io.Writer
+	// Stdin is what the builtin function readLine reads, returning the
+	// next line without its line ending, or nil at the end of the input.
+	// Without it, readLine always returns nil; standard input is never
+	// read unless it is passed here.
+	Stdin io.Reader
+}
+
+// Interpreter runs sources and keeps their global definitions.
+type Interpreter struct {
+	options     Options
+	interpreter interpreter.Interpreter
+}
+
+// New returns an Interpreter with nothing defined yet.
+func New(options Options) *Interpreter {
+	if options.File == "" {
+		options.File = DEFAULT_FILE
+	}
+	if options.Stdin == nil {
+		options.Stdin = strings.NewReader("")
+	}
+	lox := &Interpreter{options: options, interpreter: interpreter.NewInterpreter()}
+	if options.Stdout != nil {
+		lox.interpreter.SetOutput(options.Stdout)
+	}
+	lox.interpreter.SetInput(options.Stdin)
+	return lox
+}
+
+// Eval runs a source and returns the value of its last statement when that
+// is an expression, as in "double(21);", and Nil otherwise. Nothing runs
+// when the source has syntax or resolution errors. A runtime error stops
+// the source where it happened, leaving whatever it defined up to there.
+// The errors returned are *Error. Once ctx is done, the source is stopped
+// at the next loop iteration or call.
+func (lox *Interpreter) Eval(ctx context.Context, source string) (Value, error) {
+	return lox.eval(ctx, lox.options.File, source)
+}
+
+// RunFile reads the program in the file at path and runs it, like Eval.
+// Errors name the file by its path.
+func (lox *Interpreter) RunFile(ctx context.Context, path string) error {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot read the program: %w", err)
+	}
+	_, err = lox.eval(ctx, path, string(source))
+	return err
+}
+
+// Get returns the value of a global variable, function or class, and
+// whether it is defined.
+func (lox *Interpreter) Get(name string) (Value, bool) {
+	value, ok := lox.interpreter.Globals().Values[name]
+	return value, ok
+}
+
+// Set defines a global variable, or changes its value when it already
+// exists.
+func (lox *Interpreter) Set(name string, value Value) {
+	lox.interpreter.Globals().Define(name, value)
+}
+
+// Globals returns the names of every global, in order.
+func (lox *Interpreter) Globals() []string {
+	names := make([]string, 0, len(lox.interpreter.Globals().Values))
+	for name := range lox.interpreter.Globals().Values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (lox *Interpreter) eval(ctx context.Context, file string, source string) (Value, error) {
+	stmts, diagnostics := lox.analyse(file, source)
+	if len(diagnostics) > 0 {
+		return Nil, newError(file, diagnostics)
+	}
+	lox.interpreter.SetFile(file)
+	lox.interpreter.SetContext(ctx)
+	defer lox.interpreter.SetContext(nil)
+	value := Nil
+	for _, stmt := range stmts {
+		var err error
+		if exprStmt, ok := stmt.(ast.ExpressionStmt); ok {
+			value, err = lox.interpreter.Evaluate(exprStmt.Expression)
+		} else {
+			value, err = Nil, lox.interpreter.Execute(stmt)
+		}
+		if err != nil {
+			return Nil, runtimeError(ctx, file, err)
+		}
+	}
+	return value, nil
+}
+
+// analyse scans, parses and resolves a source.
+func (lox *Interpreter) analyse(file string, source string) ([]ast.Stmt, []errors.ExecutionError) {
+	return analysis.Analyse(analysis.Program{File: file, Source: source}, &lox.interpreter)
+}
@@ -0,0 +1,92 @@
+package lox
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/go-interpreter/internal/interpreter"
+)
+
+// VARIADIC is the arity of a function that takes any number of arguments.
+const VARIADIC = interpreter.VARIADIC
+
+// Function is the Go side of a native function. It gets the arguments of
+// a call, as many as the arity of the function. An error it returns stops
+// the script with a runtime error at the call.
+type Function func(arguments []Value) (Value, error)
+
+// DefineFunction defines a global function that runs a Go function. arity
+// is the number of arguments it takes, or VARIADIC.
+func (lox *Interpreter) DefineFunction(name string, arity int, function Function) {
+	lox.Set(name, interpreter.CallableValue(interpreter.NewNativeFunction(name, arity, function)))
+}
+
+// Define defines a global from a Go value, converted with ValueOf.
+//
+// Any Go function can be defined, and calling it from a script converts
+// the arguments to the types of its parameters with Convert, and its result
+// back with ValueOf. A variadic Go function takes any number of arguments
+// past its fixed ones. The function may return nothing, a value, an error,
+// or a value and an error, which then stops the script:
+//
+//	lox.Define("repeat", func(s string, times int) (string, error) { ... })
+func (lox *Interpreter) Define(name string, goValue any) error {
+	var value Value
+	var err error
+	if function := reflect.ValueOf(goValue); function.Kind() == reflect.Func && !function.IsNil() {
+		value, err = nativeOf(name, function, BindOptions{})
+	} else {
+		value, err = ValueOf(goValue)
+	}
+	if err != nil {
+		return err
+	}
+	lox.Set(name, value)
+	return nil
+}
+
+// nativeOf wraps a Go function into a native function, whose results bind
+// structs with options.
+func nativeOf(name string, function reflect.Value, options BindOptions) (Value, error) {
+	functionType := function.Type()
+	results := functionType.NumOut()
+	returnsError := results > 0 && functionType.Out(results-1) == errorType
+	if results > 2 || (results == 2 && !returnsError) {
+		return Nil, fmt.Errorf("cannot define %s: a native function returns a value, an error, or both", functionType)
+	}
+	params := functionType.NumIn()
+	arity := params
+	if functionType.IsVariadic() {
+		arity = VARIADIC
+	}
+	call := func(arguments []Value) (Value, error) {
+		if functionType.IsVariadic() && len(arguments) < params-1 {
+			return Nil, fmt.Errorf("expected at least %d arguments but got %d", params-1, len(arguments))
+		}
+		in := make([]reflect.Value, len(arguments))
+		for index, argument := range arguments {
+			var paramType reflect.Type
+			if functionType.IsVariadic() && index >= params-1 {
+				paramType = functionType.In(params - 1).Elem()
+			} else {
+				paramType = functionType.In(index)
+			}
+			in[index] = reflect.New(paramType).Elem()
+			if err := convert(argument, in[index]); err != nil {
+				return Nil, fmt.Errorf("argument %d: %w", index+1, err)
+			}
+		}
+		out := function.Call(in)
+		if returnsError {
+			if err, _ := out[len(out)-1].Interface().(error); err != nil {
+				return Nil, err
+			}
+			out = out[:len(out)-1]
+		}
+		if len(out) == 0 {
+			return Nil, nil
+		}
+		return valueOf(out[0], options)
+	}
+	return interpreter.CallableValue(interpreter.NewNativeFunction(name, arity, call)), nil
+}
@@ -0,0 +1,41 @@
+package lox
+
+import "github.com/go-interpreter/internal/interpreter"
+
+// Value is a value of the language: nil, a boolean, a number, a string, a
+// function, class or instance, or a host object such as a list or a bound
+// struct. Kind tells which, and the As methods unwrap it; String formats it
+// the way 'print' does.
+type Value = interpreter.Value
+
+// ValueKind tells which kind of value a Value is.
+type ValueKind = interpreter.ValueKind
+
+const (
+	NIL_VALUE      = interpreter.NIL_VALUE
+	BOOL_VALUE     = interpreter.BOOL_VALUE
+	NUMBER_VALUE   = interpreter.NUMBER_VALUE
+	STRING_VALUE   = interpreter.STRING_VALUE
+	FUNCTION_VALUE = interpreter.FUNCTION_VALUE
+	CLASS_VALUE    = interpreter.CLASS_VALUE
+	INSTANCE_VALUE = interpreter.INSTANCE_VALUE
+	HOST_VALUE     = interpreter.HOST_VALUE
+)
+
+// Nil is the nil value, which is also the zero Value.
+var Nil = interpreter.Nil
+
+// Bool returns a boolean value.
+func Bool(b bool) Value {
+	return interpreter.BoolValue(b)
+}
+
+// Number returns a number value.
+func Number(number float64) Value {
+	return interpreter.NumberValue(number)
+}
+
+// String returns a string value.
+func String(s string) Value {
+	return interpreter.StringValue(s)
+}
@@ -0,0 +1,169 @@
+package ast
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-interpreter/internal/ast"
+	"github.com/go-interpreter/internal/printer"
+	"github.com/go-interpreter/internal/testutil"
+	"github.com/go-interpreter/internal/token"
+	"github.com/stretchr/testify/assert"
+)
+
+// kind names a node by its type, e.g. ast.Binary.
+func kind(node ast.Node) string {
+	return fmt.Sprintf("%T", node)
+}
+
+func TestWalk(t *testing.T) {
+	stmts := testutil.Parse(t, "var a = 1 + 2; if (a) print a; else { a = f(a); }")
+	var pre, post []string
+	ast.Walk(stmts, func(node ast.Node) bool {
+		pre = append(pre, kind(node))
+		return true
+	}, func(node ast.Node) {
+		post = append(post, kind(node))
+	})
+	assert.Equal(t, []string{
+		"ast.VarStmt", "ast.Binary", "ast.Literal", "ast.Literal",
+		"ast.IfStmt", "ast.Variable", "ast.PrintStmt", "ast.Variable",
+		"ast.Block", "ast.ExpressionStmt", "ast.Assign", "ast.Call", "ast.Variable", "ast.Variable",
+	}, pre)
+	assert.Equal(t, []string{
+		"ast.Literal", "ast.Literal", "ast.Binary", "ast.VarStmt",
+		"ast.Variable", "ast.Variable", "ast.PrintStmt",
+		"ast.Variable", "ast.Variable", "ast.Call", "ast.Assign", "ast.ExpressionStmt", "ast.Block", "ast.IfStmt",
+	}, post)
+}
+
+func TestInspect(t *testing.T) {
+	stmts := testutil.Parse(t, "class A < B { get() { return this.x; } } fun f() { var y; } print z; w.n++;")
+	tests := []struct {
+		name string
+		skip string
+		want []string
+	}{
+		{name: "every variable", want: []string{"B", "z", "w"}},
+		{name: "functions skipped", skip: "ast.ClassStmt", want: []string{"z", "w"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			ast.Inspect(stmts, func(node ast.Node) bool {
+				if variable, ok := node.(ast.Variable); ok {
+					names = append(names, variable.Name.Lexeme)
+				}
+				return kind(node) != tt.skip
+			})
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		rewrite func(ast.Node) ast.Node
+		want    string
+	}{
+		{
+			name:   "folding constants bottom up",
+			source: "print (1 + 2) * 3 + a;",
+			rewrite: func(node ast.Node) ast.Node {
+				if grouping, ok := node.(ast.Grouping); ok {
+					if literal, ok := grouping.Expression.(ast.Literal); ok {
+						return literal
+					}
+				}
+				binary, ok := node.(ast.Binary)
+				if !ok {
+					return node
+				}
+				left, leftOk := binary.Left.(ast.Literal)
+				right, rightOk := binary.Right.(ast.Literal)
+				if !leftOk || !rightOk {
+					return node
+				}
+				switch binary.Operator.Type {
+				case token.PLUS:
+					return ast.Literal{Value: left.Value.(float64) + right.Value.(float64)}
+				case token.STAR:
+					return ast.Literal{Value: left.Value.(float64) * right.Value.(float64)}
+				}
+				return node
+			},
+			want: "(print (+ 9 a))\n",
+		},
+		{
+			name:   "removing statements and optional children",
+			source: "fun f() { print a; return 2; } if (a) { print b; } else print c; var b = 5;",
+			rewrite: func(node ast.Node) ast.Node {
+				switch node.(type) {
+				case ast.PrintStmt, ast.Literal:
+					return nil
+				}
+				return node
+			},
+			want: "(fun f () (return))\n(if a (block))\n(var b)\n",
+		},
+		{
+			name:   "renaming a variable everywhere",
+			source: "class A < B { m() { return B; } }",
+			rewrite: func(node ast.Node) ast.Node {
+				if variable, ok := node.(ast.Variable); ok && variable.Name.Lexeme == "B" {
+					variable.Name.Lexeme = "C"
+					return variable
+				}
+				return node
+			},
+			want: "(class A (< C) (fun m () (return C)))\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmts := testutil.Parse(t, tt.source)
+			before := printer.Print(printer.SEXPR_FORMAT, stmts)
+			rewritten := ast.Rewrite(stmts, tt.rewrite).([]ast.Stmt)
+			assert.Equal(t, tt.want, printer.Print(printer.SEXPR_FORMAT, rewritten))
+			// The original tree is left as it was
+			assert.Equal(t, before, printer.Print(printer.SEXPR_FORMAT, stmts))
+		})
+	}
+}
+
+func TestRewrite_InvalidReplacement(t *testing.T) {
+	tests := []struct {
+		name    string
+		rewrite func(ast.Node) ast.Node
+		want    string
+	}{
+		{
+			name: "an expression replaced by a statement",
+			rewrite: func(node ast.Node) ast.Node {
+				if literal, ok := node.(ast.Literal); ok {
+					return ast.PrintStmt{Expression: literal}
+				}
+				return node
+			},
+			want: "ast: an expression cannot be replaced by ast.PrintStmt",
+		},
+		{
+			name: "an expression removed",
+			rewrite: func(node ast.Node) ast.Node {
+				if _, ok := node.(ast.Binary); ok {
+					return nil
+				}
+				return node
+			},
+			want: "ast: an expression cannot be replaced by <nil>",
+		},
+	}
+	stmts := testutil.Parse(t, "print 1 + 2;")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.PanicsWithValue(t, tt.want, func() { ast.Rewrite(stmts, tt.rewrite) })
+		})
+	}
+}
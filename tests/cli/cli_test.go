@@ -0,0 +1,234 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-interpreter/internal/cli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "main.lox")
+	assert.NoError(t, os.WriteFile(file, []byte("var a = 1;\n"), 0o644))
+	var locals strings.Builder
+	for n := 0; n < 300; n++ {
+		fmt.Fprintf(&locals, "var v%d = %d;\n", n, n)
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		stdin      string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{
+			name:       "help lists the commands",
+			args:       []string{"--help"},
+			wantCode:   cli.EXIT_OK,
+			wantStdout: "  tokens   print the tokens the scanner produces for a program\n",
+		},
+		{
+			name:       "help of a command shows its flags",
+			args:       []string{"help", "run"},
+			wantCode:   cli.EXIT_OK,
+			wantStdout: "usage: go-interpreter run [flags] <file>\n",
+		},
+		{
+			name:       "flags ask for help too",
+			args:       []string{"check", "-h"},
+			wantCode:   cli.EXIT_OK,
+			wantStdout: "  -diagnostics string\n",
+		},
+		{
+			name:       "tokens reads the program from stdin",
+			args:       []string{"tokens", "-"},
+			stdin:      "var a;",
+			wantCode:   cli.EXIT_OK,
+			wantStdout: "1:1\tVAR           var\n1:5\tIDENTIFIER    a\n1:6\tSEMICOLON     ;\n1:7\tEOF           \n",
+		},
+		{
+			name:       "tokens reports scanner errors",
+			args:       []string{"tokens", "-"},
+			stdin:      "var a = @;",
+			wantCode:   cli.EXIT_PROGRAM_ERROR,
+			wantStderr: "Scanner Error[E0001]: Unexpected character '@'.\n",
+		},
+		{
+			name:       "ast prints the statements",
+			args:       []string{"ast", "-"},
+			stdin:      "print 1;",
+			wantCode:   cli.EXIT_OK,
+			wantStdout: "Print\n  Literal 1\n",
+		},
+		{
+			name:       "ast prints S-expressions",
+			args:       []string{"ast", "-format=sexpr", "-"},
+			stdin:      "print 1 + 2;",
+			wantCode:   cli.EXIT_OK,
+			wantStdout: "(print (+ 1 2))\n",
+		},
+		{
+			name:       "ast prints JSON",
+			args:       []string{"ast", "-format=json", "-"},
+			stdin:      "print nil;",
+			wantCode:   cli.EXIT_OK,
+			wantStdout: "    \"kind\": \"PrintStmt\",\n    \"span\": {\n      \"file\": \"<stdin>\",\n",
+		},
+		{
+			name:       "dot renders control-flow graphs",
+			args:       []string{"dot", "-cfg", "-"},
+			stdin:      "print 1;",
+			wantCode:   cli.EXIT_OK,
+			wantStdout: "    f0_b2 [label=\"(print 1)\\l\", shape=box];\n",
+		},
+		{
+			name:       "unknown tree formats are usage errors",
+			args:       []string{"ast", "-format=xml", "-"},
+			wantCode:   cli.EXIT_USAGE,
+			wantStderr: "unknown tree format \"xml\"\n",
+		},
+		{
+			name:     "check passes a correct file",
+			args:     []string{"check", file},
+			wantCode: cli.EXIT_OK,
+		},
+		{
+			name:       "check reports errors in the chosen format",
+			args:       []string{"check", "-diagnostics=json", "-"},
+			stdin:      "print 1",
+			wantCode:   cli.EXIT_PROGRAM_ERROR,
+			wantStderr: `{"rule":"E0102","severity":"error","file":"<stdin>",`,
+		},
+		{
+			name:       "run reports runtime errors",
+			args:       []string{"run", "-"},
+			stdin:      "var a = missing;",
+			wantCode:   cli.EXIT_RUNTIME_ERROR,
+			wantStderr: "Runtime Error[E0301]: Undefined variable missing.\n",
+		},
+		{
+			name:       "compile errors are program errors",
+			args:       []string{"run", "-backend=vm", "-"},
+			stdin:      "{\n" + locals.String() + "}\n",
+			wantCode:   cli.EXIT_PROGRAM_ERROR,
+			wantStderr: "Too many local variables in function.\n",
+		},
+		{
+			name:     "arguments without a command are those of run",
+			args:     []string{"-backend=vm", file},
+			wantCode: cli.EXIT_OK,
+		},
+		{
+			name:       "run needs a file",
+			args:       []string{"run"},
+			wantCode:   cli.EXIT_USAGE,
+			wantStderr: "run: expected one file, got 0\n",
+		},
+		{
+			name:       "unknown flags are usage errors",
+			args:       []string{"run", "-fast", file},
+			wantCode:   cli.EXIT_USAGE,
+			wantStderr: "flag provided but not defined: -fast\n",
+		},
+		{
+			name:       "unknown backends are usage errors",
+			args:       []string{"run", "-backend=jit", file},
+			wantCode:   cli.EXIT_USAGE,
+			wantStderr: "unknown backend \"jit\"\n",
+		},
+		{
+			name:       "missing files cannot be read",
+			args:       []string{"check", filepath.Join(t.TempDir(), "missing.lox")},
+			wantCode:   cli.EXIT_FAILURE,
+			wantStderr: "no such file or directory\n",
+		},
+		{
+			name:       "fmt prints the formatted program",
+			args:       []string{"fmt", "-"},
+			stdin:      "var a=1; // one\nprint a;",
+			wantCode:   cli.EXIT_OK,
+			wantStdout: "var a = 1; // one\nprint a;\n",
+		},
+		{
+			name:       "fmt does not format programs with errors",
+			args:       []string{"fmt", "-"},
+			stdin:      "print",
+			wantCode:   cli.EXIT_PROGRAM_ERROR,
+			wantStderr: "Syntax Error[E0101]",
+		},
+		{
+			name:       "explain describes a code",
+			args:       []string{"explain", "E0102"},
+			wantCode:   cli.EXIT_OK,
+			wantStdout: "E0102: missing semicolon\n",
+		},
+		{
+			name:       "explain rejects unknown codes",
+			args:       []string{"explain", "E9999"},
+			wantCode:   cli.EXIT_USAGE,
+			wantStderr: "unknown error code \"E9999\"",
+		},
+		{
+			name:       "repl evaluates its input",
+			args:       []string{"repl"},
+			stdin:      "1 + 2\n",
+			wantCode:   cli.EXIT_OK,
+			wantStdout: "3\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := cli.Run(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr)
+			assert.Equal(t, tt.wantCode, code, stderr.String())
+			assert.Contains(t, stdout.String(), tt.wantStdout)
+			assert.Contains(t, stderr.String(), tt.wantStderr)
+		})
+	}
+}
+
+func TestRun_FmtWrite(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "main.lox")
+	assert.NoError(t, os.WriteFile(file, []byte("print 1+2;"), 0o644))
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, cli.EXIT_OK, cli.Run([]string{"fmt", "-w", file}, strings.NewReader(""), &stdout, &stderr))
+	assert.Empty(t, stdout.String())
+	formatted, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Equal(t, "print 1 + 2;\n", string(formatted))
+}
+
+// TestRun_Output checks that a program writes exactly what it prints to
+// standard output, whichever backend runs it.
+func TestRun_Input(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "greet.lox")
+	assert.NoError(t, os.WriteFile(path, []byte(`print "hello " + readLine();`), 0o644))
+	for _, backend := range []string{"tree", "vm"} {
+		t.Run(backend, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := cli.Run([]string{"run", "-backend=" + backend, path}, strings.NewReader("Ada\n"), &stdout, &stderr)
+			assert.Equal(t, cli.EXIT_OK, code, stderr.String())
+			assert.Equal(t, "hello Ada", stdout.String())
+		})
+	}
+}
+
+func TestRun_Output(t *testing.T) {
+	for _, backend := range []string{"tree", "vm"} {
+		t.Run(backend, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := cli.Run([]string{"run", "-backend=" + backend, "-"}, strings.NewReader(`print "a"; print 1 + 2;`), &stdout, &stderr)
+			assert.Equal(t, cli.EXIT_OK, code, stderr.String())
+			assert.Equal(t, "a3", stdout.String())
+			assert.Empty(t, stderr.String())
+		})
+	}
+}
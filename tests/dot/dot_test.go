@@ -0,0 +1,126 @@
+package dot
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/go-interpreter/internal/dot"
+	"github.com/go-interpreter/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAST(t *testing.T) {
+	got := dot.AST(testutil.Parse(t, "if (a) print \"hi\"; else print -1;"))
+	assert.Equal(t, `digraph ast {
+  node [fontname="monospace"];
+  edge [fontname="monospace"];
+  ordering=out;
+  program [label="Program", shape=box, style=rounded];
+  n1 [label="If", shape=box];
+  n2 [label="Variable a", shape=ellipse];
+  n1 -> n2 [label="condition"];
+  n3 [label="Print", shape=box];
+  n4 [label="Literal \"hi\"", shape=ellipse];
+  n3 -> n4;
+  n1 -> n3 [label="then"];
+  n5 [label="Print", shape=box];
+  n6 [label="Unary -", shape=ellipse];
+  n7 [label="Literal 1", shape=ellipse];
+  n6 -> n7;
+  n5 -> n6;
+  n1 -> n5 [label="else"];
+  program -> n1;
+}
+`, got)
+}
+
+func TestCFG(t *testing.T) {
+	got := dot.CFG(testutil.Parse(t, "fun count(n) { for (var i = 0; i < n; i++) { if (i > 9) return; print i; } }"))
+	assert.Equal(t, `digraph cfg {
+  node [fontname="monospace"];
+  edge [fontname="monospace"];
+  subgraph cluster_0 {
+    label="<script>";
+    f0_b0 [label="entry", shape=oval];
+    f0_b1 [label="exit", shape=oval];
+    f0_b2 [label="fun count(n)\l", shape=box];
+    f0_b0 -> f0_b2;
+    f0_b2 -> f0_b1;
+  }
+  subgraph cluster_1 {
+    label="count";
+    f1_b0 [label="entry", shape=oval];
+    f1_b1 [label="exit", shape=oval];
+    f1_b2 [label="(var i 0)\l", shape=box];
+    f1_b3 [label="while (< i n)\l", shape=box];
+    f1_b5 [label="if (> i 9)\l", shape=box];
+    f1_b6 [label="(print i)\l(; (= i (++ i 1)))\l", shape=box];
+    f1_b7 [label="(return)\l", shape=box];
+    f1_b0 -> f1_b2;
+    f1_b2 -> f1_b3;
+    f1_b3 -> f1_b5 [label="true"];
+    f1_b3 -> f1_b1 [label="false"];
+    f1_b5 -> f1_b7 [label="true"];
+    f1_b5 -> f1_b6 [label="false"];
+    f1_b6 -> f1_b3;
+    f1_b7 -> f1_b1;
+  }
+}
+`, got)
+}
+
+func TestCFG_Jumps(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:   "break leaves the loop and continue goes back to its condition",
+			source: "while (true) { if (a) break; else continue; }",
+			want:   []string{`-> f0_b1 [label="break"]`, `-> f0_b2 [label="continue"]`},
+		},
+		{
+			name:    "a loop on true only ends with a break",
+			source:  "while (true) { print 1; } print 2;",
+			want:    []string{`-> f0_b4 [label="true"]`, `[label="(print 2)\l", shape=box, style=dashed]`},
+			notWant: []string{`[label="false"]`},
+		},
+		{
+			name:   "code after a return is dashed",
+			source: "fun f() { return 1; print 2; }",
+			want:   []string{`f1_b2 [label="(return 1)\l", shape=box]`, `[label="(print 2)\l", shape=box, style=dashed]`},
+		},
+		{
+			name:   "methods get a graph each",
+			source: "class A < B { init() {} get() { return this.x; } }",
+			want:   []string{`label="A.init"`, `label="A.get"`, `label="class A\l"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dot.CFG(testutil.Parse(t, tt.source))
+			for _, want := range tt.want {
+				assert.Contains(t, got, want)
+			}
+			for _, notWant := range tt.notWant {
+				assert.NotContains(t, got, notWant)
+			}
+			assertEdgesDeclared(t, got)
+		})
+	}
+}
+
+// assertEdgesDeclared checks that edges only join nodes written out, so
+// Graphviz does not add empty nodes of its own.
+func assertEdgesDeclared(t *testing.T, graph string) {
+	declared := map[string]bool{}
+	for _, match := range regexp.MustCompile(`(?m)^\s*(\w+) \[label=`).FindAllStringSubmatch(graph, -1) {
+		declared[match[1]] = true
+	}
+	for _, match := range regexp.MustCompile(`(\w+) -> (\w+)`).FindAllStringSubmatch(graph, -1) {
+		assert.True(t, declared[match[1]], match[1])
+		assert.True(t, declared[match[2]], match[2])
+	}
+}
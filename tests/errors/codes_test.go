@@ -0,0 +1,93 @@
+package errors
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-interpreter/internal/errors"
+	"github.com/go-interpreter/internal/interpreter"
+	"github.com/go-interpreter/internal/parser"
+	"github.com/go-interpreter/internal/resolver"
+	"github.com/go-interpreter/internal/scanner"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplain(t *testing.T) {
+	explanation, ok := errors.Explain("e0102")
+	assert.True(t, ok)
+	assert.Equal(t, errors.MISSING_SEMICOLON, explanation.Code)
+	assert.Equal(t, "E0102: missing semicolon\n\n"+
+		"Every statement that is not a block ends with ';'. The error points just\n"+
+		"past the token the semicolon should follow.\n\n"+
+		"Wrong:\n\n"+
+		"    var a = 1\n"+
+		"    print a;\n\n"+
+		"Correct:\n\n"+
+		"    var a = 1;\n"+
+		"    print a;\n",
+		explanation.String())
+
+	_, ok = errors.Explain("E9999")
+	assert.False(t, ok)
+}
+
+func TestCodes(t *testing.T) {
+	codes := errors.Codes()
+	assert.NotEmpty(t, codes)
+	for index, code := range codes {
+		explanation, ok := errors.Explain(string(code))
+		assert.True(t, ok)
+		assert.NotEmpty(t, explanation.Title, code)
+		assert.NotEmpty(t, explanation.Description, code)
+		if index > 0 {
+			assert.Less(t, codes[index-1], code)
+		}
+	}
+}
+
+// TestExplain_Examples runs the wrong example of every code that can be
+// written out in full, and checks that it fails with that very code.
+func TestExplain_Examples(t *testing.T) {
+	for _, code := range errors.Codes() {
+		explanation, _ := errors.Explain(string(code))
+		if explanation.Wrong == "" || strings.Contains(explanation.Wrong, "...") ||
+			strings.Contains(explanation.Wrong, "thousands") {
+			continue
+		}
+		t.Run(string(code), func(t *testing.T) {
+			assert.Equal(t, code, firstCode(explanation.Wrong))
+		})
+	}
+}
+
+func TestRenderer_Code(t *testing.T) {
+	renderer := errors.Renderer{File: "main.lox", Source: "print 1\n"}
+	got := renderer.Render(errors.ExecutionError{Type: errors.PARSER_ERROR, Code: errors.MISSING_SEMICOLON,
+		Line: 1, Column: 8, Message: "Expect ';' after value."})
+	assert.True(t, strings.HasPrefix(got, "Syntax Error[E0102]: Expect ';' after value.\n"), got)
+}
+
+func TestExecutionError_Rule(t *testing.T) {
+	assert.Equal(t, "E0301", errors.ExecutionError{Type: errors.RUNTIME_ERROR, Code: errors.UNDEFINED_VARIABLE}.Rule())
+	assert.Equal(t, "runtime-error", errors.ExecutionError{Type: errors.RUNTIME_ERROR}.Rule())
+}
+
+// firstCode returns the code of the first error the program runs into.
+func firstCode(source string) errors.Code {
+	tokenScanner := scanner.NewTokenScanner(source)
+	tokens, scanErrors := tokenScanner.ScanTokens()
+	if len(scanErrors) > 0 {
+		return scanErrors[0].Code
+	}
+	p := parser.NewParser(tokens)
+	stmts, parseErrors := p.Parse()
+	if len(parseErrors) > 0 {
+		return parseErrors[0].Code
+	}
+	inter := interpreter.NewInterpreter()
+	if resolveErrors := resolver.NewResolver(&inter).Resolve(stmts); len(resolveErrors) > 0 {
+		return resolveErrors[0].Code
+	}
+	executionError, _ := errors.AsExecutionError(inter.Interpret(stmts))
+	return executionError.Code
+}
@@ -0,0 +1,74 @@
+package errors
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-interpreter/internal/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderer_Render(t *testing.T) {
+	source := "var a = 1;\n\tprint a + nope;\nprint 1\n"
+	tests := []struct {
+		name string
+		err  errors.ExecutionError
+		want string
+	}{
+		{
+			name: "underlines the token and shows the hint",
+			err: errors.ExecutionError{Type: errors.RUNTIME_ERROR, Line: 2, Column: 12, Length: 4,
+				Message: "Undefined variable nope.", Hint: "declare it first"},
+			want: "Runtime Error: Undefined variable nope.\n" +
+				" --> main.lox:2:12\n" +
+				"  |\n" +
+				"2 | \tprint a + nope;\n" +
+				"  | \t          ^^^^\n" +
+				"  = hint: declare it first\n",
+		},
+		{
+			name: "never underlines past the end of the line",
+			err: errors.ExecutionError{Type: errors.PARSER_ERROR, Line: 3, Column: 8, Length: 3,
+				Message: "Expect ';' after value."},
+			want: "Syntax Error: Expect ';' after value.\n" +
+				" --> main.lox:3:8\n" +
+				"  |\n" +
+				"3 | print 1\n" +
+				"  |        ^\n",
+		},
+		{
+			name: "lines outside the source are not quoted",
+			err:  errors.ExecutionError{Type: errors.RUNTIME_ERROR, Line: 42, Column: 1, Message: "Oops."},
+			want: "Runtime Error: Oops.\n" +
+				"  --> main.lox:42:1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer := errors.Renderer{File: "main.lox", Source: source}
+			assert.Equal(t, tt.want, renderer.Render(tt.err))
+		})
+	}
+}
+
+func TestRenderer_Color(t *testing.T) {
+	renderer := errors.Renderer{File: "main.lox", Source: "print 1\n", Color: true}
+	got := renderer.Render(errors.ExecutionError{Type: errors.PARSER_ERROR, Line: 1, Column: 8, Message: "Expect ';' after value."})
+	assert.Contains(t, got, errors.RED+"Syntax Error"+errors.RESET)
+	assert.Contains(t, got, errors.RED+"^"+errors.RESET)
+}
+
+func TestRenderer_StackTrace(t *testing.T) {
+	trace := make([]errors.Frame, 0, 25)
+	for range 24 {
+		trace = append(trace, errors.Frame{Function: "loop", File: "main.lox", Line: 2, Column: 10})
+	}
+	trace = append(trace, errors.Frame{Function: "<script>", File: "main.lox", Line: 4, Column: 5})
+	renderer := errors.Renderer{File: "main.lox"}
+	got := renderer.Render(errors.ExecutionError{Type: errors.RUNTIME_ERROR, Line: 2, Column: 10, Message: "Oops.", Trace: trace})
+
+	assert.Contains(t, got, "stack trace:\n  at loop (main.lox:2:10)\n")
+	assert.Contains(t, got, "  ... 5 more frames ...\n")
+	assert.Contains(t, got, "  at <script> (main.lox:4:5)\n")
+	assert.Equal(t, 20, strings.Count(got, "\n  at "))
+}
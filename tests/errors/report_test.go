@@ -0,0 +1,95 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-interpreter/internal/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var diagnostics = []errors.ExecutionError{
+	{Type: errors.PARSER_ERROR, Line: 1, Column: 8, Length: 1, Message: "Expect ';' after value."},
+	{Type: errors.RUNTIME_ERROR, Line: 3, Column: 10, Length: 4, Message: "Undefined variable nope.",
+		Hint: "declare it first", Trace: []errors.Frame{
+			{Function: "f", File: "main.lox", Line: 3, Column: 10},
+			{Function: "<script>", File: "main.lox", Line: 5, Column: 2},
+		}},
+}
+
+func TestReporter_JSON(t *testing.T) {
+	var out bytes.Buffer
+	reporter := errors.NewReporter(errors.JSON_FORMAT, &out, "main.lox", "", false)
+	for _, diagnostic := range diagnostics {
+		reporter.Report(diagnostic)
+	}
+	assert.NoError(t, reporter.Flush())
+
+	assert.Equal(t,
+		`{"rule":"syntax-error","severity":"error","file":"main.lox",`+
+			`"range":{"start":{"line":1,"column":8},"end":{"line":1,"column":9}},"message":"Expect ';' after value."}`+"\n"+
+			`{"rule":"runtime-error","severity":"error","file":"main.lox",`+
+			`"range":{"start":{"line":3,"column":10},"end":{"line":3,"column":14}},"message":"Undefined variable nope.",`+
+			`"hint":"declare it first","trace":[{"function":"f","file":"main.lox","line":3,"column":10},`+
+			`{"function":"<script>","file":"main.lox","line":5,"column":2}]}`+"\n",
+		out.String())
+}
+
+func TestReporter_SARIF(t *testing.T) {
+	var out bytes.Buffer
+	reporter := errors.NewReporter(errors.SARIF_FORMAT, &out, "main.lox", "", false)
+	for _, diagnostic := range diagnostics {
+		reporter.Report(diagnostic)
+	}
+	reporter.Report(errors.ExecutionError{Type: errors.PARSER_ERROR, Line: 2, Column: 1, Message: "Unexpected end of input."})
+	// Nothing is written until the log is complete
+	assert.Empty(t, out.String())
+	assert.NoError(t, reporter.Flush())
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name  string `json:"name"`
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string                `json:"ruleId"`
+				Level     string                `json:"level"`
+				Message   struct{ Text string } `json:"message"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct{ URI string } `json:"artifactLocation"`
+						Region           struct {
+							StartLine, StartColumn, EndLine, EndColumn int
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	assert.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+	assert.Equal(t, "go-interpreter", run.Tool.Driver.Name)
+	// Every rule is described once
+	assert.Len(t, run.Tool.Driver.Rules, 2)
+	assert.Len(t, run.Results, 3)
+
+	result := run.Results[1]
+	assert.Equal(t, "runtime-error", result.RuleID)
+	assert.Equal(t, "error", result.Level)
+	assert.Equal(t, "Undefined variable nope. (hint: declare it first)", result.Message.Text)
+	location := result.Locations[0].PhysicalLocation
+	assert.Equal(t, "main.lox", location.ArtifactLocation.URI)
+	assert.Equal(t, 3, location.Region.StartLine)
+	assert.Equal(t, 10, location.Region.StartColumn)
+	assert.Equal(t, 3, location.Region.EndLine)
+	assert.Equal(t, 14, location.Region.EndColumn)
+}
@@ -0,0 +1,120 @@
+package formatter
+
+import (
+	"os"
+	"testing"
+
+	"github.com/go-interpreter/internal/errors"
+	"github.com/go-interpreter/internal/formatter"
+	"github.com/go-interpreter/internal/scanner"
+	"github.com/go-interpreter/internal/token"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "spacing and one statement per line",
+			source: "var a=1;print a+2*-a;a=a-1;",
+			want:   "var a = 1;\nprint a + 2 * -a;\na = a - 1;\n",
+		},
+		{
+			name:   "blocks are indented with braces on the line they belong to",
+			source: "fun add(a,b)\n{\nreturn a+b;\n}\nwhile(true){if(add(1,2)>3){print \"big\";}else{print \"small\";}}",
+			want: "fun add(a, b) {\n\treturn a + b;\n}\n" +
+				"while (true) {\n\tif (add(1, 2) > 3) {\n\t\tprint \"big\";\n\t} else {\n\t\tprint \"small\";\n\t}\n}\n",
+		},
+		{
+			name:   "bodies that are not blocks go on the next line",
+			source: "if (a) print 1; else if (b) print 2; else print 3;",
+			want:   "if (a)\n\tprint 1;\nelse if (b)\n\tprint 2;\nelse\n\tprint 3;\n",
+		},
+		{
+			name:   "comments stay where they were",
+			source: "// leading\nvar a = 1;   // trailing\n{\n  // inside\n}\nprint a;\n// at the end\n",
+			want:   "// leading\nvar a = 1; // trailing\n{\n\t// inside\n}\nprint a;\n// at the end\n",
+		},
+		{
+			name:   "runs of blank lines become one",
+			source: "var a;\n\n\n\nvar b;\nvar c;",
+			want:   "var a;\n\nvar b;\nvar c;\n",
+		},
+		{
+			name:   "for loops are printed as written, not desugared",
+			source: "for(var i=0;i<3;i++){print i;}\nfor(;;)print 1;\nfor(i=0;;i=i+1;)print i;",
+			want: "for (var i = 0; i < 3; i++) {\n\tprint i;\n}\n" +
+				"for (;;)\n\tprint 1;\n" +
+				"for (i = 0;; i = i + 1)\n\tprint i;\n",
+		},
+		{
+			name:   "literals keep their spelling",
+			source: "print 1.50 + 2;print \"tab\\there\";print nil;print !false;",
+			want:   "print 1.50 + 2;\nprint \"tab\\there\";\nprint nil;\nprint !false;\n",
+		},
+		{
+			name:   "classes",
+			source: "class A{}\nclass B<A{\ninit(x){this.x=x;this.x++;}\n\nget(){return super.get();}}",
+			want: "class A {}\nclass B < A {\n\tinit(x) {\n\t\tthis.x = x;\n\t\tthis.x++;\n\t}\n\n" +
+				"\tget() {\n\t\treturn super.get();\n\t}\n}\n",
+		},
+		{
+			name:   "negations are not merged into a decrement",
+			source: "print - -1;",
+			want:   "print - -1;\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, diagnostics := formatter.Format(tt.source)
+			assert.Empty(t, diagnostics)
+			assert.Equal(t, tt.want, got)
+			// Formatting is idempotent
+			again, _ := formatter.Format(got)
+			assert.Equal(t, got, again)
+		})
+	}
+}
+
+func TestFormat_SyntaxErrors(t *testing.T) {
+	got, diagnostics := formatter.Format("print 1")
+	assert.Empty(t, got)
+	assert.Len(t, diagnostics, 1)
+	assert.Equal(t, errors.MISSING_SEMICOLON, diagnostics[0].Code)
+}
+
+// TestFormat_RoundTrip checks that formatting the examples only changes
+// the whitespace between their tokens, so the parser builds the same tree
+// from the result.
+func TestFormat_RoundTrip(t *testing.T) {
+	source, err := os.ReadFile("../../examples/program.txt")
+	assert.NoError(t, err)
+	got, diagnostics := formatter.Format(string(source))
+	assert.Empty(t, diagnostics)
+
+	want := lexemes(string(source))
+	// The example still ends the increment of its for loop with a ';',
+	// which is dropped
+	for index := 0; index+1 < len(want); index++ {
+		if want[index] == "SEMICOLON ;" && want[index+1] == "RIGHT_PAREN )" {
+			want = append(want[:index], want[index+1:]...)
+		}
+	}
+	assert.Equal(t, want, lexemes(got))
+}
+
+func lexemes(source string) []string {
+	tokenScanner := scanner.NewTokenScanner(source)
+	tokens, _ := tokenScanner.ScanTokens()
+	lexemes := make([]string, 0, len(tokens))
+	for _, tok := range tokens {
+		lexemes = append(lexemes, tok.Type.String()+" "+tok.Lexeme)
+	}
+	for _, comment := range tokenScanner.Comments {
+		lexemes = append(lexemes, token.COMMENT.String()+" "+comment.Lexeme)
+	}
+	return lexemes
+}
@@ -0,0 +1,70 @@
+package interpreter
+
+import (
+	"testing"
+
+	"github.com/go-interpreter/internal/errors"
+	"github.com/go-interpreter/internal/interpreter"
+	"github.com/go-interpreter/internal/parser"
+	"github.com/go-interpreter/internal/resolver"
+	"github.com/go-interpreter/internal/scanner"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpreter_StackTrace(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []errors.Frame
+	}{
+		{
+			name:   "top-level error",
+			source: "print nope;",
+			want:   []errors.Frame{{Function: "<script>", File: "main.lox", Line: 1, Column: 7}},
+		},
+		{
+			name: "nested calls",
+			source: `fun inner() {
+  return nope;
+}
+fun outer() {
+  return inner();
+}
+print outer();`,
+			want: []errors.Frame{
+				{Function: "inner", File: "main.lox", Line: 2, Column: 10},
+				{Function: "outer", File: "main.lox", Line: 5, Column: 16},
+				{Function: "<script>", File: "main.lox", Line: 7, Column: 13},
+			},
+		},
+		{
+			name: "methods and initializers",
+			source: `class Point {
+  init() { this.x = 1 + nil; }
+}
+Point();`,
+			want: []errors.Frame{
+				{Function: "Point", File: "main.lox", Line: 2, Column: 23},
+				{Function: "<script>", File: "main.lox", Line: 4, Column: 7},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokenScanner := scanner.NewTokenScanner(tt.source)
+			tokens, scanErrors := tokenScanner.ScanTokens()
+			assert.Empty(t, scanErrors)
+			p := parser.NewParser(tokens)
+			stmts, parseErrors := p.Parse()
+			assert.Empty(t, parseErrors)
+			inter := interpreter.NewInterpreter()
+			inter.SetFile("main.lox")
+			assert.Empty(t, resolver.NewResolver(&inter).Resolve(stmts))
+
+			err := inter.Interpret(stmts)
+			executionError, ok := errors.AsExecutionError(err)
+			assert.True(t, ok)
+			assert.Equal(t, tt.want, executionError.Trace)
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package interpreter
+
+import (
+	"testing"
+
+	"github.com/go-interpreter/internal/ast"
+	"github.com/go-interpreter/internal/interpreter"
+	"github.com/go-interpreter/internal/parser"
+	"github.com/go-interpreter/internal/resolver"
+	"github.com/go-interpreter/internal/scanner"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClass_Call(t *testing.T) {
+	tokenScanner := scanner.NewTokenScanner(`
+class Point {
+  init(x, y) { this.x = x; this.y = y; return; }
+  sum() { return this.x + this.y; }
+}`)
+	tokens, scanErrors := tokenScanner.ScanTokens()
+	assert.Empty(t, scanErrors)
+	p := parser.NewParser(tokens)
+	stmts, parseErrors := p.Parse()
+	assert.Empty(t, parseErrors)
+	inter := interpreter.NewInterpreter()
+	assert.Empty(t, resolver.NewResolver(&inter).Resolve(stmts))
+	assert.Len(t, stmts, 1)
+	declaration, ok := stmts[0].(ast.ClassStmt)
+	assert.True(t, ok)
+
+	env := interpreter.NewEnvironment(nil)
+	methods := make(map[string]*interpreter.Function)
+	for _, method := range declaration.Methods {
+		methods[method.Name.Lexeme] = interpreter.NewFunction(method, env, method.Name.Lexeme == "init")
+	}
+	class := interpreter.NewClass(declaration.Name.Lexeme, nil, methods)
+	assert.Equal(t, 2, class.Arity())
+
+	value, err := class.Call(&inter, []interpreter.Value{interpreter.NumberValue(1), interpreter.NumberValue(2)})
+	assert.NoError(t, err)
+	assert.True(t, value.IsInstance())
+	instance := value.AsInstance()
+	assert.Equal(t, interpreter.NumberValue(1), instance.Fields["x"])
+
+	sum, err := methods["sum"].Bind(instance).Call(&inter, []interpreter.Value{})
+	assert.NoError(t, err)
+	assert.Equal(t, interpreter.NumberValue(3), sum)
+
+	// Calling init directly hands back the instance
+	again, err := methods["init"].Bind(instance).Call(&inter, []interpreter.Value{interpreter.NumberValue(5), interpreter.NumberValue(5)})
+	assert.NoError(t, err)
+	assert.Same(t, instance, again.AsInstance())
+}
+
+func TestClass_Inheritance(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		wantErr string
+	}{
+		{
+			name: "super calls walk up the chain",
+			source: `
+class A { init(n) { this.n = n; } name() { return "A"; } }
+class B < A { name() { return "B" + super.name(); } }
+class C < B {}
+var c = C(1);
+c.name();`,
+		},
+		{
+			name:    "inheriting from a non-class",
+			source:  "var A = 1; class B < A {}",
+			wantErr: "Superclass must be a class.",
+		},
+		{
+			name:    "inheriting from itself",
+			source:  "class A < A {}",
+			wantErr: "A class can't inherit from itself.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokenScanner := scanner.NewTokenScanner(tt.source)
+			tokens, scanErrors := tokenScanner.ScanTokens()
+			assert.Empty(t, scanErrors)
+			p := parser.NewParser(tokens)
+			stmts, parseErrors := p.Parse()
+			assert.Empty(t, parseErrors)
+			inter := interpreter.NewInterpreter()
+			if resolverErrors := resolver.NewResolver(&inter).Resolve(stmts); len(resolverErrors) > 0 {
+				assert.ErrorContains(t, resolverErrors[0], tt.wantErr)
+				return
+			}
+			err := inter.Interpret(stmts)
+
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestClass_Increment(t *testing.T) {
+	tests := []struct {
+		name   string
+		result string
+		want   interpreter.Value
+	}{
+		{name: "adds one to the property", result: "box.n", want: interpreter.NumberValue(2)},
+		{name: "evaluates the object once", result: "k", want: interpreter.NumberValue(1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := `fun run() {
+  class Box {}
+  var box = Box();
+  box.n = 1;
+  var k = 0;
+  fun get() { k = k + 1; return box; }
+  get().n++;
+  return ` + tt.result + `;
+}`
+			inter := interpreter.NewInterpreter()
+			run := interpreter.NewFunction(parseFunction(t, &inter, source), interpreter.NewEnvironment(nil), false)
+			got, err := run.Call(&inter, []interpreter.Value{})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -44,26 +44,26 @@ func TestEnvironment_Define(t *testing.T) {
 	tests := []struct {
 		name     string
 		varName  string
-		value    any
-		wantType any
+		value    interpreter.Value
+		wantKind interpreter.ValueKind
 	}{
 		{
 			name:     "string value",
 			varName:  "str",
-			value:    "test",
-			wantType: "",
+			value:    interpreter.StringValue("test"),
+			wantKind: interpreter.STRING_VALUE,
 		},
 		{
-			name:     "integer value",
+			name:     "number value",
 			varName:  "num",
-			value:    42,
-			wantType: 0,
+			value:    interpreter.NumberValue(42),
+			wantKind: interpreter.NUMBER_VALUE,
 		},
 		{
 			name:     "nil value",
 			varName:  "empty",
-			value:    nil,
-			wantType: nil,
+			value:    interpreter.Nil,
+			wantKind: interpreter.NIL_VALUE,
 		},
 	}
 
@@ -74,7 +74,7 @@ func TestEnvironment_Define(t *testing.T) {
 
 			got, exists := env.Values[tt.varName]
 			assert.True(t, exists)
-			assert.IsType(t, tt.wantType, got)
+			assert.Equal(t, tt.wantKind, got.Kind())
 			assert.Equal(t, tt.value, got)
 		})
 	}
@@ -85,14 +85,14 @@ func TestEnvironment_Get(t *testing.T) {
 		name      string
 		setupEnv  func() *interpreter.Environment
 		token     token.Token
-		wantValue any
+		wantValue interpreter.Value
 		wantErr   bool
 	}{
 		{
 			name: "existing variable in current scope",
 			setupEnv: func() *interpreter.Environment {
 				env := interpreter.NewEnvironment(nil)
-				env.Define("x", 42)
+				env.Define("x", interpreter.NumberValue(42))
 				return env
 			},
 			token: token.Token{
@@ -100,14 +100,14 @@ func TestEnvironment_Get(t *testing.T) {
 				Line:   1,
 				Char:   1,
 			},
-			wantValue: 42,
+			wantValue: interpreter.NumberValue(42),
 			wantErr:   false,
 		},
 		{
 			name: "existing variable in outer scope",
 			setupEnv: func() *interpreter.Environment {
 				outer := interpreter.NewEnvironment(nil)
-				outer.Define("x", 42)
+				outer.Define("x", interpreter.NumberValue(42))
 				return interpreter.NewEnvironment(outer)
 			},
 			token: token.Token{
@@ -115,7 +115,7 @@ func TestEnvironment_Get(t *testing.T) {
 				Line:   1,
 				Char:   1,
 			},
-			wantValue: 42,
+			wantValue: interpreter.NumberValue(42),
 			wantErr:   false,
 		},
 		{
@@ -128,7 +128,7 @@ func TestEnvironment_Get(t *testing.T) {
 				Line:   1,
 				Char:   1,
 			},
-			wantValue: nil,
+			wantValue: interpreter.Nil,
 			wantErr:   true,
 		},
 	}
@@ -153,14 +153,14 @@ func TestEnvironment_Assign(t *testing.T) {
 		name     string
 		setupEnv func() *interpreter.Environment
 		token    token.Token
-		value    any
+		value    interpreter.Value
 		wantErr  bool
 	}{
 		{
 			name: "assign to existing variable",
 			setupEnv: func() *interpreter.Environment {
 				env := interpreter.NewEnvironment(nil)
-				env.Define("x", 42)
+				env.Define("x", interpreter.NumberValue(42))
 				return env
 			},
 			token: token.Token{
@@ -168,14 +168,14 @@ func TestEnvironment_Assign(t *testing.T) {
 				Line:   1,
 				Char:   1,
 			},
-			value:   100,
+			value:   interpreter.NumberValue(100),
 			wantErr: false,
 		},
 		{
 			name: "assign to variable in outer scope",
 			setupEnv: func() *interpreter.Environment {
 				outer := interpreter.NewEnvironment(nil)
-				outer.Define("x", 42)
+				outer.Define("x", interpreter.NumberValue(42))
 				return interpreter.NewEnvironment(outer)
 			},
 			token: token.Token{
@@ -183,7 +183,7 @@ func TestEnvironment_Assign(t *testing.T) {
 				Line:   1,
 				Char:   1,
 			},
-			value:   100,
+			value:   interpreter.NumberValue(100),
 			wantErr: false,
 		},
 		{
@@ -196,7 +196,7 @@ func TestEnvironment_Assign(t *testing.T) {
 				Line:   1,
 				Char:   1,
 			},
-			value:   100,
+			value:   interpreter.NumberValue(100),
 			wantErr: true,
 		},
 	}
@@ -0,0 +1,110 @@
+package interpreter
+
+import (
+	"testing"
+
+	"github.com/go-interpreter/internal/ast"
+	"github.com/go-interpreter/internal/interpreter"
+	"github.com/go-interpreter/internal/parser"
+	"github.com/go-interpreter/internal/resolver"
+	"github.com/go-interpreter/internal/scanner"
+	"github.com/stretchr/testify/assert"
+)
+
+// parseFunction parses and resolves a single function declaration,
+// recording its local bindings in the given interpreter.
+func parseFunction(t *testing.T, inter *interpreter.Interpreter, source string) ast.FunctionStmt {
+	t.Helper()
+	tokenScanner := scanner.NewTokenScanner(source)
+	tokens, scanErrors := tokenScanner.ScanTokens()
+	assert.Empty(t, scanErrors)
+	p := parser.NewParser(tokens)
+	stmts, parseErrors := p.Parse()
+	assert.Empty(t, parseErrors)
+	assert.Empty(t, resolver.NewResolver(inter).Resolve(stmts))
+	assert.Len(t, stmts, 1)
+	declaration, ok := stmts[0].(ast.FunctionStmt)
+	assert.True(t, ok)
+	return declaration
+}
+
+func TestFunction_Call(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    string
+		arguments []interpreter.Value
+		wantArity int
+		wantValue interpreter.Value
+	}{
+		{
+			name:      "returns its argument sum",
+			source:    "fun add(a, b) { return a + b; }",
+			arguments: []interpreter.Value{interpreter.NumberValue(1), interpreter.NumberValue(2)},
+			wantArity: 2,
+			wantValue: interpreter.NumberValue(3),
+		},
+		{
+			name:      "return unwinds nested loops and blocks",
+			source:    "fun find(n) { var i = 0; while (true) { if (i == n) { return i; } i++; } }",
+			arguments: []interpreter.Value{interpreter.NumberValue(4)},
+			wantArity: 1,
+			wantValue: interpreter.NumberValue(4),
+		},
+		{
+			name:      "falling off the end returns nil",
+			source:    "fun nothing() { var x = 1; }",
+			arguments: []interpreter.Value{},
+			wantArity: 0,
+			wantValue: interpreter.Nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inter := interpreter.NewInterpreter()
+			function := interpreter.NewFunction(parseFunction(t, &inter, tt.source), interpreter.NewEnvironment(nil), false)
+			assert.Equal(t, tt.wantArity, function.Arity())
+
+			got, err := function.Call(&inter, tt.arguments)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantValue, got)
+		})
+	}
+}
+
+func TestFunction_Closure(t *testing.T) {
+	source := "fun makeCounter() { var i = 0; fun count() { i++; return i; } return count; }"
+	inter := interpreter.NewInterpreter()
+	makeCounter := interpreter.NewFunction(parseFunction(t, &inter, source), interpreter.NewEnvironment(nil), false)
+
+	first, err := makeCounter.Call(&inter, []interpreter.Value{})
+	assert.NoError(t, err)
+	second, err := makeCounter.Call(&inter, []interpreter.Value{})
+	assert.NoError(t, err)
+
+	assert.True(t, first.IsCallable())
+	counter := first.AsCallable()
+	for _, want := range []float64{1, 2, 3} {
+		got, err := counter.Call(&inter, []interpreter.Value{})
+		assert.NoError(t, err)
+		assert.Equal(t, interpreter.NumberValue(want), got)
+	}
+
+	// Every call to the factory closes over a scope of its own
+	got, err := second.AsCallable().Call(&inter, []interpreter.Value{})
+	assert.NoError(t, err)
+	assert.Equal(t, interpreter.NumberValue(1), got)
+}
+
+func TestFunction_SameTokensInTwoSources(t *testing.T) {
+	// Both returns read 'a' from the same place, but from different depths
+	inter := interpreter.NewInterpreter()
+	flat := interpreter.NewFunction(parseFunction(t, &inter, "fun f(a) {  return a;  }"), interpreter.NewEnvironment(nil), false)
+	nested := interpreter.NewFunction(parseFunction(t, &inter, "fun f(a) {{ return a; }}"), interpreter.NewEnvironment(nil), false)
+
+	for _, function := range []*interpreter.Function{flat, nested} {
+		got, err := function.Call(&inter, []interpreter.Value{interpreter.NumberValue(1)})
+		assert.NoError(t, err)
+		assert.Equal(t, interpreter.NumberValue(1), got)
+	}
+}
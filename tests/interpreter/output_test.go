@@ -0,0 +1,95 @@
+package interpreter
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-interpreter/internal/analysis"
+	"github.com/go-interpreter/internal/ast"
+	"github.com/go-interpreter/internal/interpreter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpreter_SetOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{name: "print writes its value and nothing else", source: `print "a"; print 1;`, want: "a1"},
+		{name: "programs that print nothing write nothing", source: `var a = 1;`, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			inter := interpreter.NewInterpreter()
+			inter.SetOutput(&out)
+			assert.NoError(t, inter.Interpret(parse(t, &inter, tt.source)))
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+// failingWriter rejects everything written to it.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("connection closed")
+}
+
+func TestInterpreter_SetOutput_WriteErrors(t *testing.T) {
+	inter := interpreter.NewInterpreter()
+	inter.SetOutput(failingWriter{})
+	err := inter.Interpret(parse(t, &inter, "var a = 1;\nprint a;"))
+	assert.EqualError(t, err, "error: Runtime Error[E0310] [line 2, column 1]: connection closed")
+}
+
+func TestInterpreter_SetInput(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		source string
+		want   string
+	}{
+		{
+			name:   "readLine returns lines without their endings",
+			input:  "first\r\nsecond\nlast",
+			source: `var line = readLine(); while (line != nil) { print "[" + line + "]"; line = readLine(); }`,
+			want:   "[first][second][last]",
+		},
+		{name: "readLine returns nil at the end", input: "", source: `print readLine() == nil;`, want: "true"},
+		{name: "globals hide builtins", input: "a\n", source: `var readLine = "mine"; print readLine;`, want: "mine"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			inter := interpreter.NewInterpreter()
+			inter.SetOutput(&out)
+			inter.SetInput(strings.NewReader(tt.input))
+			assert.NoError(t, inter.Interpret(parse(t, &inter, tt.source)))
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+// failingReader fails every read.
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestInterpreter_SetInput_ReadErrors(t *testing.T) {
+	inter := interpreter.NewInterpreter()
+	inter.SetInput(failingReader{})
+	err := inter.Interpret(parse(t, &inter, "var a = 1;\nreadLine();"))
+	assert.EqualError(t, err, "error: Runtime Error[E0310] [line 2, column 10]: connection reset")
+}
+
+func parse(t *testing.T, inter *interpreter.Interpreter, source string) []ast.Stmt {
+	stmts, diagnostics := analysis.Analyse(analysis.Program{Source: source}, inter)
+	assert.Empty(t, diagnostics)
+	return stmts
+}
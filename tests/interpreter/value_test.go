@@ -0,0 +1,89 @@
+package interpreter
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-interpreter/internal/interpreter"
+	"github.com/go-interpreter/internal/parser"
+	"github.com/go-interpreter/internal/scanner"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValue_Truthy(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interpreter.Value
+		want  bool
+	}{
+		{name: "nil", value: interpreter.Nil, want: false},
+		{name: "false", value: interpreter.BoolValue(false), want: false},
+		{name: "true", value: interpreter.BoolValue(true), want: true},
+		{name: "zero", value: interpreter.NumberValue(0), want: false},
+		{name: "positive number", value: interpreter.NumberValue(0.5), want: true},
+		{name: "negative number", value: interpreter.NumberValue(-1), want: true},
+		{name: "NaN", value: interpreter.NumberValue(math.NaN()), want: true},
+		{name: "empty string", value: interpreter.StringValue(""), want: false},
+		{name: "string", value: interpreter.StringValue("a"), want: true},
+		{name: "class", value: interpreter.ClassValue(interpreter.NewClass("A", nil, nil)), want: true},
+		{name: "instance", value: interpreter.InstanceValue(interpreter.NewInstance(interpreter.NewClass("A", nil, nil))), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.value.Truthy())
+		})
+	}
+}
+
+func TestValue_Equal(t *testing.T) {
+	class := interpreter.NewClass("A", nil, nil)
+	instance := interpreter.NewInstance(class)
+	tests := []struct {
+		name  string
+		left  interpreter.Value
+		right interpreter.Value
+		want  bool
+	}{
+		{name: "nil and nil", left: interpreter.Nil, right: interpreter.Nil, want: true},
+		{name: "nil and false", left: interpreter.Nil, right: interpreter.BoolValue(false), want: false},
+		{name: "equal numbers", left: interpreter.NumberValue(1), right: interpreter.NumberValue(1), want: true},
+		{name: "number and string", left: interpreter.NumberValue(1), right: interpreter.StringValue("1"), want: false},
+		{name: "equal strings", left: interpreter.StringValue("a"), right: interpreter.StringValue("a"), want: true},
+		{name: "same instance", left: interpreter.InstanceValue(instance), right: interpreter.InstanceValue(instance), want: true},
+		{name: "different instances", left: interpreter.InstanceValue(instance), right: interpreter.InstanceValue(interpreter.NewInstance(class)), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.left.Equal(tt.right))
+		})
+	}
+}
+
+func TestInterpreter_TypeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		wantErr string
+	}{
+		{name: "subtracting a string", source: `var x = "a" - 1;`, wantErr: "'a' Operand must be a number"},
+		{name: "negating a string", source: `var x = -"a";`, wantErr: "'a' Operand must be a number"},
+		{name: "adding a boolean to a string", source: `var x = "a" + true;`, wantErr: "'true' Operand must be a string or a number"},
+		{name: "comparing nil", source: `var x = nil < 1;`, wantErr: "Operand must be a number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokenScanner := scanner.NewTokenScanner(tt.source)
+			tokens, scanErrors := tokenScanner.ScanTokens()
+			assert.Empty(t, scanErrors)
+			p := parser.NewParser(tokens)
+			inter := interpreter.NewInterpreter()
+			stmts, parseErrors := p.Parse()
+			assert.Empty(t, parseErrors)
+			err := inter.Interpret(stmts)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
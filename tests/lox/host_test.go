@@ -0,0 +1,182 @@
+package lox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-interpreter/pkg/lox"
+	"github.com/stretchr/testify/assert"
+)
+
+type server struct {
+	Host string
+	Port int
+}
+
+type config struct {
+	Name    string
+	Debug   bool
+	Server  server
+	Backup  *server
+	Tags    []string
+	HTTPURL string
+	secret  string
+}
+
+func (c *config) Address() string {
+	return fmt.Sprintf("%s:%d", c.Server.Host, c.Server.Port)
+}
+
+func (c *config) Rename(name string) error {
+	if name == "" {
+		return errors.New("the name cannot be empty")
+	}
+	c.Name = name
+	return nil
+}
+
+func (c *config) Primary() *server {
+	return &c.Server
+}
+
+type counter struct {
+	Count int
+}
+
+func (c *counter) String() string {
+	return fmt.Sprintf("counter at %d", c.Count)
+}
+
+func TestBind(t *testing.T) {
+	cfg := &config{Name: "api", Server: server{Host: "localhost", Port: 80}, Tags: []string{"a", "b"}, HTTPURL: "http://x"}
+	interpreter := lox.New(lox.Options{})
+	object, err := lox.Bind(cfg, lox.BindOptions{})
+	assert.NoError(t, err)
+	interpreter.Set("config", object)
+
+	tests := []struct {
+		name   string
+		source string
+		want   lox.Value
+	}{
+		{name: "fields are read", source: "config.name;", want: lox.String("api")},
+		{name: "names are mapped to lower camel case", source: "config.httpurl;", want: lox.String("http://x")},
+		{name: "nested structs are objects", source: "config.server.port;", want: lox.Number(80)},
+		{name: "slices are lists", source: "config.tags.get(1);", want: lox.String("b")},
+		{name: "nil pointers are nil", source: "config.backup;", want: lox.Nil},
+		{name: "methods are called", source: "config.address();", want: lox.String("localhost:80")},
+		{name: "methods can be passed around", source: "var address = config.address; address();", want: lox.String("localhost:80")},
+		{name: "objects print by type", source: "config.server;", want: lox.String("lox.server object")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := interpreter.Eval(context.Background(), tt.source)
+			assert.NoError(t, err)
+			if tt.want.IsString() && got.IsHost() {
+				got = lox.String(got.String())
+			}
+			assert.True(t, tt.want.Equal(got), "got %v", got)
+		})
+	}
+
+	_, err = interpreter.Eval(context.Background(),
+		"config.debug = true; config.server.port = 8079; config.server.port++; config.rename(\"web\"); config.primary().host = \"example.com\";")
+	assert.NoError(t, err)
+	assert.True(t, cfg.Debug)
+	assert.Equal(t, "web", cfg.Name)
+	assert.Equal(t, server{Host: "example.com", Port: 8080}, cfg.Server)
+
+	errorTests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "unknown properties",
+			source: "config.secret;",
+			want:   "<eval>:1:8: Runtime Error[E0302]: Undefined property 'secret'.",
+		},
+		{
+			name:   "objects do not grow properties",
+			source: "config.extra = 1;",
+			want:   "<eval>:1:8: Runtime Error[E0302]: Undefined property 'extra'.",
+		},
+		{
+			name:   "fields keep their type",
+			source: "config.server.port = \"80\";",
+			want:   "<eval>:1:15: Runtime Error[E0310]: cannot use \"80\" as int",
+		},
+		{
+			name:   "methods cannot be assigned to",
+			source: "config.address = nil;",
+			want:   "<eval>:1:8: Runtime Error[E0310]: cannot assign to address, which is a method",
+		},
+		{
+			name:   "method errors are runtime errors",
+			source: "config.rename(\"\");",
+			want:   "<eval>:1:17: Runtime Error[E0310]: the name cannot be empty",
+		},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := interpreter.Eval(context.Background(), tt.source)
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
+
+func TestBind_Options(t *testing.T) {
+	cfg := &config{Name: "api", Server: server{Port: 80}}
+	interpreter := lox.New(lox.Options{})
+	object, err := lox.Bind(cfg, lox.BindOptions{Name: strings.ToUpper, Allow: []string{"Name", "Server", "Port", "Address"}})
+	assert.NoError(t, err)
+	interpreter.Set("config", object)
+
+	value, err := interpreter.Eval(context.Background(), "config.NAME + \" \" + config.SERVER.PORT + \" \" + config.ADDRESS();")
+	assert.NoError(t, err)
+	assert.Equal(t, "api 80 :80", value.AsString())
+
+	_, err = interpreter.Eval(context.Background(), "config.DEBUG;")
+	assert.EqualError(t, err, "<eval>:1:8: Runtime Error[E0302]: Undefined property 'DEBUG'.")
+	_, err = interpreter.Eval(context.Background(), "config.SERVER.HOST;")
+	assert.EqualError(t, err, "<eval>:1:15: Runtime Error[E0302]: Undefined property 'HOST'.")
+
+	_, err = lox.Bind(cfg, lox.BindOptions{Name: func(string) string { return "same" }})
+	assert.EqualError(t, err, "cannot bind *lox.config: both Name and Debug are called \"same\"")
+	_, err = lox.Bind(*cfg, lox.BindOptions{})
+	assert.EqualError(t, err, "cannot bind lox.config, which is not a pointer to a struct")
+}
+
+func TestBind_Conversions(t *testing.T) {
+	interpreter := lox.New(lox.Options{})
+	c := &counter{}
+	assert.NoError(t, interpreter.Define("counter", c))
+	assert.NoError(t, interpreter.Define("increment", func(c *counter, by int) { c.Count += by }))
+	assert.NoError(t, interpreter.Define("make", func(count int) counter { return counter{Count: count} }))
+
+	value, err := interpreter.Eval(context.Background(), "increment(counter, 2); counter.count = counter.count + 1; counter;")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, c.Count)
+	assert.Equal(t, "counter at 3", value.String())
+
+	value, err = interpreter.Eval(context.Background(), "var other = make(5); increment(other, 1); other.count;")
+	assert.NoError(t, err)
+	assert.Equal(t, 6.0, value.AsNumber())
+
+	value, err = interpreter.Eval(context.Background(), "counter == counter;")
+	assert.NoError(t, err)
+	assert.True(t, value.AsBool())
+
+	var natural any
+	value, _ = interpreter.Get("counter")
+	assert.NoError(t, lox.Convert(value, &natural))
+	assert.Same(t, c, natural)
+
+	assert.Equal(t, "port", lox.LowerCamelCase("Port"))
+	assert.Equal(t, "url", lox.LowerCamelCase("URL"))
+	assert.Equal(t, "httpServer", lox.LowerCamelCase("HTTPServer"))
+	assert.Equal(t, "id", lox.LowerCamelCase("ID"))
+}
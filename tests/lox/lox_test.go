@@ -0,0 +1,188 @@
+package lox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-interpreter/pkg/lox"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpreter_Eval(t *testing.T) {
+	tests := []struct {
+		name    string
+		sources []string
+		want    lox.Value
+	}{
+		{
+			name:    "the value of a trailing expression is returned",
+			sources: []string{"1 + 2;"},
+			want:    lox.Number(3),
+		},
+		{
+			name:    "other statements return nil",
+			sources: []string{"var a = 1;"},
+			want:    lox.Nil,
+		},
+		{
+			name:    "only the last statement counts",
+			sources: []string{"\"a\" + 1; var b = 2;"},
+			want:    lox.Nil,
+		},
+		{
+			name:    "definitions persist across sources",
+			sources: []string{"fun double(n) { return n * 2; }", "double(21);"},
+			want:    lox.Number(42),
+		},
+		{
+			name: "closures keep their own locals across sources",
+			sources: []string{
+				"fun make() { var x = 5; fun get() { return x; } return get; }",
+				"var get = make(); var x = 9;",
+				"get() + x;",
+			},
+			want: lox.Number(14),
+		},
+		{
+			name:    "the same source can be evaluated twice",
+			sources: []string{"var n = 1; { var n = 2; }", "{ var n = 3; } n;"},
+			want:    lox.Number(1),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interpreter := lox.New(lox.Options{})
+			var got lox.Value
+			for _, source := range tt.sources {
+				var err error
+				got, err = interpreter.Eval(context.Background(), source)
+				assert.NoError(t, err)
+			}
+			assert.True(t, tt.want.Equal(got), "got %v", got)
+		})
+	}
+}
+
+func TestInterpreter_Eval_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		options lox.Options
+		source  string
+		want    string
+		runtime bool
+	}{
+		{
+			name:   "every syntax error is returned",
+			source: "var = 1;\nprint (2;",
+			want: "<eval>:1:5: Syntax Error[E0105]: Expect variable name.\n" +
+				"<eval>:2:9: Syntax Error[E0103]: Expect ')' after expression.",
+		},
+		{
+			name:   "resolution errors are returned",
+			source: "return 1;",
+			want:   "<eval>:1:1: Resolution Error[E0202]: Can't return from top-level code.",
+		},
+		{
+			name:    "runtime errors are returned",
+			options: lox.Options{File: "script.lox"},
+			source:  "var a = 1;\nprint missing;",
+			want:    "script.lox:2:7: Runtime Error[E0301]: Undefined variable missing.",
+			runtime: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := lox.New(tt.options).Eval(context.Background(), tt.source)
+			var loxErr *lox.Error
+			assert.True(t, errors.As(err, &loxErr))
+			assert.Equal(t, tt.want, err.Error())
+			assert.Equal(t, tt.runtime, loxErr.Runtime())
+		})
+	}
+}
+
+func TestInterpreter_Eval_RuntimeErrorKeepsDefinitions(t *testing.T) {
+	interpreter := lox.New(lox.Options{})
+	_, err := interpreter.Eval(context.Background(), "fun f() { return missing; }\nvar a = 1;\nf();\nvar b = 2;")
+	var loxErr *lox.Error
+	assert.True(t, errors.As(err, &loxErr))
+	assert.Equal(t, []lox.Frame{
+		{Function: "f", File: "<eval>", Line: 1, Column: 18},
+		{Function: "<script>", File: "<eval>", Line: 3, Column: 3},
+	}, loxErr.Diagnostics[0].Trace)
+	assert.Equal(t, []string{"a", "f"}, interpreter.Globals())
+}
+
+func TestInterpreter_Eval_Cancelled(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{name: "loops stop", source: "while (true) {}"},
+		{name: "recursion stops", source: "fun f(n) { if (n > 0) return f(n - 1); return f(100); }\nf(100);"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			_, err := lox.New(lox.Options{}).Eval(ctx, tt.source)
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+			var loxErr *lox.Error
+			assert.True(t, errors.As(err, &loxErr))
+			assert.Equal(t, "E0309", loxErr.Diagnostics[0].Code)
+		})
+	}
+}
+
+func TestInterpreter_Globals(t *testing.T) {
+	interpreter := lox.New(lox.Options{})
+	interpreter.Set("limit", lox.Number(3))
+	interpreter.Set("name", lox.String("lox"))
+	_, err := interpreter.Eval(context.Background(), "var greeting = \"hi \" + name; limit = limit + 1;")
+	assert.NoError(t, err)
+
+	greeting, ok := interpreter.Get("greeting")
+	assert.True(t, ok)
+	assert.Equal(t, "hi lox", greeting.AsString())
+	limit, _ := interpreter.Get("limit")
+	assert.Equal(t, 4.0, limit.AsNumber())
+	_, ok = interpreter.Get("missing")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"greeting", "limit", "name"}, interpreter.Globals())
+}
+
+func TestInterpreter_RunFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.lox")
+	assert.NoError(t, os.WriteFile(path, []byte("var port = 8000 + 80;\nfun url() { return \"host:\" + port; }\n"), 0o644))
+	interpreter := lox.New(lox.Options{})
+	assert.NoError(t, interpreter.RunFile(context.Background(), path))
+	url, err := interpreter.Eval(context.Background(), "url();")
+	assert.NoError(t, err)
+	assert.Equal(t, "host:8080", url.AsString())
+
+	assert.NoError(t, os.WriteFile(path, []byte("print nope;"), 0o644))
+	assert.EqualError(t, interpreter.RunFile(context.Background(), path),
+		path+":1:7: Runtime Error[E0301]: Undefined variable nope.")
+
+	err = interpreter.RunFile(context.Background(), filepath.Join(t.TempDir(), "missing.lox"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestInterpreter_Streams(t *testing.T) {
+	var out bytes.Buffer
+	interpreter := lox.New(lox.Options{Stdout: &out, Stdin: strings.NewReader("first\r\nsecond\nlast")})
+	_, err := interpreter.Eval(context.Background(),
+		"var line = readLine(); while (line != nil) { print \"[\" + line + \"]\"; line = readLine(); }")
+	assert.NoError(t, err)
+	assert.Equal(t, "[first][second][last]", out.String())
+
+	value, err := lox.New(lox.Options{Stdout: &out}).Eval(context.Background(), "readLine();")
+	assert.NoError(t, err)
+	assert.True(t, value.IsNil())
+}
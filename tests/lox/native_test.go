@@ -0,0 +1,164 @@
+package lox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-interpreter/pkg/lox"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpreter_DefineFunction(t *testing.T) {
+	interpreter := lox.New(lox.Options{})
+	interpreter.DefineFunction("sum", lox.VARIADIC, func(arguments []lox.Value) (lox.Value, error) {
+		total := 0.0
+		for _, argument := range arguments {
+			total += argument.AsNumber()
+		}
+		return lox.Number(total), nil
+	})
+	interpreter.DefineFunction("fail", 1, func(arguments []lox.Value) (lox.Value, error) {
+		return lox.Nil, fmt.Errorf("failed with %v", arguments[0])
+	})
+
+	value, err := interpreter.Eval(context.Background(), "sum() + sum(1) + sum(1, 2, 3);")
+	assert.NoError(t, err)
+	assert.Equal(t, 7.0, value.AsNumber())
+
+	value, err = interpreter.Eval(context.Background(), "sum;")
+	assert.NoError(t, err)
+	assert.Equal(t, "<native fn sum>", value.String())
+
+	_, err = interpreter.Eval(context.Background(), "fail();")
+	assert.EqualError(t, err, "<eval>:1:6: Runtime Error[E0306]: Expected 1 arguments but got 0.")
+
+	_, err = interpreter.Eval(context.Background(), "fun f() {\n  fail(\"x\");\n}\nf();")
+	assert.EqualError(t, err, "<eval>:2:11: Runtime Error[E0310]: failed with x")
+	var loxErr *lox.Error
+	assert.True(t, errors.As(err, &loxErr))
+	assert.Equal(t, []lox.Frame{
+		{Function: "f", File: "<eval>", Line: 2, Column: 11},
+		{Function: "<script>", File: "<eval>", Line: 4, Column: 3},
+	}, loxErr.Diagnostics[0].Trace)
+}
+
+func TestInterpreter_Define(t *testing.T) {
+	interpreter := lox.New(lox.Options{})
+	assert.NoError(t, interpreter.Define("repeat", func(s string, times int) (string, error) {
+		if times < 0 {
+			return "", errors.New("cannot repeat a negative number of times")
+		}
+		return strings.Repeat(s, times), nil
+	}))
+	assert.NoError(t, interpreter.Define("join", func(separator string, parts ...string) string {
+		return strings.Join(parts, separator)
+	}))
+	assert.NoError(t, interpreter.Define("range", func(n int) []int {
+		numbers := make([]int, n)
+		for index := range numbers {
+			numbers[index] = index * index
+		}
+		return numbers
+	}))
+	assert.NoError(t, interpreter.Define("total", func(numbers []float64) float64 {
+		total := 0.0
+		for _, number := range numbers {
+			total += number
+		}
+		return total
+	}))
+	assert.NoError(t, interpreter.Define("keys", func(fields map[string]any) int { return len(fields) }))
+	assert.NoError(t, interpreter.Define("config", map[string]any{"host": "localhost", "port": 8080, "debug": true}))
+	assert.NoError(t, interpreter.Define("log", func(any) {}))
+
+	tests := []struct {
+		name   string
+		source string
+		want   lox.Value
+	}{
+		{name: "arguments and results are converted", source: "repeat(\"ab\", 3);", want: lox.String("ababab")},
+		{name: "variadic functions take extra arguments", source: "join(\", \", \"a\", \"b\", \"c\");", want: lox.String("a, b, c")},
+		{name: "variadic functions take no extra arguments", source: "join(\", \");", want: lox.String("")},
+		{name: "slices become lists", source: "var squares = range(4); squares.length + squares.get(3);", want: lox.Number(13)},
+		{name: "lists become slices", source: "total(range(4));", want: lox.Number(14)},
+		{name: "maps become instances", source: "config.host + \":\" + config.port;", want: lox.String("localhost:8080")},
+		{name: "instances become maps", source: "class P {} var p = P(); p.x = 1; p.y = 2; keys(p);", want: lox.Number(2)},
+		{name: "functions without results return nil", source: "log(config);", want: lox.Nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := interpreter.Eval(context.Background(), tt.source)
+			assert.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "got %v", got)
+		})
+	}
+
+	errorTests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{name: "errors are runtime errors", source: "repeat(\"a\", -1);", want: "cannot repeat a negative number of times"},
+		{name: "arguments of the wrong kind", source: "repeat(1, 2);", want: "argument 1: cannot use number 1 as string"},
+		{name: "numbers that are not whole", source: "repeat(\"a\", 1.5);", want: "argument 2: cannot use number 1.5 as int"},
+		{name: "missing fixed arguments", source: "join();", want: "expected at least 1 arguments but got 0"},
+		{name: "lists are checked", source: "range(5).get(5);", want: "index 5 is out of range for a list of length 5"},
+		{name: "the get of a list cannot be removed", source: "var l = range(2); l.get = nil;", want: "cannot assign to get, lists cannot be changed"},
+		{name: "the get of a list cannot be replaced", source: "var l = range(2); l.get = total;", want: "cannot assign to get, lists cannot be changed"},
+		{name: "the length of a list cannot be changed", source: "var l = range(2); l.length = -1;", want: "cannot assign to length, lists cannot be changed"},
+		{name: "the length of a list cannot be replaced", source: "var l = range(2); l.length = \"x\";", want: "cannot assign to length, lists cannot be changed"},
+		{name: "the length of a list cannot be incremented", source: "var l = range(2); l.length++;", want: "cannot assign to length, lists cannot be changed"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := interpreter.Eval(context.Background(), tt.source)
+			var loxErr *lox.Error
+			assert.True(t, errors.As(err, &loxErr))
+			assert.Equal(t, "E0310", loxErr.Diagnostics[0].Code)
+			assert.Equal(t, tt.want, loxErr.Diagnostics[0].Message)
+		})
+	}
+	value, err := interpreter.Eval(context.Background(), "total(l) + l.length;")
+	assert.NoError(t, err)
+	assert.Equal(t, 3.0, value.AsNumber())
+	value, err = interpreter.Eval(context.Background(), "l;")
+	assert.NoError(t, err)
+	assert.Equal(t, "<list of 2>", value.String())
+
+	assert.EqualError(t, interpreter.Define("pair", func() (int, int) { return 1, 2 }),
+		"cannot define func() (int, int): a native function returns a value, an error, or both")
+	assert.EqualError(t, interpreter.Define("channel", make(chan int)), "cannot convert chan int to a value")
+}
+
+func TestConvert(t *testing.T) {
+	var number int
+	assert.NoError(t, lox.Convert(lox.Number(42), &number))
+	assert.Equal(t, 42, number)
+
+	var small int8
+	assert.EqualError(t, lox.Convert(lox.Number(300), &small), "cannot use number 300 as int8")
+	var unsigned uint
+	assert.EqualError(t, lox.Convert(lox.Number(-1), &unsigned), "cannot use number -1 as uint")
+	var text string
+	assert.EqualError(t, lox.Convert(lox.Nil, &text), "cannot use nil as string")
+	assert.EqualError(t, lox.Convert(lox.Nil, text), "cannot convert to string, which is not a pointer")
+
+	list, err := lox.ValueOf([]any{1, "two", []bool{true}, nil})
+	assert.NoError(t, err)
+	var natural any
+	assert.NoError(t, lox.Convert(list, &natural))
+	assert.Equal(t, []any{1.0, "two", []any{true}, nil}, natural)
+
+	fields, err := lox.ValueOf(map[string]int{"a": 1})
+	assert.NoError(t, err)
+	assert.NoError(t, lox.Convert(fields, &natural))
+	assert.Equal(t, map[string]any{"a": 1.0}, natural)
+
+	value := lox.String("kept")
+	var same lox.Value
+	assert.NoError(t, lox.Convert(value, &same))
+	assert.True(t, value.Equal(same))
+}
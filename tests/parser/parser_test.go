@@ -0,0 +1,198 @@
+package parser
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-interpreter/internal/ast"
+	"github.com/go-interpreter/internal/parser"
+	"github.com/go-interpreter/internal/scanner"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParser_Diagnostics(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    string
+		wantStmts int
+		want      []string
+	}{
+		{
+			name:      "valid program",
+			source:    `var a = 1; print a and 2;`,
+			wantStmts: 2,
+		},
+		{
+			name:      "every error is collected",
+			source:    "var = 1;\nprint (1;\nprint 2;\nvar b = ;",
+			wantStmts: 1,
+			want: []string{
+				"Expect variable name.",
+				"Expect ')' after expression.",
+				"Unexpected token ';'",
+			},
+		},
+		{
+			name:      "error in the right operand of 'and'",
+			source:    `print true and ;`,
+			wantStmts: 0,
+			want:      []string{"Unexpected token ';'"},
+		},
+		{
+			name:      "unexpected first token",
+			source:    `) print 1;`,
+			wantStmts: 1,
+			want:      []string{"Unexpected token ')'"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokenScanner := scanner.NewTokenScanner(tt.source)
+			tokens, scanErrors := tokenScanner.ScanTokens()
+			assert.Empty(t, scanErrors)
+			p := parser.NewParser(tokens)
+			stmts, parseErrors := p.Parse()
+
+			assert.Len(t, stmts, tt.wantStmts)
+			for _, stmt := range stmts {
+				assert.NotNil(t, stmt)
+			}
+			messages := make([]string, 0, len(parseErrors))
+			for _, err := range parseErrors {
+				messages = append(messages, err.Message)
+			}
+			if len(tt.want) == 0 {
+				assert.Empty(t, messages)
+			} else {
+				assert.Equal(t, tt.want, messages)
+			}
+		})
+	}
+}
+
+func TestParser_Logical(t *testing.T) {
+	tokenScanner := scanner.NewTokenScanner(`a and b or c;`)
+	tokens, _ := tokenScanner.ScanTokens()
+	p := parser.NewParser(tokens)
+	stmts, parseErrors := p.Parse()
+	assert.Empty(t, parseErrors)
+	assert.Len(t, stmts, 1)
+
+	or, ok := stmts[0].(ast.ExpressionStmt).Expression.(ast.Logical)
+	assert.True(t, ok)
+	assert.Equal(t, "or", or.Operator.Lexeme)
+	and, ok := or.Left.(ast.Logical)
+	assert.True(t, ok)
+	assert.Equal(t, "and", and.Operator.Lexeme)
+}
+
+func TestParser_MissingTokenPosition(t *testing.T) {
+	tokenScanner := scanner.NewTokenScanner("print 1\nprint 2;")
+	tokens, _ := tokenScanner.ScanTokens()
+	p := parser.NewParser(tokens)
+	_, parseErrors := p.Parse()
+
+	// Reported right after the '1' rather than at the start of the next line
+	assert.Len(t, parseErrors, 1)
+	assert.Equal(t, 1, parseErrors[0].Line)
+	assert.Equal(t, 8, parseErrors[0].Column)
+}
+
+func TestParser_Spans(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		node   func(stmts []ast.Stmt) ast.Node
+		want   string
+	}{
+		{
+			name:   "statements include their semicolon",
+			source: "  print 1 + 2;",
+			node:   func(stmts []ast.Stmt) ast.Node { return stmts[0] },
+			want:   "1:3-1:15 [2,14)",
+		},
+		{
+			name:   "binary expressions run from their left to their right operand",
+			source: "print a * (b - c);",
+			node:   func(stmts []ast.Stmt) ast.Node { return stmts[0].(ast.PrintStmt).Expression },
+			want:   "1:7-1:18 [6,17)",
+		},
+		{
+			name:   "groupings include their parentheses",
+			source: "print a * (b - c);",
+			node: func(stmts []ast.Stmt) ast.Node {
+				return stmts[0].(ast.PrintStmt).Expression.(ast.Binary).Right
+			},
+			want: "1:11-1:18 [10,17)",
+		},
+		{
+			name:   "literals over several lines",
+			source: "print \"a\nbc\";",
+			node:   func(stmts []ast.Stmt) ast.Node { return stmts[0].(ast.PrintStmt).Expression },
+			want:   "1:7-2:4 [6,12)",
+		},
+		{
+			name:   "calls end at their closing parenthesis",
+			source: "a.b(1, 2).c;",
+			node: func(stmts []ast.Stmt) ast.Node {
+				return stmts[0].(ast.ExpressionStmt).Expression.(ast.Get).Object
+			},
+			want: "1:1-1:10 [0,9)",
+		},
+		{
+			name:   "blocks include their braces",
+			source: "if (a) {\n  b;\n} else c;",
+			node:   func(stmts []ast.Stmt) ast.Node { return stmts[0].(ast.IfStmt).ThenBranch },
+			want:   "1:8-3:2 [7,15)",
+		},
+		{
+			name:   "methods start at their name",
+			source: "class A { m() {} }",
+			node:   func(stmts []ast.Stmt) ast.Node { return stmts[0].(ast.ClassStmt).Methods[0] },
+			want:   "1:11-1:17 [10,16)",
+		},
+		{
+			name:   "desugared for loops span the whole loop",
+			source: "for (;;) {}",
+			node: func(stmts []ast.Stmt) ast.Node {
+				return stmts[0].(ast.WhileStmt).Condition
+			},
+			want: "1:1-1:12 [0,11)",
+		},
+		{
+			name:   "the statement running the increment spans the increment",
+			source: "for (;; i++) {}",
+			node: func(stmts []ast.Stmt) ast.Node {
+				return stmts[0].(ast.WhileStmt).Body.(ast.Block).Statements[1]
+			},
+			want: "1:9-1:12 [8,11)",
+		},
+		{
+			name:   "property increments span the object and the operator",
+			source: "a.b.c++;",
+			node:   func(stmts []ast.Stmt) ast.Node { return stmts[0].(ast.ExpressionStmt).Expression },
+			want:   "1:1-1:8 [0,7)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokenScanner := scanner.NewTokenScanner(tt.source)
+			tokens, _ := tokenScanner.ScanTokens()
+			p := parser.NewParser(tokens)
+			p.File = "main.lox"
+			stmts, parseErrors := p.Parse()
+			assert.Empty(t, parseErrors)
+
+			var span ast.Span
+			switch node := tt.node(stmts).(type) {
+			case ast.Stmt:
+				span = node.Location()
+			case ast.Expr:
+				span = node.Location()
+			}
+			assert.Equal(t, "main.lox", span.File)
+			assert.Equal(t, tt.want, fmt.Sprintf("%d:%d-%d:%d [%d,%d)",
+				span.Start.Line, span.Start.Column, span.End.Line, span.End.Column, span.Start.Offset, span.End.Offset))
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package printer
+
+import (
+	"testing"
+
+	"github.com/go-interpreter/internal/ast"
+	"github.com/go-interpreter/internal/printer"
+	"github.com/go-interpreter/internal/testutil"
+	"github.com/go-interpreter/internal/token"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrint(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		tree   string
+		sexpr  string
+	}{
+		{
+			name:   "operators",
+			source: "print -(1 + 2.5) * 3 or !nil;",
+			tree: "Print\n  Logical or\n    Binary *\n      Unary -\n        Grouping\n          Binary +\n" +
+				"            Literal 1\n            Literal 2.5\n      Literal 3\n    Unary !\n      Literal nil\n",
+			sexpr: "(print (or (* (- (group (+ 1 2.5))) 3) (! nil)))\n",
+		},
+		{
+			name:   "variables",
+			source: "var a; var b = \"hi\"; a = b;",
+			tree:   "Var a\nVar b\n  Literal \"hi\"\nExpression\n  Assign a\n    Variable b\n",
+			sexpr:  "(var a)\n(var b \"hi\")\n(; (= a b))\n",
+		},
+		{
+			name:   "control flow",
+			source: "if (a) { print 1; } else print 2; while (true) { break; continue; }",
+			tree: "If\n  condition:\n    Variable a\n  then:\n    Block\n      Print\n        Literal 1\n" +
+				"  else:\n    Print\n      Literal 2\n" +
+				"While\n  condition:\n    Literal true\n  body:\n    Block\n      Break\n      Continue\n",
+			sexpr: "(if a (block (print 1)) (print 2))\n(while true (block (break) (continue)))\n",
+		},
+		{
+			name:   "for loops are printed desugared",
+			source: "for (var i = 0; i < 2; i++) print i;",
+			tree: "Block\n  Var i\n    Literal 0\n  While\n    condition:\n      Binary <\n        Variable i\n        Literal 2\n" +
+				"    body:\n      Block\n        Print\n          Variable i\n        Expression\n" +
+				"          Assign i\n            Binary ++\n              Variable i\n              Literal 1\n",
+			sexpr: "(block (var i 0) (while (< i 2) (block (print i) (; (= i (++ i 1))))))\n",
+		},
+		{
+			name:   "functions and calls",
+			source: "fun add(a, b) { return a + b; } fun nothing() { return; } add(1, nothing());",
+			tree: "Function add(a, b)\n  Return\n    Binary +\n      Variable a\n      Variable b\n" +
+				"Function nothing()\n  Return\n" +
+				"Expression\n  Call\n    callee:\n      Variable add\n    arguments:\n      Literal 1\n" +
+				"      Call\n        callee:\n          Variable nothing\n",
+			sexpr: "(fun add (a b) (return (+ a b)))\n(fun nothing () (return))\n(; (call add 1 (call nothing)))\n",
+		},
+		{
+			name:   "classes",
+			source: "class A {} class B < A { init() { this.x = super.get; } }",
+			tree: "Class A\nClass B < A\n  Function init()\n    Expression\n      Set x\n        object:\n          This\n" +
+				"        value:\n          Super get\n",
+			sexpr: "(class A)\n(class B (< A) (fun init () (; (= (. this x) (super get)))))\n",
+		},
+		{
+			name:   "properties",
+			source: "print a.b.c;",
+			tree:   "Print\n  Get c\n    Get b\n      Variable a\n",
+			sexpr:  "(print (. (. a b) c))\n",
+		},
+		{
+			name:   "incremented properties",
+			source: "a.b.c++;",
+			tree:   "Expression\n  Increment c\n    Get b\n      Variable a\n",
+			sexpr:  "(; (++ (. (. a b) c)))\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmts := testutil.Parse(t, tt.source)
+			assert.Equal(t, tt.tree, printer.Print(printer.TREE_FORMAT, stmts))
+			assert.Equal(t, tt.sexpr, printer.Print(printer.SEXPR_FORMAT, stmts))
+		})
+	}
+}
+
+func TestPrint_Expression(t *testing.T) {
+	expression := ast.Binary{
+		Left:     ast.Literal{Value: 1.0},
+		Operator: token.Token{Type: token.PLUS, Lexeme: "+"},
+		Right:    ast.Variable{Name: token.Token{Type: token.IDENTIFIER, Lexeme: "a"}},
+	}
+	assert.Equal(t, "Binary +\n  Literal 1\n  Variable a\n", (&printer.PrintAST{}).Print(expression))
+	assert.Equal(t, "(+ 1 a)", (&printer.PrintSExpr{}).Print(expression))
+}
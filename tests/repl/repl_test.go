@@ -0,0 +1,219 @@
+package repl
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-interpreter/internal/repl"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepl_Start(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "expression values are echoed",
+			input: "1 + 2;\n\"a\" + 1;\nnil;\n",
+			want:  "3\n\"a1\"\nnil\n",
+		},
+		{
+			name:  "the semicolon of a lone expression is optional",
+			input: "2 * 21\n",
+			want:  "42\n",
+		},
+		{
+			name:  "definitions persist across inputs",
+			input: "var a = 1;\nfun add(n) { return a + n; }\nadd(2);\n",
+			want:  "3\n",
+		},
+		{
+			name:  "print writes to the output of the session",
+			input: "print \"a\"; print \"b\";\nprint 1;\n",
+			want:  "ab\n1\n",
+		},
+		{
+			name:  "printed lines are ended before an echo",
+			input: "print \"a\"; 1;\n",
+			want:  "a\n1\n",
+		},
+		{
+			name:  "printed lines that are already ended are kept",
+			input: "print \"a\\n\";\n",
+			want:  "a\n",
+		},
+		{
+			name:  "printed lines are ended before an error",
+			input: "print \"a\"; missing;\n",
+			want: "a\n" +
+				"Runtime Error[E0301]: Undefined variable missing.\n" +
+				" --> <repl>:1:12\n" +
+				"  |\n" +
+				"1 | print \"a\"; missing;\n" +
+				"  |            ^^^^^^^\n" +
+				"  = hint: declare it with 'var missing' before it is used\n",
+		},
+		{
+			name:  "readLine reads the next line of the session",
+			input: "var name = readLine();\nAda\nname;\n",
+			want:  "\"Ada\"\n",
+		},
+		{
+			name:  "unbalanced input continues on the next line",
+			input: "fun twice(n) {\n  return n * 2;\n}\ntwice(\n  4\n);\n",
+			want:  "8\n",
+		},
+		{
+			name:  "closures still see their own locals after later inputs",
+			input: "fun make() { var x = 5; fun get() { return x; } return get; }\nvar get = make();\nvar x = 9;\nget();\nx;\n",
+			want:  "5\n9\n",
+		},
+		{
+			name:  "errors do not end the session",
+			input: "missing;\n7;\n",
+			want: "Runtime Error[E0301]: Undefined variable missing.\n" +
+				" --> <repl>:1:1\n" +
+				"  |\n" +
+				"1 | missing;\n" +
+				"  | ^^^^^^^\n" +
+				"  = hint: declare it with 'var missing' before it is used\n" +
+				"7\n",
+		},
+		{
+			name:  "errors in functions from earlier inputs quote those inputs",
+			input: "var a = 1;\nfun f() {\n  return missing;\n}\nf();\n",
+			want: "Runtime Error[E0301]: Undefined variable missing.\n" +
+				" --> <repl>:3:10\n" +
+				"  |\n" +
+				"3 |   return missing;\n" +
+				"  |          ^^^^^^^\n" +
+				"  = hint: declare it with 'var missing' before it is used\n" +
+				"stack trace:\n" +
+				"  at f (<repl>:3:10)\n" +
+				"  at <script> (<repl>:5:3)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := repl.NewRepl().Start(strings.NewReader(tt.input), &out)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+func TestRepl_Commands(t *testing.T) {
+	dir := t.TempDir()
+	session := filepath.Join(dir, "session.lox")
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "env lists the globals in order",
+			input: "var b = \"two\";\nvar a = 1;\n:env\n",
+			want:  "a = 1\nb = \"two\"\n",
+		},
+		{
+			name:  "tokens shows the scanner output",
+			input: ":tokens print 1;\n",
+			want:  "1:1\tPRINT         print\n1:7\tNUMBER        1 (1)\n1:8\tSEMICOLON     ;\n1:9\tEOF           \n",
+		},
+		{
+			name:  "reset forgets definitions",
+			input: "var a = 1;\n:reset\n:env\n",
+			want:  "",
+		},
+		{
+			name:  "readLine still reads the session after a reset",
+			input: ":reset\nreadLine();\nAda\n",
+			want:  "\"Ada\"\n",
+		},
+		{
+			name:  "save then load restores the session",
+			input: "var a = 20;\nmissing;\na = a + 1;\n:save " + session + "\n:reset\n:load " + session + "\na;\n",
+			want: "Runtime Error[E0301]: Undefined variable missing.\n" +
+				" --> <repl>:2:1\n" +
+				"  |\n" +
+				"2 | missing;\n" +
+				"  | ^^^^^^^\n" +
+				"  = hint: declare it with 'var missing' before it is used\n" +
+				"21\nsaved 2 inputs to " + session + "\n21\n21\n",
+		},
+		{
+			name:  "unknown commands are reported",
+			input: ":nope\n:quit\n1;\n",
+			want:  "error: unknown command ':nope' (try :help)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := repl.NewRepl().Start(strings.NewReader(tt.input), &out)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+func TestRepl_Run_Input(t *testing.T) {
+	for _, backend := range []repl.Backend{repl.TREE_WALKER, repl.BYTECODE_VM} {
+		t.Run(string(backend), func(t *testing.T) {
+			var out, errs bytes.Buffer
+			r := repl.NewRepl()
+			r.Backend = backend
+			r.Output = &out
+			r.Errors = &errs
+			r.Input = strings.NewReader("Ada\n")
+			r.Run("main.lox", `print "hello " + readLine(); print readLine();`)
+			assert.Empty(t, errs.String())
+			assert.Equal(t, "hello Ada", out.String())
+		})
+	}
+}
+
+func TestRepl_LoadProgram(t *testing.T) {
+	var locals strings.Builder
+	for n := 0; n < 300; n++ {
+		fmt.Fprintf(&locals, "var v%d = %d;\n", n, n)
+	}
+	tests := []struct {
+		name                string
+		backend             repl.Backend
+		source              string
+		wantErr             string
+		wantHadError        bool
+		wantHadRuntimeError bool
+	}{
+		{name: "a syntax error", backend: repl.TREE_WALKER, source: "var = 1;", wantErr: "has errors", wantHadError: true},
+		{name: "a runtime error", backend: repl.TREE_WALKER, source: "missing;", wantErr: "failed", wantHadRuntimeError: true},
+		{name: "a compile error", backend: repl.BYTECODE_VM, source: "{\n" + locals.String() + "}\n", wantErr: "has errors", wantHadError: true},
+		{name: "a runtime error in the vm", backend: repl.BYTECODE_VM, source: "print -\"a\";", wantErr: "failed", wantHadRuntimeError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "program.lox")
+			assert.NoError(t, os.WriteFile(path, []byte(tt.source), 0o644))
+			r := repl.NewRepl()
+			r.Backend = tt.backend
+			r.Errors = &bytes.Buffer{}
+			err := r.LoadProgram(path)
+			assert.ErrorContains(t, err, path+" "+tt.wantErr)
+			assert.Equal(t, tt.wantHadError, r.HadError)
+			assert.Equal(t, tt.wantHadRuntimeError, r.HadRuntimeError)
+		})
+	}
+	t.Run("missing files are errors", func(t *testing.T) {
+		r := repl.NewRepl()
+		err := r.LoadProgram(filepath.Join(t.TempDir(), "missing.lox"))
+		assert.ErrorContains(t, err, "cannot read the program")
+	})
+}
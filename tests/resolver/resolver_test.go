@@ -0,0 +1,81 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/go-interpreter/internal/interpreter"
+	"github.com/go-interpreter/internal/parser"
+	"github.com/go-interpreter/internal/resolver"
+	"github.com/go-interpreter/internal/scanner"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolver_Resolve(t *testing.T) {
+	tests := []struct {
+		name       string
+		source     string
+		wantErrors []string
+	}{
+		{
+			name:   "locals and globals resolve cleanly",
+			source: "var a = 1; { var b = a; fun f(c) { return b + c; } }",
+		},
+		{
+			name:   "globals may be redeclared",
+			source: "var a = 1; var a = 2;",
+		},
+		{
+			name:       "read in own initializer",
+			source:     "{ var a = 1; { var a = a; } }",
+			wantErrors: []string{"Can't read local variable in its own initializer."},
+		},
+		{
+			name:       "duplicate declaration in one scope",
+			source:     "fun f(a) { var a = 1; var b; var b; }",
+			wantErrors: []string{"Already a variable with this name in this scope.", "Already a variable with this name in this scope."},
+		},
+		{
+			name:       "top level return",
+			source:     "return 1;",
+			wantErrors: []string{"Can't return from top-level code."},
+		},
+		{
+			name:       "value returned from initializer",
+			source:     "class A { init() { return 1; } }",
+			wantErrors: []string{"Can't return a value from an initializer."},
+		},
+		{
+			name:       "this outside of a class",
+			source:     "fun f() { return this; }",
+			wantErrors: []string{"Can't use 'this' outside of a class."},
+		},
+		{
+			name:       "super without a superclass",
+			source:     "class A { f() { return super.f(); } }",
+			wantErrors: []string{"Can't use 'super' in a class with no superclass."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokenScanner := scanner.NewTokenScanner(tt.source)
+			tokens, scanErrors := tokenScanner.ScanTokens()
+			assert.Empty(t, scanErrors)
+			p := parser.NewParser(tokens)
+			inter := interpreter.NewInterpreter()
+			stmts, parseErrors := p.Parse()
+			assert.Empty(t, parseErrors)
+			got := resolver.NewResolver(&inter).Resolve(stmts)
+
+			messages := make([]string, 0, len(got))
+			for _, err := range got {
+				messages = append(messages, err.Message)
+			}
+			if len(tt.wantErrors) == 0 {
+				assert.Empty(t, messages)
+			} else {
+				assert.Equal(t, tt.wantErrors, messages)
+			}
+		})
+	}
+}
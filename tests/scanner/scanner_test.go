@@ -0,0 +1,72 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/go-interpreter/internal/errors"
+	"github.com/go-interpreter/internal/scanner"
+	"github.com/go-interpreter/internal/token"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenScanner_ScanTokens(t *testing.T) {
+	tokenScanner := scanner.NewTokenScanner(`var a = "x" + 1.5;`)
+	tokens, scanErrors := tokenScanner.ScanTokens()
+	assert.Empty(t, scanErrors)
+
+	types := make([]token.TokenType, 0, len(tokens))
+	for _, tok := range tokens {
+		types = append(types, tok.Type)
+	}
+	assert.Equal(t, []token.TokenType{
+		token.VAR, token.IDENTIFIER, token.EQUAL, token.STRING, token.PLUS, token.NUMBER, token.SEMICOLON, token.EOF,
+	}, types)
+	assert.Equal(t, "x", tokens[3].Literal)
+	assert.Equal(t, 1.5, tokens[5].Literal)
+}
+
+func TestTokenScanner_Diagnostics(t *testing.T) {
+	tokenScanner := scanner.NewTokenScanner("var a = @;\nvar b = #;\nprint \"open")
+	tokens, scanErrors := tokenScanner.ScanTokens()
+
+	assert.Equal(t, []errors.ExecutionError{
+		{Type: errors.SCANNER_ERROR, Code: errors.UNEXPECTED_CHARACTER, Line: 1, Column: 9, Length: 1, Where: 8, Message: "Unexpected character '@'."},
+		{Type: errors.SCANNER_ERROR, Code: errors.UNEXPECTED_CHARACTER, Line: 2, Column: 9, Length: 1, Where: 19, Message: "Unexpected character '#'."},
+		{Type: errors.SCANNER_ERROR, Code: errors.UNTERMINATED_STRING, Line: 3, Column: 7, Length: 1, Where: 28, Message: "Unterminated string.",
+			Hint: "add a closing '\"' to end the string"},
+	}, scanErrors)
+	// Scanning carries on past errors
+	assert.Equal(t, token.EOF, tokens[len(tokens)-1].Type)
+	assert.Equal(t, token.PRINT, tokens[len(tokens)-2].Type)
+}
+
+func TestTokenScanner_Positions(t *testing.T) {
+	tokenScanner := scanner.NewTokenScanner("var s = \"a\nb\";\n\tprint \"é\" + s;")
+	tokens, scanErrors := tokenScanner.ScanTokens()
+	assert.Empty(t, scanErrors)
+
+	// A string spanning lines is reported where it starts
+	assert.Equal(t, token.STRING, tokens[3].Type)
+	assert.Equal(t, 1, tokens[3].Line)
+	assert.Equal(t, 9, tokens[3].Column)
+	// Columns count characters, not bytes
+	plus := tokens[7]
+	assert.Equal(t, token.PLUS, plus.Type)
+	assert.Equal(t, 3, plus.Line)
+	assert.Equal(t, 12, plus.Column)
+}
+
+func TestTokenScanner_Comments(t *testing.T) {
+	tokenScanner := scanner.NewTokenScanner("// first\nvar a = 1; // second\r\nprint a / 2;")
+	tokens, scanErrors := tokenScanner.ScanTokens()
+	assert.Empty(t, scanErrors)
+
+	// Comments are trivia: the parser never sees them
+	for _, tok := range tokens {
+		assert.NotEqual(t, token.COMMENT, tok.Type)
+	}
+	assert.Equal(t, []token.Token{
+		{Type: token.COMMENT, Lexeme: "// first", Line: 1, Column: 1, Char: 0},
+		{Type: token.COMMENT, Lexeme: "// second", Line: 2, Column: 12, Char: 20},
+	}, tokenScanner.Comments)
+}
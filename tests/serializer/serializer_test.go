@@ -0,0 +1,121 @@
+package serializer
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/go-interpreter/internal/ast"
+	"github.com/go-interpreter/internal/serializer"
+	"github.com/go-interpreter/internal/testutil"
+	"github.com/go-interpreter/internal/token"
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with the current output")
+
+func TestMarshal(t *testing.T) {
+	data, err := serializer.Marshal(testutil.Parse(t, "var a = \"hi\";"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[{
+		"kind": "VarStmt",
+		"span": {"start": {"line": 1, "column": 1, "offset": 0}, "end": {"line": 1, "column": 14, "offset": 13}},
+		"name": {"type": "IDENTIFIER", "lexeme": "a", "line": 1, "column": 5, "offset": 4},
+		"initializer": {
+			"kind": "Literal",
+			"span": {"start": {"line": 1, "column": 9, "offset": 8}, "end": {"line": 1, "column": 13, "offset": 12}},
+			"value": "hi"
+		}
+	}]`, string(data))
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{name: "expressions", source: "print -(1 + 2.5) * 3 or !nil and a == \"s\";"},
+		{name: "variables", source: "var a; var b = true; a = b;"},
+		{name: "control flow", source: "if (a) { print 1; } else print 2; while (true) { break; continue; }"},
+		{name: "for loops", source: "for (var i = 0; i < 2; i++) print i;"},
+		{name: "functions", source: "fun add(a, b) { return a + b; } fun nothing() { return; } add(1, nothing());"},
+		{name: "classes", source: "class A {} class B < A { init() { this.x = super.get; this.x.y = nil; this.n++; } }"},
+		{name: "empty program", source: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmts := testutil.Parse(t, tt.source)
+			data, err := serializer.Marshal(stmts)
+			assert.NoError(t, err)
+			decoded, err := serializer.Unmarshal(data)
+			assert.NoError(t, err)
+			if len(stmts) == 0 {
+				assert.Empty(t, decoded)
+				return
+			}
+			assert.Equal(t, stmts, decoded)
+		})
+	}
+}
+
+// TestGolden snapshots the parser output for the examples. Run the tests
+// with -update after changing the parser on purpose.
+func TestGolden(t *testing.T) {
+	source, err := os.ReadFile("../../examples/program.txt")
+	assert.NoError(t, err)
+	data, err := serializer.MarshalIndent(testutil.Parse(t, string(source)))
+	assert.NoError(t, err)
+	data = append(data, '\n')
+	if *update {
+		assert.NoError(t, os.WriteFile("testdata/program.json", data, 0o644))
+	}
+	golden, err := os.ReadFile("testdata/program.json")
+	assert.NoError(t, err)
+	assert.Equal(t, string(golden), string(data))
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "not json", data: "[", wantErr: "unexpected end of JSON input"},
+		{name: "not an array", data: `{"kind": "BreakStmt"}`, wantErr: "cannot unmarshal object"},
+		{name: "no kind", data: `[{}]`, wantErr: "node without a kind: {}"},
+		{name: "unknown kind", data: `[{"kind": "GotoStmt"}]`, wantErr: `unknown statement kind "GotoStmt"`},
+		{
+			name:    "statement as an expression",
+			data:    `[{"kind": "PrintStmt", "expression": {"kind": "BreakStmt"}}]`,
+			wantErr: `unknown expression kind "BreakStmt"`,
+		},
+		{name: "missing expression", data: `[{"kind": "PrintStmt"}]`, wantErr: "missing expression"},
+		{
+			name:    "unknown token type",
+			data:    `[{"kind": "VarStmt", "name": {"type": "NAME", "lexeme": "a"}}]`,
+			wantErr: `unknown token type "NAME"`,
+		},
+		{
+			name:    "superclass that is not a variable",
+			data:    `[{"kind": "ClassStmt", "name": {"type": "IDENTIFIER", "lexeme": "A"}, "superclass": {"kind": "This"}}]`,
+			wantErr: "the superclass of A is not a variable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmts, err := serializer.Unmarshal([]byte(tt.data))
+			assert.Nil(t, stmts)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestUnmarshal_WithoutPositions(t *testing.T) {
+	stmts, err := serializer.Unmarshal([]byte(`[{"kind": "PrintStmt", "expression": {
+		"kind": "Variable", "name": {"type": "IDENTIFIER", "lexeme": "a"}}}]`))
+	assert.NoError(t, err)
+	assert.Equal(t, []ast.Stmt{ast.PrintStmt{Expression: ast.Variable{
+		Name:    token.Token{Type: token.IDENTIFIER, Lexeme: "a"},
+		Binding: &ast.Binding{Name: "a"},
+	}}}, stmts)
+}
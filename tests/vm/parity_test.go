@@ -0,0 +1,78 @@
+package vm
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/go-interpreter/internal/compiler"
+	"github.com/go-interpreter/internal/interpreter"
+	"github.com/go-interpreter/internal/parser"
+	"github.com/go-interpreter/internal/resolver"
+	"github.com/go-interpreter/internal/scanner"
+	"github.com/go-interpreter/internal/vm"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBackends_Agree runs the same programs on the tree-walking interpreter
+// and on the virtual machine, which must print the same thing.
+func TestBackends_Agree(t *testing.T) {
+	truthiness := `fun f() {} class C {} if (%s) print "t"; else print "f";`
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{name: "subtraction", source: `print 10 - 3;`, want: "7"},
+		{name: "division", source: `print 10 / 2;`, want: "5"},
+		{name: "operators of the same precedence", source: `print 8 - 2 - 1; print " "; print 8 / 2 / 2;`, want: "5 2"},
+		{name: "precedence", source: `print 2 + 3 * 4 - 1;`, want: "13"},
+		{name: "a number before a string", source: `print 1 + "a";`, want: "1a"},
+		{name: "a string before a number", source: `print "a" + 1;`, want: "a1"},
+		{name: "strings", source: `print "a" + "b";`, want: "ab"},
+		{name: "comparison", source: `print 3 < 10; print 10 >= 3;`, want: "truetrue"},
+		{name: "positive numbers are true", source: fmt.Sprintf(truthiness, "1"), want: "t"},
+		{name: "negative numbers are true", source: fmt.Sprintf(truthiness, "-1"), want: "t"},
+		{name: "zero is false", source: fmt.Sprintf(truthiness, "0"), want: "f"},
+		{name: "NaN is true", source: fmt.Sprintf(truthiness, "0 / 0"), want: "t"},
+		{name: "nil is false", source: fmt.Sprintf(truthiness, "nil"), want: "f"},
+		{name: "false is false", source: fmt.Sprintf(truthiness, "false"), want: "f"},
+		{name: "the empty string is false", source: fmt.Sprintf(truthiness, `""`), want: "f"},
+		{name: "other strings are true", source: fmt.Sprintf(truthiness, `"a"`), want: "t"},
+		{name: "functions are true", source: fmt.Sprintf(truthiness, "f"), want: "t"},
+		{name: "classes are true", source: fmt.Sprintf(truthiness, "C"), want: "t"},
+		{name: "instances are true", source: fmt.Sprintf(truthiness, "C()"), want: "t"},
+		{name: "not", source: `print !-1; print !0;`, want: "falsetrue"},
+		{name: "and and or", source: `print -1 and "b"; print 0 or "c";`, want: "bc"},
+		{
+			name: "incrementing a property evaluates the object once",
+			source: `class Box {} var box = Box(); box.n = 1; var k = 0;
+fun get() { k = k + 1; return box; }
+print get().n++; print k; print box.n;`,
+			want: "212",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokenScanner := scanner.NewTokenScanner(tt.source)
+			tokens, scanErrors := tokenScanner.ScanTokens()
+			assert.Empty(t, scanErrors)
+			p := parser.NewParser(tokens)
+			stmts, parseErrors := p.Parse()
+			assert.Empty(t, parseErrors)
+			inter := interpreter.NewInterpreter()
+			assert.Empty(t, resolver.NewResolver(&inter).Resolve(stmts))
+			var treeWalker bytes.Buffer
+			inter.SetOutput(&treeWalker)
+			assert.NoError(t, inter.Interpret(stmts))
+
+			script, err := compiler.NewCompiler().Compile(stmts)
+			assert.NoError(t, err)
+			var machine bytes.Buffer
+			assert.NoError(t, vm.NewVM(&machine).Interpret(script))
+
+			assert.Equal(t, tt.want, treeWalker.String(), "tree-walker")
+			assert.Equal(t, tt.want, machine.String(), "vm")
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package vm
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/go-interpreter/internal/compiler"
+	"github.com/go-interpreter/internal/errors"
+	"github.com/go-interpreter/internal/interpreter"
+	"github.com/go-interpreter/internal/parser"
+	"github.com/go-interpreter/internal/resolver"
+	"github.com/go-interpreter/internal/scanner"
+	"github.com/go-interpreter/internal/testutil"
+	"github.com/go-interpreter/internal/vm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVM_Interpret(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "arithmetic and strings",
+			source: `print 10 - 4 / 2; print " "; print "a" + 1;`,
+			want:   "8 a1",
+		},
+		{
+			name:   "locals shadow globals",
+			source: `var a = "global"; { var a = "local"; print a; } print a;`,
+			want:   "localglobal",
+		},
+		{
+			name:   "break and continue",
+			source: `var i = 0; while (i < 10) { var j = i; i++; if (j == 2) { continue; } if (j == 5) { break; } print j; }`,
+			want:   "0134",
+		},
+		{
+			name: "closures outlive their scope",
+			source: `
+fun makeCounter() { var i = 0; fun count() { i++; return i; } return count; }
+var a = makeCounter(); var b = makeCounter();
+a(); a(); print a(); print b();`,
+			want: "31",
+		},
+		{
+			name: "classes, initializers and super",
+			source: `
+class A { init(n) { this.n = n; } get() { return this.n; } }
+class B < A { init(n) { super.init(n * 2); } get() { return super.get() + 1; } }
+var b = B(2); print b.get(); print b;`,
+			want: "5B instance",
+		},
+		{
+			name:    "arity mismatch",
+			source:  `fun f(a) {} f(1, 2);`,
+			wantErr: "Expected 1 arguments but got 2.",
+		},
+		{
+			name:    "undefined variable",
+			source:  `print missing;`,
+			wantErr: "Undefined variable missing.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokenScanner := scanner.NewTokenScanner(tt.source)
+			tokens, scanErrors := tokenScanner.ScanTokens()
+			assert.Empty(t, scanErrors)
+			p := parser.NewParser(tokens)
+			stmts, parseErrors := p.Parse()
+			assert.Empty(t, parseErrors)
+			inter := interpreter.NewInterpreter()
+			assert.Empty(t, resolver.NewResolver(&inter).Resolve(stmts))
+
+			script, err := compiler.NewCompiler().Compile(stmts)
+			assert.NoError(t, err)
+
+			var out bytes.Buffer
+			err = vm.NewVM(&out).Interpret(script)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+func TestVM_ErrorPosition(t *testing.T) {
+	var locals strings.Builder
+	for n := 0; n < 300; n++ {
+		fmt.Fprintf(&locals, "var v%d = %d;\n", n, n)
+	}
+	tests := []struct {
+		name       string
+		source     string
+		wantType   errors.ExecutionErrorType
+		wantLine   int
+		wantColumn int
+		wantLength int
+	}{
+		{
+			name:       "a runtime error spans the token",
+			source:     "var a = 1;\nprint missing;",
+			wantType:   errors.RUNTIME_ERROR,
+			wantLine:   2,
+			wantColumn: 7,
+			wantLength: len("missing"),
+		},
+		{
+			name:       "a compile error spans the token",
+			source:     "{\n" + locals.String() + "}",
+			wantType:   errors.COMPILER_ERROR,
+			wantLine:   257,
+			wantColumn: 5,
+			wantLength: len("v255"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokenScanner := scanner.NewTokenScanner(tt.source)
+			tokens, scanErrors := tokenScanner.ScanTokens()
+			assert.Empty(t, scanErrors)
+			p := parser.NewParser(tokens)
+			stmts, parseErrors := p.Parse()
+			assert.Empty(t, parseErrors)
+
+			script, err := compiler.NewCompiler().Compile(stmts)
+			if err == nil {
+				err = vm.NewVM(io.Discard).Interpret(script)
+			}
+			diagnostic, ok := errors.AsExecutionError(err)
+			assert.True(t, ok, "got %v", err)
+			assert.Equal(t, tt.wantType, diagnostic.Type)
+			assert.Equal(t, tt.wantLine, diagnostic.Line)
+			assert.Equal(t, tt.wantColumn, diagnostic.Column)
+			assert.Equal(t, tt.wantLength, diagnostic.Length)
+		})
+	}
+}
+
+func TestVM_StackTrace(t *testing.T) {
+	source := `fun inner() {
+  return nope;
+}
+fun outer() {
+  return inner();
+}
+print outer();`
+	tokenScanner := scanner.NewTokenScanner(source)
+	tokens, scanErrors := tokenScanner.ScanTokens()
+	assert.Empty(t, scanErrors)
+	p := parser.NewParser(tokens)
+	stmts, parseErrors := p.Parse()
+	assert.Empty(t, parseErrors)
+	script, err := compiler.NewCompiler().Compile(stmts)
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	machine := vm.NewVM(&out)
+	machine.SetFile("main.lox")
+	executionError, ok := errors.AsExecutionError(machine.Interpret(script))
+	assert.True(t, ok)
+	assert.Equal(t, []errors.Frame{
+		{Function: "inner", File: "main.lox", Line: 2, Column: 10},
+		{Function: "outer", File: "main.lox", Line: 5, Column: 16},
+		{Function: "<script>", File: "main.lox", Line: 7, Column: 13},
+	}, executionError.Trace)
+}
+
+func TestVM_SetInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "readLine returns lines, then nil",
+			source: `var line = readLine(); while (line != nil) { print "[" + line + "]"; line = readLine(); } print readLine;`,
+			want:   "[first][second]<native fn readLine>",
+		},
+		{
+			name:    "readLine takes no arguments",
+			source:  `readLine(1);`,
+			wantErr: "Expected 0 arguments but got 1.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script, err := compiler.NewCompiler().Compile(testutil.Parse(t, tt.source))
+			assert.NoError(t, err)
+
+			var out bytes.Buffer
+			machine := vm.NewVM(&out)
+			machine.SetInput(strings.NewReader("first\r\nsecond\n"))
+			err = machine.Interpret(script)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}